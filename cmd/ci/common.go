@@ -24,3 +24,32 @@ func newSecret(key string) string {
 func newVariable(key string) string {
 	return fmt.Sprintf("${{ %s }}", varsPrefix(key))
 }
+
+func protectedVariable(s string) string {
+	return s + " (protected)"
+}
+
+func plainVariable(s string) string {
+	return s
+}
+
+func newGitLabVariable(key string) string {
+	return fmt.Sprintf("${%s}", key)
+}
+
+// runtimeTestCommand returns the shell command which installs the
+// dependencies of a function of the given runtime and runs its tests using
+// 'func test', which chooses the runtime's test tool.  False is returned if
+// no CI environment is provided for the runtime.
+func runtimeTestCommand(runtime string) (string, bool) {
+	switch runtime {
+	case "go", "quarkus":
+		return "func test", true
+	case "node", "typescript":
+		return "npm ci && func test", true
+	case "python":
+		return "pip install . && func test", true
+	default:
+		return "", false
+	}
+}
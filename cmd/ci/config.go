@@ -15,11 +15,21 @@ const (
 	PathFlag = "path"
 
 	PlatformFlag    = "platform"
-	DefaultPlatform = "github"
+	DefaultPlatform = PlatformGitHub
+
+	PlatformGitHub  = "github"
+	PlatformGitLab  = "gitlab"
+	PlatformForgejo = "forgejo"
+	PlatformGitea   = "gitea"
 
 	DefaultGitHubWorkflowDir      = ".github/workflows"
 	DefaultGitHubWorkflowFilename = "func-deploy.yaml"
 
+	DefaultGitLabPipelineFilename = ".gitlab-ci.yml"
+
+	DefaultForgejoWorkflowDir = ".forgejo/workflows"
+	DefaultGiteaWorkflowDir   = ".gitea/workflows"
+
 	BranchFlag    = "branch"
 	DefaultBranch = "main"
 
@@ -57,30 +67,47 @@ const (
 	TestStepFlag    = "test-step"
 	DefaultTestStep = true
 
+	PromoteFlag    = "promote"
+	DefaultPromote = false
+
+	StagingEnvironmentFlag    = "staging-environment"
+	DefaultStagingEnvironment = "staging"
+
+	ProductionEnvironmentFlag    = "production-environment"
+	DefaultProductionEnvironment = "production"
+
 	ForceFlag    = "force"
 	DefaultForce = false
 
+	CheckFlag    = "check"
+	DefaultCheck = false
+
 	VerboseFlag    = "verbose"
 	DefaultVerbose = false
 )
 
 // CIConfig readonly configuration
 type CIConfig struct {
-	githubWorkflowDir,
-	githubWorkflowFilename,
+	platform,
+	workflowDir,
+	workflowFilename,
 	branch,
 	workflowName,
 	kubeconfigSecret,
 	registryLoginUrlVar,
 	registryUserVar,
 	registryPassSecret,
-	registryUrlVar string
+	registryUrlVar,
+	stagingEnvironment,
+	productionEnvironment string
 	registryLogin,
 	selfHostedRunner,
 	remoteBuild,
 	workflowDispatch,
 	testStep,
+	promote,
 	force,
+	check,
 	verbose bool
 	fnRuntime,
 	fnRoot,
@@ -93,7 +120,8 @@ func NewCIConfig(
 	workingDir common.WorkDirFunc,
 	workflowNameExplicit bool,
 ) (CIConfig, error) {
-	if err := resolvePlatform(); err != nil {
+	platform, err := resolvePlatform()
+	if err != nil {
 		return CIConfig{}, err
 	}
 
@@ -115,43 +143,74 @@ func NewCIConfig(
 	}
 
 	remoteBuild := viper.GetBool(RemoteBuildFlag)
+	promote := viper.GetBool(PromoteFlag)
+	if err := validatePromotion(platform, promote, remoteBuild); err != nil {
+		return CIConfig{}, err
+	}
+
 	fnBuilder, err := resolveBuilder(f.Runtime, remoteBuild)
 	if err != nil {
 		return CIConfig{}, err
 	}
 
 	return CIConfig{
-		githubWorkflowDir:      DefaultGitHubWorkflowDir,
-		githubWorkflowFilename: DefaultGitHubWorkflowFilename,
-		branch:                 branch,
-		workflowName:           workflowName,
-		kubeconfigSecret:       viper.GetString(KubeconfigSecretNameFlag),
-		registryLoginUrlVar:    viper.GetString(RegistryLoginUrlVariableNameFlag),
-		registryUserVar:        viper.GetString(RegistryUserVariableNameFlag),
-		registryPassSecret:     viper.GetString(RegistryPassSecretNameFlag),
-		registryUrlVar:         viper.GetString(RegistryUrlVariableNameFlag),
-		registryLogin:          viper.GetBool(RegistryLoginFlag),
-		selfHostedRunner:       viper.GetBool(SelfHostedRunnerFlag),
-		remoteBuild:            remoteBuild,
-		workflowDispatch:       viper.GetBool(WorkflowDispatchFlag),
-		testStep:               viper.GetBool(TestStepFlag),
-		force:                  viper.GetBool(ForceFlag),
-		verbose:                viper.GetBool(VerboseFlag),
-		fnRuntime:              f.Runtime,
-		fnRoot:                 f.Root,
-		fnBuilder:              fnBuilder,
+		platform:              platform,
+		workflowDir:           platforms[platform].workflowDir,
+		workflowFilename:      platforms[platform].workflowFilename,
+		branch:                branch,
+		workflowName:          workflowName,
+		kubeconfigSecret:      viper.GetString(KubeconfigSecretNameFlag),
+		registryLoginUrlVar:   viper.GetString(RegistryLoginUrlVariableNameFlag),
+		registryUserVar:       viper.GetString(RegistryUserVariableNameFlag),
+		registryPassSecret:    viper.GetString(RegistryPassSecretNameFlag),
+		registryUrlVar:        viper.GetString(RegistryUrlVariableNameFlag),
+		registryLogin:         viper.GetBool(RegistryLoginFlag),
+		selfHostedRunner:      viper.GetBool(SelfHostedRunnerFlag),
+		remoteBuild:           remoteBuild,
+		workflowDispatch:      viper.GetBool(WorkflowDispatchFlag),
+		testStep:              viper.GetBool(TestStepFlag),
+		promote:               promote,
+		stagingEnvironment:    viper.GetString(StagingEnvironmentFlag),
+		productionEnvironment: viper.GetString(ProductionEnvironmentFlag),
+		force:                 viper.GetBool(ForceFlag),
+		check:                 viper.GetBool(CheckFlag),
+		verbose:               viper.GetBool(VerboseFlag),
+		fnRuntime:             f.Runtime,
+		fnRoot:                f.Root,
+		fnBuilder:             fnBuilder,
 	}, nil
 }
 
-func resolvePlatform() error {
-	platform := viper.GetString(PlatformFlag)
+func resolvePlatform() (string, error) {
+	platform := strings.ToLower(viper.GetString(PlatformFlag))
 	if platform == "" {
-		return fmt.Errorf("platform must not be empty, supported: %s", DefaultPlatform)
+		return "", fmt.Errorf("platform must not be empty, supported: %s", SupportedPlatforms())
 	}
-	if strings.ToLower(platform) != DefaultPlatform {
-		return fmt.Errorf("%s support is not implemented, supported: %s", platform, DefaultPlatform)
+	if _, ok := platforms[platform]; !ok {
+		return "", fmt.Errorf("%s support is not implemented, supported: %s", viper.GetString(PlatformFlag), SupportedPlatforms())
 	}
 
+	return platform, nil
+}
+
+// validatePromotion checks that a promotion workflow can be generated, which
+// requires environments with manual approval and builds within the workflow.
+func validatePromotion(platform string, promote, remoteBuild bool) error {
+	if !promote {
+		return nil
+	}
+	if platform != PlatformGitHub && platform != PlatformGitLab {
+		return fmt.Errorf("promotion workflows are not supported on %s, supported: %s, %s", platform, PlatformGitHub, PlatformGitLab)
+	}
+	if remoteBuild {
+		return fmt.Errorf("--%s cannot be used with --%s, as the image is built once in the workflow and promoted by digest", PromoteFlag, RemoteBuildFlag)
+	}
+	if viper.GetString(StagingEnvironmentFlag) == "" || viper.GetString(ProductionEnvironmentFlag) == "" {
+		return fmt.Errorf("promotion workflows require both a staging and a production environment name")
+	}
+	if viper.GetString(StagingEnvironmentFlag) == viper.GetString(ProductionEnvironmentFlag) {
+		return fmt.Errorf("staging and production environments must differ")
+	}
 	return nil
 }
 
@@ -218,13 +277,20 @@ func resolveBuilder(runtime string, remote bool) (string, error) {
 	}
 }
 
-func (cc CIConfig) FnGitHubWorkflowFilepath() string {
-	fnGitHubWorkflowDir := filepath.Join(cc.fnRoot, cc.githubWorkflowDir)
-	return filepath.Join(fnGitHubWorkflowDir, cc.githubWorkflowFilename)
+func (cc CIConfig) FnWorkflowFilepath() string {
+	fnWorkflowDir := filepath.Join(cc.fnRoot, cc.workflowDir)
+	return filepath.Join(fnWorkflowDir, cc.workflowFilename)
 }
 
 func (cc CIConfig) OutputPath() string {
-	return filepath.Join(cc.githubWorkflowDir, cc.githubWorkflowFilename)
+	return filepath.Join(cc.workflowDir, cc.workflowFilename)
+}
+
+func (cc CIConfig) Platform() string {
+	if cc.platform == "" {
+		return DefaultPlatform
+	}
+	return cc.platform
 }
 
 func (cc CIConfig) Branch() string {
@@ -263,6 +329,14 @@ func (cc CIConfig) SelfHostedRunner() bool {
 	return cc.selfHostedRunner
 }
 
+// runner on which the workflow runs, as presented to the user.
+func (cc CIConfig) runner() string {
+	if cc.Platform() == PlatformGitLab && !cc.selfHostedRunner {
+		return "shared"
+	}
+	return determineRunner(cc.selfHostedRunner)
+}
+
 func (cc CIConfig) RemoteBuild() bool {
 	return cc.remoteBuild
 }
@@ -275,10 +349,26 @@ func (cc CIConfig) TestStep() bool {
 	return cc.testStep
 }
 
+func (cc CIConfig) Promote() bool {
+	return cc.promote
+}
+
+func (cc CIConfig) StagingEnvironment() string {
+	return cc.stagingEnvironment
+}
+
+func (cc CIConfig) ProductionEnvironment() string {
+	return cc.productionEnvironment
+}
+
 func (cc CIConfig) Force() bool {
 	return cc.force
 }
 
+func (cc CIConfig) Check() bool {
+	return cc.check
+}
+
 func (cc CIConfig) Verbose() bool {
 	return cc.verbose
 }
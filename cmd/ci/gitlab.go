@@ -0,0 +1,303 @@
+package ci
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	gitlabGoImage     = "golang:1"
+	gitlabNodeImage   = "node:lts"
+	gitlabPythonImage = "python:3"
+	gitlabJavaImage   = "eclipse-temurin:21"
+	gitlabDockerImage = "docker:27"
+	gitlabDindImage   = "docker:27-dind"
+	gitlabAlpineImage = "alpine:3"
+
+	gitlabKubeconfigPath = "/tmp/kubeconfig"
+)
+
+type gitlabPipeline struct {
+	title string `yaml:"-"`
+
+	Workflow         gitlabWorkflow `yaml:"workflow"`
+	Stages           []string       `yaml:"stages"`
+	Test             *gitlabJob     `yaml:"test,omitempty"`
+	Build            *gitlabJob     `yaml:"build,omitempty"`
+	Deploy           *gitlabJob     `yaml:"deploy,omitempty"`
+	DeployStaging    *gitlabJob     `yaml:"deploy-staging,omitempty"`
+	DeployProduction *gitlabJob     `yaml:"deploy-production,omitempty"`
+}
+
+type gitlabWorkflow struct {
+	Name  string       `yaml:"name"`
+	Rules []gitlabRule `yaml:"rules"`
+}
+
+type gitlabRule struct {
+	If string `yaml:"if"`
+}
+
+type gitlabJob struct {
+	Stage        string             `yaml:"stage"`
+	Image        string             `yaml:"image"`
+	Tags         []string           `yaml:"tags,omitempty"`
+	Services     []string           `yaml:"services,omitempty"`
+	Environment  *gitlabEnvironment `yaml:"environment,omitempty"`
+	When         string             `yaml:"when,omitempty"`
+	Variables    map[string]string  `yaml:"variables,omitempty"`
+	BeforeScript []string           `yaml:"before_script,omitempty"`
+	Script       []string           `yaml:"script"`
+	Artifacts    *gitlabArtifacts   `yaml:"artifacts,omitempty"`
+}
+
+type gitlabEnvironment struct {
+	Name string `yaml:"name"`
+}
+
+type gitlabArtifacts struct {
+	Reports gitlabReports `yaml:"reports"`
+}
+
+type gitlabReports struct {
+	Dotenv string `yaml:"dotenv"`
+}
+
+// NewGitLabPipeline returns a GitLab CI pipeline (.gitlab-ci.yml) which runs
+// the function's tests and deploys it on push to the configured branch.
+func NewGitLabPipeline(conf CIConfig, messageWriter io.Writer) *gitlabPipeline {
+	pipeline := &gitlabPipeline{
+		title:    conf.platformSpec().title,
+		Workflow: createGitLabWorkflow(conf),
+	}
+
+	pipeline.Test = createGitLabTestJob(conf, messageWriter)
+	if pipeline.Test != nil {
+		pipeline.Stages = append(pipeline.Stages, "test")
+	}
+
+	if conf.Promote() {
+		// The image is built once, deployed by digest to staging and then
+		// promoted to production by manually running the production job.
+		// The production environment should be protected with required
+		// approvals.
+		pipeline.Build = createGitLabBuildJob(conf)
+		pipeline.DeployStaging = createGitLabDeployImageJob(conf, "staging", conf.StagingEnvironment())
+		pipeline.DeployStaging.Script = append(pipeline.DeployStaging.Script, "func invoke --target=remote")
+		pipeline.DeployProduction = createGitLabDeployImageJob(conf, "production", conf.ProductionEnvironment())
+		pipeline.DeployProduction.When = "manual"
+		pipeline.Stages = append(pipeline.Stages, "build", "staging", "production")
+		return pipeline
+	}
+
+	pipeline.Deploy = createGitLabDeployJob(conf)
+	pipeline.Stages = append(pipeline.Stages, "deploy")
+
+	return pipeline
+}
+
+func createGitLabWorkflow(conf CIConfig) gitlabWorkflow {
+	workflow := gitlabWorkflow{
+		Name: conf.WorkflowName(),
+		Rules: []gitlabRule{
+			{If: fmt.Sprintf(`$CI_COMMIT_BRANCH == "%s"`, conf.Branch())},
+		},
+	}
+
+	if conf.WorkflowDispatch() {
+		// pipelines run manually using "Run pipeline" in the GitLab UI
+		workflow.Rules = append(workflow.Rules, gitlabRule{If: `$CI_PIPELINE_SOURCE == "web"`})
+	}
+
+	return workflow
+}
+
+func createGitLabTestJob(conf CIConfig, messageWriter io.Writer) *gitlabJob {
+	if !conf.TestStep() {
+		return nil
+	}
+
+	run, ok := runtimeTestCommand(conf.FnRuntime())
+	if !ok {
+		// best-effort user message; errors are non-critical
+		_, _ = fmt.Fprintf(messageWriter, "WARNING: test step not supported for runtime %s\n", conf.FnRuntime())
+		return nil
+	}
+
+	return &gitlabJob{
+		Stage:        "test",
+		Image:        gitlabRuntimeImage(conf.FnRuntime()),
+		Tags:         gitlabRunnerTags(conf.SelfHostedRunner()),
+		BeforeScript: gitlabFuncCLIInstallScript(),
+		Script:       []string{run},
+	}
+}
+
+func createGitLabDeployJob(conf CIConfig) *gitlabJob {
+	deploy := newGitLabBuilderJob(conf, "deploy")
+
+	if conf.RemoteBuild() {
+		deploy.Variables["FUNC_REMOTE"] = "true"
+	}
+
+	deploy.BeforeScript = append(deploy.BeforeScript, gitlabKubeContextScript(conf)...)
+	deploy.BeforeScript = append(deploy.BeforeScript, gitlabRegistryLoginScript(conf)...)
+	deploy.BeforeScript = append(deploy.BeforeScript, gitlabFuncCLIInstallScript()...)
+	deploy.Script = []string{"func deploy"}
+
+	return deploy
+}
+
+// createGitLabBuildJob builds and pushes the function's image, passing the
+// image reference including its digest to later jobs as $IMAGE.
+func createGitLabBuildJob(conf CIConfig) *gitlabJob {
+	build := newGitLabBuilderJob(conf, "build")
+
+	build.BeforeScript = append(build.BeforeScript, gitlabRegistryLoginScript(conf)...)
+	build.BeforeScript = append(build.BeforeScript, gitlabFuncCLIInstallScript()...)
+	build.Script = []string{
+		"func build --push",
+		`echo "IMAGE=$(cat .func/built-image)" > build.env`,
+	}
+	build.Artifacts = &gitlabArtifacts{Reports: gitlabReports{Dotenv: "build.env"}}
+
+	return build
+}
+
+// createGitLabDeployImageJob deploys the image built by the build job to the
+// given environment, whose variables (such as the kubeconfig) are scoped to it.
+func createGitLabDeployImageJob(conf CIConfig, stage, environment string) *gitlabJob {
+	deploy := &gitlabJob{
+		Stage:       stage,
+		Image:       gitlabAlpineImage,
+		Tags:        gitlabRunnerTags(conf.SelfHostedRunner()),
+		Environment: &gitlabEnvironment{Name: environment},
+		Variables:   map[string]string{"FUNC_VERBOSE": "true"},
+	}
+
+	deploy.BeforeScript = append(deploy.BeforeScript, gitlabKubeContextScript(conf)...)
+	deploy.BeforeScript = append(deploy.BeforeScript, gitlabFuncCLIInstallScript()...)
+	deploy.Script = []string{`func deploy --image "${IMAGE}"`}
+
+	return deploy
+}
+
+// newGitLabBuilderJob returns a job whose image is able to build the
+// function using its configured builder.
+func newGitLabBuilderJob(conf CIConfig, stage string) *gitlabJob {
+	job := &gitlabJob{
+		Stage: stage,
+		Image: gitlabAlpineImage,
+		Tags:  gitlabRunnerTags(conf.SelfHostedRunner()),
+		Variables: map[string]string{
+			"FUNC_VERBOSE":  "true",
+			"FUNC_BUILDER":  conf.FnBuilder(),
+			"FUNC_REGISTRY": gitlabRegistryUrl(conf),
+		},
+	}
+
+	switch {
+	case conf.RemoteBuild():
+		// built on the cluster
+	case conf.FnBuilder() == "host":
+		job.Image = gitlabRuntimeImage(conf.FnRuntime())
+	default:
+		// pack and s2i builders require a container engine
+		job.Image = gitlabDockerImage
+		job.Services = []string{gitlabDindImage}
+		job.Variables["DOCKER_HOST"] = "tcp://docker:2375"
+		job.Variables["DOCKER_TLS_CERTDIR"] = ""
+	}
+
+	return job
+}
+
+func gitlabRegistryUrl(conf CIConfig) string {
+	if conf.RegistryLogin() {
+		return newGitLabVariable(conf.RegistryLoginUrlVar()) + "/" + newGitLabVariable(conf.RegistryUserVar())
+	}
+	return newGitLabVariable(conf.RegistryUrlVar())
+}
+
+// gitlabKubeContextScript configures the Kubernetes context from the
+// kubeconfig variable, which may be of type "File" (a path) or a value.
+func gitlabKubeContextScript(conf CIConfig) []string {
+	kubeconfig := newGitLabVariable(conf.KubeconfigSecret())
+	return []string{
+		fmt.Sprintf(`if [ -f "%s" ]; then cp "%s" %s; else printf '%%s' "%s" > %s; fi`,
+			kubeconfig, kubeconfig, gitlabKubeconfigPath, kubeconfig, gitlabKubeconfigPath),
+		"export KUBECONFIG=" + gitlabKubeconfigPath,
+	}
+}
+
+// gitlabRegistryLoginScript writes registry credentials to the docker
+// config, such that no container engine is required to log in.
+func gitlabRegistryLoginScript(conf CIConfig) []string {
+	if !conf.RegistryLogin() {
+		return nil
+	}
+
+	return []string{
+		"mkdir -p ~/.docker",
+		fmt.Sprintf(`echo "{\"auths\":{\"%s\":{\"auth\":\"$(printf '%%s:%%s' "%s" "%s" | base64 | tr -d '\n')\"}}}" > ~/.docker/config.json`,
+			newGitLabVariable(conf.RegistryLoginUrlVar()),
+			newGitLabVariable(conf.RegistryUserVar()),
+			newGitLabVariable(conf.RegistryPassSecret())),
+	}
+}
+
+func gitlabFuncCLIInstallScript() []string {
+	return []string{
+		fmt.Sprintf("wget -q -O /usr/local/bin/func https://github.com/knative/func/releases/download/%s/func_linux_amd64", defaultFuncCliVersion),
+		"chmod +x /usr/local/bin/func",
+	}
+}
+
+func gitlabRuntimeImage(runtime string) string {
+	switch runtime {
+	case "go":
+		return gitlabGoImage
+	case "node", "typescript":
+		return gitlabNodeImage
+	case "python":
+		return gitlabPythonImage
+	case "quarkus", "springboot":
+		return gitlabJavaImage
+	default:
+		return gitlabAlpineImage
+	}
+}
+
+func gitlabRunnerTags(selfHosted bool) []string {
+	if selfHosted {
+		return []string{determineRunner(selfHosted)}
+	}
+	return nil
+}
+
+func (gp *gitlabPipeline) Export(path string, w WorkflowWriter, force bool, m io.Writer) error {
+	return export(gp.title, path, gp, markGitLabPipeline, w, force, m)
+}
+
+func (gp *gitlabPipeline) Check(path string, w WorkflowWriter) error {
+	return check(path, gp, markGitLabPipeline, w)
+}
+
+// markGitLabPipeline marks each job, stage and script line as managed by
+// func.
+func markGitLabPipeline(root *yaml.Node) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "workflow":
+		case "stages":
+			markSequence(value)
+		default:
+			markManaged(key)
+			markSequence(mappingValue(value, "before_script"))
+			markSequence(mappingValue(value, "script"))
+		}
+	}
+}
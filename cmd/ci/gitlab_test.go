@@ -0,0 +1,49 @@
+package ci_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ory/viper"
+	"gotest.tools/v3/assert"
+	"knative.dev/func/cmd/ci"
+	"knative.dev/func/cmd/common"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func TestGitLabPipeline_Export(t *testing.T) {
+	// GIVEN
+	viper.Set("platform", "gitlab")
+	viper.Set("workflow-name", ci.DefaultWorkflowName)
+	t.Cleanup(func() { viper.Reset() })
+	loaderSaver := common.NewMockLoaderSaver()
+	loaderSaver.LoadFn = func(path string) (fn.Function, error) {
+		return fn.Function{Root: path, Runtime: "go"}, nil
+	}
+	bufferWriter := ci.NewBufferWriter()
+
+	// WHEN
+	cfg, configErr := ci.NewCIConfig(
+		loaderSaver,
+		common.CurrentBranchStub("main", nil),
+		common.WorkDirStub("", nil),
+		false,
+	)
+	assert.NilError(t, configErr, "unexpected error when creating CIConfig")
+
+	gp := ci.NewManifest(cfg, &bytes.Buffer{})
+	exportErr := gp.Export(cfg.FnWorkflowFilepath(), bufferWriter, false, &bytes.Buffer{})
+
+	// THEN
+	assert.NilError(t, exportErr, "unexpected error when exporting GitLab pipeline")
+	assert.Equal(t, bufferWriter.Path, ci.DefaultGitLabPipelineFilename)
+	assert.Assert(t, strings.Contains(bufferWriter.Buffer.String(), `$CI_COMMIT_BRANCH == "main"`))
+
+	// exporting again without force updates the pipeline in place
+	exported := bufferWriter.Buffer.String()
+	exportErr = gp.Export(cfg.FnWorkflowFilepath(), bufferWriter, false, &bytes.Buffer{})
+	assert.NilError(t, exportErr)
+	assert.Equal(t, bufferWriter.Buffer.String(), exported)
+	assert.NilError(t, gp.Check(cfg.FnWorkflowFilepath(), bufferWriter))
+}
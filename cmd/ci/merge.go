@@ -0,0 +1,221 @@
+package ci
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// managedMarker is the comment which marks the jobs, steps and script lines
+// of a workflow which are managed by func.  Re-running 'func config ci'
+// updates marked items, removes marked items which are no longer generated,
+// and preserves everything else.  Removing the marker from an item takes
+// ownership of it.
+const managedMarker = "func:managed"
+
+const managedHeader = "Generated by 'func config ci'.  Items marked " + managedMarker + " are updated\n" +
+	"when the command is re-run; other jobs and steps are preserved."
+
+func isManaged(n *yaml.Node) bool {
+	for _, line := range strings.Split(n.HeadComment, "\n") {
+		if strings.TrimSpace(strings.TrimPrefix(line, "#")) == managedMarker {
+			return true
+		}
+	}
+	return false
+}
+
+func markManaged(n *yaml.Node) {
+	if n.HeadComment == "" {
+		n.HeadComment = managedMarker
+		return
+	}
+	n.HeadComment += "\n" + managedMarker
+}
+
+// hasManaged returns true if any node of the tree is marked as managed.
+func hasManaged(n *yaml.Node) bool {
+	if isManaged(n) {
+		return true
+	}
+	for _, c := range n.Content {
+		if hasManaged(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// mappingValue returns the value of the given key of a mapping node, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// markSequence marks every item of the sequence node as managed.
+func markSequence(n *yaml.Node) {
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, item := range n.Content {
+		markManaged(item)
+	}
+}
+
+// merge the generated node into the existing node, returning the result.
+//
+// Mappings are merged key by key.  Existing keys which are not generated are
+// kept unless marked as managed, and generated keys which are marked managed
+// but exist unmarked are kept as they are (the user took ownership of them).
+// Sequences of managed items are merged item by item, keeping the position
+// of user items; other sequences and scalars are replaced.
+func merge(existing, generated *yaml.Node) *yaml.Node {
+	if existing == nil || existing.Kind != generated.Kind {
+		return generated
+	}
+	switch generated.Kind {
+	case yaml.DocumentNode:
+		if len(existing.Content) == 0 || len(generated.Content) == 0 {
+			return generated
+		}
+		result := *existing
+		result.Content = []*yaml.Node{merge(existing.Content[0], generated.Content[0])}
+		return &result
+	case yaml.MappingNode:
+		return mergeMapping(existing, generated)
+	case yaml.SequenceNode:
+		if !hasManagedItems(generated) {
+			return generated
+		}
+		return mergeSequence(existing, generated)
+	default:
+		return generated
+	}
+}
+
+func mergeMapping(existing, generated *yaml.Node) *yaml.Node {
+	result := *existing
+	result.Content = nil
+
+	generatedKeys := map[string]bool{}
+	for i := 0; i+1 < len(generated.Content); i += 2 {
+		generatedKeys[generated.Content[i].Value] = true
+	}
+
+	// Existing keys, in their existing order
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(existing.Content); i += 2 {
+		key, value := existing.Content[i], existing.Content[i+1]
+		seen[key.Value] = true
+		if !generatedKeys[key.Value] {
+			if !isManaged(key) {
+				result.Content = append(result.Content, key, value) // user-defined
+			}
+			continue // managed, but no longer generated
+		}
+		genKey, genValue := keyValue(generated, key.Value)
+		if isManaged(genKey) && !isManaged(key) {
+			result.Content = append(result.Content, key, value) // owned by the user
+			continue
+		}
+		result.Content = append(result.Content, key, merge(value, genValue))
+	}
+
+	// Generated keys which do not yet exist
+	for i := 0; i+1 < len(generated.Content); i += 2 {
+		if !seen[generated.Content[i].Value] {
+			result.Content = append(result.Content, generated.Content[i], generated.Content[i+1])
+		}
+	}
+	return &result
+}
+
+func keyValue(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func hasManagedItems(n *yaml.Node) bool {
+	for _, item := range n.Content {
+		if isManaged(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSequence replaces the managed items of the existing sequence with the
+// generated items of the same identity, removes managed items which are no
+// longer generated, and inserts new generated items after the preceding
+// generated item.  Unmarked existing items are kept in place.
+func mergeSequence(existing, generated *yaml.Node) *yaml.Node {
+	generatedByID := map[string]*yaml.Node{}
+	for _, item := range generated.Content {
+		generatedByID[itemID(item)] = item
+	}
+
+	placed := map[string]bool{}
+	var content []*yaml.Node
+	for _, item := range existing.Content {
+		id := itemID(item)
+		if !isManaged(item) {
+			content = append(content, item)
+			placed[id] = placed[id] || generatedByID[id] != nil // owned by the user
+			continue
+		}
+		if gen, ok := generatedByID[id]; ok && !placed[id] {
+			content = append(content, gen)
+			placed[id] = true
+		}
+	}
+
+	// Insert generated items not yet placed after their preceding sibling
+	for i, item := range generated.Content {
+		id := itemID(item)
+		if placed[id] {
+			continue
+		}
+		pos := 0
+		if i > 0 {
+			prev := itemID(generated.Content[i-1])
+			for j, c := range content {
+				if itemID(c) == prev {
+					pos = j + 1
+					break
+				}
+			}
+		}
+		content = append(content[:pos], append([]*yaml.Node{item}, content[pos:]...)...)
+		placed[id] = true
+	}
+
+	result := *existing
+	result.Content = content
+	return &result
+}
+
+// itemID identifies an item of a sequence: steps by name (or id), and
+// scalars by value.
+func itemID(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	for _, key := range []string{"name", "id"} {
+		if v := mappingValue(n, key); v != nil && v.Kind == yaml.ScalarNode {
+			return key + ":" + v.Value
+		}
+	}
+	raw, _ := yaml.Marshal(n)
+	return string(raw)
+}
@@ -0,0 +1,94 @@
+package ci
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+)
+
+func TestMerge(t *testing.T) {
+	existing := `jobs:
+  # func:managed
+  deploy:
+    steps:
+      # func:managed
+      - name: Checkout
+        uses: actions/checkout@v3
+      - name: Lint
+        run: make lint
+      # func:managed
+      - name: Removed
+        run: echo removed
+      # func:managed
+      - name: Deploy
+        run: func deploy
+  # func:managed
+  stale:
+    steps: []
+  notify:
+    steps:
+      - run: ./notify.sh
+`
+	generated := `jobs:
+  # func:managed
+  deploy:
+    steps:
+      # func:managed
+      - name: Checkout
+        uses: actions/checkout@v4
+      # func:managed
+      - name: Test
+        run: go test ./...
+      # func:managed
+      - name: Deploy
+        run: func deploy -v
+`
+	expected := `jobs:
+  # func:managed
+  deploy:
+    steps:
+      # func:managed
+      - name: Checkout
+        uses: actions/checkout@v4
+      # func:managed
+      - name: Test
+        run: go test ./...
+      - name: Lint
+        run: make lint
+      # func:managed
+      - name: Deploy
+        run: func deploy -v
+  notify:
+    steps:
+      - run: ./notify.sh
+`
+	var e, g yaml.Node
+	assert.NilError(t, yaml.Unmarshal([]byte(existing), &e))
+	assert.NilError(t, yaml.Unmarshal([]byte(generated), &g))
+
+	raw, err := toYaml(merge(&e, &g))
+
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), expected)
+}
+
+func TestMerge_UserOwnedJob(t *testing.T) {
+	existing := `jobs:
+  deploy:
+    runs-on: self-hosted
+`
+	generated := `jobs:
+  # func:managed
+  deploy:
+    runs-on: ubuntu-latest
+`
+	var e, g yaml.Node
+	assert.NilError(t, yaml.Unmarshal([]byte(existing), &e))
+	assert.NilError(t, yaml.Unmarshal([]byte(generated), &g))
+
+	raw, err := toYaml(merge(&e, &g))
+
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), existing)
+}
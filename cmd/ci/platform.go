@@ -0,0 +1,197 @@
+package ci
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is a generated CI/CD configuration which can be exported to the
+// function's repository.
+type Manifest interface {
+	// Export the manifest to path, updating the func-managed items of an
+	// existing workflow, or overwriting it entirely if forced.
+	Export(path string, w WorkflowWriter, force bool, m io.Writer) error
+	// Check returns ErrWorkflowDrift if the workflow at path differs from
+	// the manifest in its func-managed items.
+	Check(path string, w WorkflowWriter) error
+}
+
+// NewManifest returns the manifest for the CI/CD platform of the given config.
+func NewManifest(conf CIConfig, messageWriter io.Writer) Manifest {
+	switch conf.Platform() {
+	case PlatformGitLab:
+		return NewGitLabPipeline(conf, messageWriter)
+	case PlatformForgejo, PlatformGitea:
+		return NewForgejoWorkflow(conf, messageWriter)
+	default:
+		return NewGitHubWorkflow(conf, messageWriter)
+	}
+}
+
+// platform describes where a CI/CD platform expects its manifest and how
+// secrets and variables are referred to when presented to the user.
+type platform struct {
+	title            string // e.g. "GitHub Workflow"
+	host             string // where secrets and variables are created
+	workflowDir      string
+	workflowFilename string
+	secretRef        func(string) string
+	varRef           func(string) string
+}
+
+// platforms supported, keyed by the value of the --platform flag.
+var platforms = map[string]platform{
+	PlatformGitHub: {
+		title:            "GitHub Workflow",
+		host:             "github.com",
+		workflowDir:      DefaultGitHubWorkflowDir,
+		workflowFilename: DefaultGitHubWorkflowFilename,
+		secretRef:        secretsPrefix,
+		varRef:           varsPrefix,
+	},
+	PlatformGitLab: {
+		title:            "GitLab CI Pipeline",
+		host:             "GitLab (Settings > CI/CD > Variables)",
+		workflowFilename: DefaultGitLabPipelineFilename,
+		secretRef:        protectedVariable,
+		varRef:           plainVariable,
+	},
+	PlatformForgejo: {
+		title:            "Forgejo Workflow",
+		host:             "Forgejo",
+		workflowDir:      DefaultForgejoWorkflowDir,
+		workflowFilename: DefaultGitHubWorkflowFilename,
+		secretRef:        secretsPrefix,
+		varRef:           varsPrefix,
+	},
+	PlatformGitea: {
+		title:            "Gitea Workflow",
+		host:             "Gitea",
+		workflowDir:      DefaultGiteaWorkflowDir,
+		workflowFilename: DefaultGitHubWorkflowFilename,
+		secretRef:        secretsPrefix,
+		varRef:           varsPrefix,
+	},
+}
+
+// SupportedPlatforms returns the supported values of the --platform flag.
+func SupportedPlatforms() string {
+	return strings.Join([]string{PlatformGitHub, PlatformGitLab, PlatformForgejo, PlatformGitea}, ", ")
+}
+
+func (cc CIConfig) platformSpec() platform {
+	return platforms[cc.Platform()]
+}
+
+// export writes the manifest to path as YAML.  An existing workflow is
+// updated, replacing only its func-managed items, unless forced, in which
+// case it is overwritten.  Workflows without func-managed items are only
+// overwritten when forced.
+func export(title, path string, manifest any, mark func(*yaml.Node), w WorkflowWriter, force bool, m io.Writer) error {
+	generated, err := toNode(manifest, mark)
+	if err != nil {
+		return err
+	}
+
+	if w.Exist(path) {
+		existing, err := readNode(path, w)
+		if err != nil {
+			return err
+		}
+		switch {
+		case force:
+			// best-effort user message; errors are non-critical
+			_, _ = fmt.Fprintf(m, "WARNING: --force flag is set, overwriting existing %s file\n", title)
+		case !hasManaged(existing):
+			return ErrWorkflowExists
+		default:
+			// best-effort user message; errors are non-critical
+			_, _ = fmt.Fprintf(m, "Updating func-managed items of existing %s file\n", title)
+			generated = merge(existing, generated)
+		}
+	}
+
+	raw, err := toYaml(generated)
+	if err != nil {
+		return err
+	}
+
+	return w.Write(path, raw)
+}
+
+// check returns ErrWorkflowDrift if the func-managed items of the workflow
+// at path differ from the generated manifest, or the workflow is missing.
+func check(path string, manifest any, mark func(*yaml.Node), w WorkflowWriter) error {
+	if !w.Exist(path) {
+		return fmt.Errorf("%w: %s does not exist", ErrWorkflowDrift, path)
+	}
+
+	generated, err := toNode(manifest, mark)
+	if err != nil {
+		return err
+	}
+	existing, err := readNode(path, w)
+	if err != nil {
+		return err
+	}
+	if !hasManaged(existing) {
+		return fmt.Errorf("%w: %s is not managed by func", ErrWorkflowDrift, path)
+	}
+
+	expected, err := toYaml(merge(existing, generated))
+	if err != nil {
+		return err
+	}
+	actual, err := toYaml(existing)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(expected, actual) {
+		return fmt.Errorf("%w: %s", ErrWorkflowDrift, path)
+	}
+	return nil
+}
+
+// toNode encodes the manifest as a YAML document with its func-managed
+// items marked.
+func toNode(manifest any, mark func(*yaml.Node)) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := root.Encode(manifest); err != nil {
+		return nil, err
+	}
+	mark(&root)
+	return &yaml.Node{
+		Kind:        yaml.DocumentNode,
+		HeadComment: managedHeader,
+		Content:     []*yaml.Node{&root},
+	}, nil
+}
+
+func readNode(path string, w WorkflowWriter) (*yaml.Node, error) {
+	raw, err := w.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	var n yaml.Node
+	if err := yaml.Unmarshal(raw, &n); err != nil {
+		return nil, fmt.Errorf("unable to parse existing workflow %s: %w", path, err)
+	}
+	return &n, nil
+}
+
+func toYaml(n *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(n); err != nil {
+		return nil, err
+	}
+	encoder.Close()
+
+	return buf.Bytes(), nil
+}
@@ -7,7 +7,7 @@ import (
 
 const (
 	MainLayoutPlainText = `
-GitHub Workflow Configuration
+%s Configuration
   Workflow filepath:  %s
   Workflow name:      %s
   Branch:             %s
@@ -18,6 +18,8 @@ GitHub Workflow Configuration
   Registry login:     %s
   Manual dispatch:    %s
   Workflow overwrite: %s
+`
+	PromotionPlainText = `  Promotion:          %s -> %s (manual approval)
 `
 	RequireManyPlainText = `
   Required Secrets & Variables:
@@ -32,9 +34,9 @@ GitHub Workflow Configuration
 `
 
 	PostExportManyPlainText = `
-GitHub Workflow created at: %s
+%s created at: %s
 
-Create the following Secrets & Variables on github.com:
+Create the following Secrets & Variables on %s:
   %s
   %s
   %s
@@ -42,21 +44,28 @@ Create the following Secrets & Variables on github.com:
   %s
 `
 
+	PostExportPromotionPlainText = `
+Create the environments '%s' and '%s' on %s, each with its own
+%s, and require approval for deployments to '%s'.
+`
+
 	PostExportOnePlainText = `
-GitHub Workflow created at: %s
+%s created at: %s
 
-Create the following Secret on github.com: %s
+Create the following Secret on %s: %s
 `
 )
 
 func PrintConfiguration(w io.Writer, conf CIConfig) error {
+	p := conf.platformSpec()
 	if _, err := fmt.Fprintf(w, MainLayoutPlainText,
+		p.title,
 		conf.OutputPath(),
 		conf.WorkflowName(),
 		conf.Branch(),
 		conf.FnBuilder(),
 		enabledOrDisabled(conf.RemoteBuild()),
-		determineRunner(conf.SelfHostedRunner()),
+		conf.runner(),
 		enabledOrDisabled(conf.TestStep()),
 		enabledOrDisabled(conf.RegistryLogin()),
 		enabledOrDisabled(conf.WorkflowDispatch()),
@@ -65,13 +74,22 @@ func PrintConfiguration(w io.Writer, conf CIConfig) error {
 		return err
 	}
 
+	if conf.Promote() {
+		if _, err := fmt.Fprintf(w, PromotionPlainText,
+			conf.StagingEnvironment(),
+			conf.ProductionEnvironment(),
+		); err != nil {
+			return err
+		}
+	}
+
 	if conf.RegistryLogin() {
 		if _, err := fmt.Fprintf(w, RequireManyPlainText,
-			secretsPrefix(conf.KubeconfigSecret()),
-			secretsPrefix(conf.RegistryPassSecret()),
-			varsPrefix(conf.RegistryLoginUrlVar()),
-			varsPrefix(conf.RegistryUserVar()),
-			varsPrefix(conf.RegistryUrlVar()),
+			p.secretRef(conf.KubeconfigSecret()),
+			p.secretRef(conf.RegistryPassSecret()),
+			p.varRef(conf.RegistryLoginUrlVar()),
+			p.varRef(conf.RegistryUserVar()),
+			p.varRef(conf.RegistryUrlVar()),
 		); err != nil {
 			return err
 		}
@@ -81,7 +99,7 @@ func PrintConfiguration(w io.Writer, conf CIConfig) error {
 
 	if _, err := fmt.Fprintf(w,
 		RequireOnePlainText,
-		secretsPrefix(conf.KubeconfigSecret()),
+		p.secretRef(conf.KubeconfigSecret()),
 	); err != nil {
 		return err
 	}
@@ -90,21 +108,46 @@ func PrintConfiguration(w io.Writer, conf CIConfig) error {
 }
 
 func PrintPostExportMessage(w io.Writer, conf CIConfig) error {
+	if err := printPostExportSecrets(w, conf); err != nil {
+		return err
+	}
+
+	if conf.Promote() {
+		p := conf.platformSpec()
+		_, err := fmt.Fprintf(w, PostExportPromotionPlainText,
+			conf.StagingEnvironment(),
+			conf.ProductionEnvironment(),
+			p.host,
+			p.secretRef(conf.KubeconfigSecret()),
+			conf.ProductionEnvironment(),
+		)
+		return err
+	}
+
+	return nil
+}
+
+func printPostExportSecrets(w io.Writer, conf CIConfig) error {
+	p := conf.platformSpec()
 	if conf.RegistryLogin() {
 		_, err := fmt.Fprintf(w, PostExportManyPlainText,
+			p.title,
 			conf.OutputPath(),
-			secretsPrefix(conf.KubeconfigSecret()),
-			secretsPrefix(conf.RegistryPassSecret()),
-			varsPrefix(conf.RegistryLoginUrlVar()),
-			varsPrefix(conf.RegistryUserVar()),
-			varsPrefix(conf.RegistryUrlVar()),
+			p.host,
+			p.secretRef(conf.KubeconfigSecret()),
+			p.secretRef(conf.RegistryPassSecret()),
+			p.varRef(conf.RegistryLoginUrlVar()),
+			p.varRef(conf.RegistryUserVar()),
+			p.varRef(conf.RegistryUrlVar()),
 		)
 		return err
 	}
 
 	_, err := fmt.Fprintf(w, PostExportOnePlainText,
+		p.title,
 		conf.OutputPath(),
-		secretsPrefix(conf.KubeconfigSecret()),
+		p.host,
+		p.secretRef(conf.KubeconfigSecret()),
 	)
 	return err
 }
@@ -1,20 +1,33 @@
 package ci
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 const defaultFuncCliVersion = "knative-v1.21.0"
 
-// ErrWorkflowExists is returned when a GitHub workflow file already exists and --force is not specified.
-var ErrWorkflowExists = errors.New("existing GitHub workflow detected, overwrite using the --force option")
+// ErrWorkflowExists is returned when a CI workflow file already exists which
+// is not managed by func, and --force is not specified.
+var ErrWorkflowExists = errors.New("existing CI workflow detected which is not managed by func, overwrite using the --force option")
 
+// ErrWorkflowDrift is returned by a check when the workflow differs from
+// what func would generate.
+var ErrWorkflowDrift = errors.New("CI workflow has drifted from the generated workflow")
+
+// githubActionsURL is the prefix of actions which are not hosted by the
+// platform running the workflow.
+const githubActionsURL = "https://github.com/"
+
+// githubWorkflow is a GitHub Actions workflow, which is also the format of
+// Forgejo and Gitea Actions workflows.
 type githubWorkflow struct {
+	title string `yaml:"-"`
+
 	Name string           `yaml:"name"`
 	On   workflowTriggers `yaml:"on"`
 	Jobs map[string]job   `yaml:"jobs"`
@@ -30,12 +43,16 @@ type pushTrigger struct {
 }
 
 type job struct {
-	RunsOn string `yaml:"runs-on"`
-	Steps  []step `yaml:"steps"`
+	Needs       []string          `yaml:"needs,omitempty"`
+	RunsOn      string            `yaml:"runs-on"`
+	Environment string            `yaml:"environment,omitempty"`
+	Outputs     map[string]string `yaml:"outputs,omitempty"`
+	Steps       []step            `yaml:"steps"`
 }
 
 type step struct {
 	Name string            `yaml:"name,omitempty"`
+	ID   string            `yaml:"id,omitempty"`
 	Env  map[string]string `yaml:"env,omitempty"`
 	Uses string            `yaml:"uses,omitempty"`
 	Run  string            `yaml:"run,omitempty"`
@@ -43,18 +60,23 @@ type step struct {
 }
 
 func NewGitHubWorkflow(conf CIConfig, messageWriter io.Writer) *githubWorkflow {
+	if conf.Promote() {
+		return newPromotionWorkflow(conf, messageWriter)
+	}
+
 	var steps []step
 	steps = createCheckoutStep(steps)
+	steps = createFuncCLIInstallStep(steps)
 	steps = createRuntimeTestStep(conf, messageWriter, steps)
 	steps = createK8ContextStep(conf, steps)
 	steps = createRegistryLoginStep(conf, steps)
-	steps = createFuncCLIInstallStep(steps)
 
 	steps = createFuncDeployStep(conf, steps)
 
 	return &githubWorkflow{
-		Name: conf.WorkflowName(),
-		On:   createPushTrigger(conf),
+		title: conf.platformSpec().title,
+		Name:  conf.WorkflowName(),
+		On:    createPushTrigger(conf),
 		Jobs: map[string]job{
 			"deploy": {
 				RunsOn: determineRunner(conf.SelfHostedRunner()),
@@ -64,6 +86,75 @@ func NewGitHubWorkflow(conf CIConfig, messageWriter io.Writer) *githubWorkflow {
 	}
 }
 
+// newPromotionWorkflow returns a workflow which builds the function's image
+// once, deploys it by digest to the staging environment, runs a smoke
+// invocation and then promotes the same digest to the production
+// environment.  Manual approval is configured on GitHub by adding required
+// reviewers to the production environment.
+func newPromotionWorkflow(conf CIConfig, messageWriter io.Writer) *githubWorkflow {
+	runner := determineRunner(conf.SelfHostedRunner())
+
+	var buildSteps []step
+	buildSteps = createCheckoutStep(buildSteps)
+	buildSteps = createFuncCLIInstallStep(buildSteps)
+	buildSteps = createRuntimeTestStep(conf, messageWriter, buildSteps)
+	buildSteps = createRegistryLoginStep(conf, buildSteps)
+	buildSteps = createFuncBuildStep(conf, buildSteps)
+
+	var stagingSteps []step
+	stagingSteps = createCheckoutStep(stagingSteps)
+	stagingSteps = createK8ContextStep(conf, stagingSteps)
+	stagingSteps = createFuncCLIInstallStep(stagingSteps)
+	stagingSteps = createFuncDeployImageStep(stagingSteps)
+	stagingSteps = createSmokeTestStep(stagingSteps)
+
+	var productionSteps []step
+	productionSteps = createCheckoutStep(productionSteps)
+	productionSteps = createK8ContextStep(conf, productionSteps)
+	productionSteps = createFuncCLIInstallStep(productionSteps)
+	productionSteps = createFuncDeployImageStep(productionSteps)
+
+	return &githubWorkflow{
+		title: conf.platformSpec().title,
+		Name:  conf.WorkflowName(),
+		On:    createPushTrigger(conf),
+		Jobs: map[string]job{
+			"build": {
+				RunsOn:  runner,
+				Outputs: map[string]string{"image": "${{ steps.build.outputs.image }}"},
+				Steps:   buildSteps,
+			},
+			"deploy-staging": {
+				Needs:       []string{"build"},
+				RunsOn:      runner,
+				Environment: conf.StagingEnvironment(),
+				Steps:       stagingSteps,
+			},
+			"deploy-production": {
+				Needs:       []string{"build", "deploy-staging"},
+				RunsOn:      runner,
+				Environment: conf.ProductionEnvironment(),
+				Steps:       productionSteps,
+			},
+		},
+	}
+}
+
+// NewForgejoWorkflow returns a Forgejo or Gitea Actions workflow.  These
+// resolve actions against their own configured instance by default, so
+// actions are referenced by their full GitHub URL.
+func NewForgejoWorkflow(conf CIConfig, messageWriter io.Writer) *githubWorkflow {
+	gw := NewGitHubWorkflow(conf, messageWriter)
+	for _, j := range gw.Jobs {
+		for i := range j.Steps {
+			if j.Steps[i].Uses != "" && !strings.Contains(j.Steps[i].Uses, "://") {
+				j.Steps[i].Uses = githubActionsURL + j.Steps[i].Uses
+			}
+		}
+	}
+	return gw
+}
+
 func createCheckoutStep(steps []step) []step {
 	checkoutCode := newStep("Checkout code").
 		withUses("actions/checkout@v4")
@@ -76,23 +167,15 @@ func createRuntimeTestStep(conf CIConfig, messageWriter io.Writer, steps []step)
 		return steps
 	}
 
-	testStep := newStep("Run tests")
-
-	switch conf.FnRuntime() {
-	case "go":
-		testStep.withRun("go test ./...")
-	case "node", "typescript":
-		testStep.withRun("npm ci && npm test")
-	case "python":
-		testStep.withRun("pip install . && python -m pytest")
-	case "quarkus":
-		testStep.withRun("./mvnw test")
-	default:
+	run, ok := runtimeTestCommand(conf.FnRuntime())
+	if !ok {
 		// best-effort user message; errors are non-critical
 		_, _ = fmt.Fprintf(messageWriter, "WARNING: test step not supported for runtime %s\n", conf.FnRuntime())
 		return steps
 	}
 
+	testStep := newStep("Run tests").withRun(run)
+
 	return append(steps, *testStep)
 }
 
@@ -137,16 +220,50 @@ func createFuncDeployStep(conf CIConfig, steps []step) []step {
 		deployFuncStep.withEnv("FUNC_REMOTE", "true")
 	}
 
-	registryUrl := newVariable(conf.RegistryUrlVar())
+	deployFuncStep.withEnv("FUNC_REGISTRY", registryUrl(conf)).
+		withRun("func deploy")
+
+	return append(steps, *deployFuncStep)
+}
+
+func registryUrl(conf CIConfig) string {
 	if conf.RegistryLogin() {
-		registryUrl = newVariable(conf.RegistryLoginUrlVar()) + "/" + newVariable(conf.RegistryUserVar())
+		return newVariable(conf.RegistryLoginUrlVar()) + "/" + newVariable(conf.RegistryUserVar())
 	}
-	deployFuncStep.withEnv("FUNC_REGISTRY", registryUrl).
-		withRun("func deploy")
+	return newVariable(conf.RegistryUrlVar())
+}
+
+// createFuncBuildStep builds and pushes the function's image, exposing the
+// image reference including its digest as the step's "image" output.
+func createFuncBuildStep(conf CIConfig, steps []step) []step {
+	buildFuncStep := newStep("Build and push function").
+		withID("build").
+		withEnv("FUNC_VERBOSE", "true").
+		withEnv("FUNC_BUILDER", conf.FnBuilder()).
+		withEnv("FUNC_REGISTRY", registryUrl(conf)).
+		withRun("func build --push\n" +
+			`echo "image=$(cat .func/built-image)" >> "$GITHUB_OUTPUT"`)
+
+	return append(steps, *buildFuncStep)
+}
+
+// createFuncDeployImageStep deploys the image built by the build job, such
+// that every environment runs exactly the same image.
+func createFuncDeployImageStep(steps []step) []step {
+	deployFuncStep := newStep("Deploy function").
+		withEnv("FUNC_VERBOSE", "true").
+		withRun("func deploy --image ${{ needs.build.outputs.image }}")
 
 	return append(steps, *deployFuncStep)
 }
 
+func createSmokeTestStep(steps []step) []step {
+	smokeTestStep := newStep("Smoke test function").
+		withRun("func invoke --target=remote")
+
+	return append(steps, *smokeTestStep)
+}
+
 func createPushTrigger(conf CIConfig) workflowTriggers {
 	result := workflowTriggers{
 		Push: &pushTrigger{Branches: []string{conf.Branch()}},
@@ -168,6 +285,11 @@ func (s *step) withUses(u string) *step {
 	return s
 }
 
+func (s *step) withID(id string) *step {
+	s.ID = id
+	return s
+}
+
 func (s *step) withRun(r string) *step {
 	s.Run = r
 	return s
@@ -194,32 +316,21 @@ func (s *step) withEnv(key, value string) *step {
 }
 
 func (gw *githubWorkflow) Export(path string, w WorkflowWriter, force bool, m io.Writer) error {
-	if !force && w.Exist(path) {
-		return ErrWorkflowExists
-	}
-
-	if w.Exist(path) {
-		// best-effort user message; errors are non-critical
-		_, _ = fmt.Fprintf(m, "WARNING: --force flag is set, overwriting existing GitHub Workflow file\n")
-	}
-
-	raw, err := gw.toYaml()
-	if err != nil {
-		return err
-	}
-
-	return w.Write(path, raw)
+	return export(gw.title, path, gw, markGitHubWorkflow, w, force, m)
 }
 
-func (gw *githubWorkflow) toYaml() ([]byte, error) {
-	var buf bytes.Buffer
-	encoder := yaml.NewEncoder(&buf)
-	encoder.SetIndent(2)
+func (gw *githubWorkflow) Check(path string, w WorkflowWriter) error {
+	return check(path, gw, markGitHubWorkflow, w)
+}
 
-	if err := encoder.Encode(gw); err != nil {
-		return nil, err
+// markGitHubWorkflow marks each job and step as managed by func.
+func markGitHubWorkflow(root *yaml.Node) {
+	jobs := mappingValue(root, "jobs")
+	if jobs == nil {
+		return
+	}
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		markManaged(jobs.Content[i])
+		markSequence(mappingValue(jobs.Content[i+1], "steps"))
 	}
-	encoder.Close()
-
-	return buf.Bytes(), nil
 }
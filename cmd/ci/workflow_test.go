@@ -32,7 +32,7 @@ func TestGitHubWorkflow_Export(t *testing.T) {
 	assert.NilError(t, configErr, "unexpected error when creating CIConfig")
 
 	gw := ci.NewGitHubWorkflow(cfg, &bytes.Buffer{})
-	exportErr := gw.Export(cfg.FnGitHubWorkflowFilepath(), bufferWriter, true, &bytes.Buffer{})
+	exportErr := gw.Export(cfg.FnWorkflowFilepath(), bufferWriter, true, &bytes.Buffer{})
 
 	// THEN
 	assert.NilError(t, exportErr, "unexpected error when exporting GitHub Workflow")
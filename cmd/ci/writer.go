@@ -17,6 +17,7 @@ var DefaultWorkflowWriter = &fileWriter{}
 // WorkflowWriter defines the interface for writing workflow files.
 type WorkflowWriter interface {
 	Exist(path string) bool
+	Read(path string) ([]byte, error)
 	Write(path string, raw []byte) error
 }
 
@@ -35,6 +36,11 @@ func (fw *fileWriter) Write(path string, raw []byte) error {
 	return nil
 }
 
+// Read returns the content of the file at the specified path.
+func (fw *fileWriter) Read(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
 func (fw *fileWriter) Exist(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -60,6 +66,11 @@ func (bw *BufferWriter) Write(path string, raw []byte) error {
 	return err
 }
 
+// Read is a fake implementation that returns the content of the buffer.
+func (bw *BufferWriter) Read(_ string) ([]byte, error) {
+	return bw.Buffer.Bytes(), nil
+}
+
 // Exist is a fake implementation that returns true if the buffer has content.
 func (bw *BufferWriter) Exist(_ string) bool {
 	return bw.Buffer != nil && bw.Buffer.Len() > 0
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/ory/viper"
 	"knative.dev/func/pkg/keda"
 
@@ -18,6 +20,8 @@ import (
 	"knative.dev/func/pkg/k8s"
 	"knative.dev/func/pkg/knative"
 	"knative.dev/func/pkg/oci"
+	"knative.dev/func/pkg/pipelines"
+	"knative.dev/func/pkg/pipelines/shipwright"
 	"knative.dev/func/pkg/pipelines/tekton"
 )
 
@@ -62,11 +66,12 @@ func NewClient(cfg ClientConfig, options ...fn.Option) (*fn.Client, func()) {
 		t  = newTransport(cfg.InsecureSkipVerify)        // may provide a custom impl which proxies
 		c  = newCredentialsProvider(config.Dir(), t, "") // for accessing registries
 		d  = newKnativeDeployer(cfg.Verbose)             // default deployer (can be overridden via options)
-		pp = newTektonPipelinesProvider(c, cfg.Verbose)
+		pp = newPipelinesProvider(c, d, remoteBuildConfig{Verbose: cfg.Verbose})
 		o  = []fn.Option{ // standard (shared) options for all commands
 			fn.WithVerbose(cfg.Verbose),
 			fn.WithTransport(t),
 			fn.WithRepositoriesPath(config.RepositoriesPath()),
+			fn.WithRepositoriesKeychain(newRepositoriesKeychain(config.Dir(), t)),
 			fn.WithScaffolder(buildpacks.NewScaffolder(cfg.Verbose)),
 			fn.WithBuilder(buildpacks.NewBuilder(buildpacks.WithVerbose(cfg.Verbose))),
 			fn.WithRemovers(knative.NewRemover(cfg.Verbose), k8s.NewRemover(cfg.Verbose), keda.NewRemover(cfg.Verbose)),
@@ -106,7 +111,27 @@ func newTransport(insecureSkipVerify bool) fnhttp.RoundTripCloser {
 // has cluster-flavor specific additional credential loaders to take advantage
 // of features or configuration nuances of cluster variants.
 // If authFilePath is provided (non-empty), it will be used as the primary auth file.
-func newCredentialsProvider(configPath string, t http.RoundTripper, authFilePath string) oci.CredentialsProvider {
+func newCredentialsProvider(configPath string, t http.RoundTripper, authFilePath string, opts ...creds.Opt) oci.CredentialsProvider {
+	options := []creds.Opt{
+		creds.WithPromptForCredentials(prompt.NewPromptForCredentials(os.Stdin, os.Stdout, os.Stderr)),
+		creds.WithPromptForCredentialStore(prompt.NewPromptForCredentialStore()),
+		creds.WithTransport(t),
+		creds.WithAdditionalCredentialLoaders(newCredentialLoaders()...),
+	}
+
+	// If a custom auth file path is provided, use it
+	if authFilePath != "" {
+		options = append(options, creds.WithAuthFilePath(authFilePath))
+	}
+
+	// Other cluster variants can be supported here
+	return creds.NewCredentialsProvider(configPath, append(options, opts...)...)
+}
+
+// newCredentialLoaders returns the credential loaders consulted in addition
+// to the defaults: those specific to cluster variants and those of the
+// --username, --password and --token flags.
+func newCredentialLoaders() []creds.CredentialsCallback {
 	additionalLoaders := append(k8s.GetOpenShiftDockerCredentialLoaders(), k8s.GetGoogleCredentialLoader()...)
 	additionalLoaders = append(additionalLoaders, k8s.GetECRCredentialLoader()...)
 	additionalLoaders = append(additionalLoaders, k8s.GetACRCredentialLoader()...)
@@ -126,33 +151,66 @@ func newCredentialsProvider(configPath string, t http.RoundTripper, authFilePath
 			return oci.Credentials{}, creds.ErrCredentialsNotFound
 		},
 	)
+	return additionalLoaders
+}
 
-	options := []creds.Opt{
-		creds.WithPromptForCredentials(prompt.NewPromptForCredentials(os.Stdin, os.Stdout, os.Stderr)),
-		creds.WithPromptForCredentialStore(prompt.NewPromptForCredentialStore()),
+// newRepositoriesKeychain returns the keychain with which template
+// repositories distributed as OCI artifacts are pulled.  Credentials are
+// those of the registries to which functions are pushed, though they need
+// only grant pulling.  The user is never prompted: repositories for which no
+// credentials are found are pulled anonymously, as is expected of public
+// repositories and required in non-interactive environments such as CI.
+func newRepositoriesKeychain(configPath string, t http.RoundTripper) authn.Keychain {
+	c := creds.NewCredentialsProvider(configPath,
 		creds.WithTransport(t),
-		creds.WithAdditionalCredentialLoaders(additionalLoaders...),
-	}
-
-	// If a custom auth file path is provided, use it
-	if authFilePath != "" {
-		options = append(options, creds.WithAuthFilePath(authFilePath))
-	}
+		creds.WithAdditionalCredentialLoaders(newCredentialLoaders()...),
+		creds.WithVerifyCredentials(func(ctx context.Context, image string, c oci.Credentials) error {
+			return creds.CheckPullAuth(ctx, image, c, t)
+		}))
+	return creds.NewKeychain(context.Background(), c)
+}
 
-	// Other cluster variants can be supported here
-	return creds.NewCredentialsProvider(configPath, options...)
+// remoteBuildConfig configures how remote builds are run and reported.
+type remoteBuildConfig struct {
+	Verbose bool
+	Quiet   bool // print only the build progress, not the build logs
+	NoCache bool // reset the function's build cache (Tekton only)
 }
 
-func newTektonPipelinesProvider(creds oci.CredentialsProvider, verbose bool) *tekton.PipelinesProvider {
+func newTektonPipelinesProvider(creds oci.CredentialsProvider, cfg remoteBuildConfig) *tekton.PipelinesProvider {
 	options := []tekton.Opt{
 		tekton.WithCredentialsProvider(creds),
-		tekton.WithVerbose(verbose),
+		tekton.WithVerbose(cfg.Verbose),
+		tekton.WithQuiet(cfg.Quiet),
+		tekton.WithNoCache(cfg.NoCache),
 		tekton.WithPipelineDecorator(deployDecorator{}),
 	}
 
 	return tekton.NewPipelinesProvider(options...)
 }
 
+func newShipwrightPipelinesProvider(creds oci.CredentialsProvider, deployer fn.Deployer, cfg remoteBuildConfig) *shipwright.PipelinesProvider {
+	options := []shipwright.Opt{
+		shipwright.WithCredentialsProvider(creds),
+		shipwright.WithVerbose(cfg.Verbose),
+		shipwright.WithQuiet(cfg.Quiet),
+		shipwright.WithPipelineDecorator(deployDecorator{}),
+		shipwright.WithDeployer(deployer),
+	}
+
+	return shipwright.NewPipelinesProvider(options...)
+}
+
+// newPipelinesProvider returns a pipelines provider which runs remote builds
+// with the remote builder selected by the function.  Shipwright only builds,
+// the deployer deploys the built image.
+func newPipelinesProvider(creds oci.CredentialsProvider, deployer fn.Deployer, cfg remoteBuildConfig) pipelines.Selector {
+	return pipelines.Selector{
+		fn.RemoteBuilderTekton:     newTektonPipelinesProvider(creds, cfg),
+		fn.RemoteBuilderShipwright: newShipwrightPipelinesProvider(creds, deployer, cfg),
+	}
+}
+
 func newKnativeDeployer(verbose bool) fn.Deployer {
 	options := []knative.DeployerOpt{
 		knative.WithDeployerVerbose(verbose),
@@ -1,10 +1,26 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	ociregistry "github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
 )
 
 // Test_NewTestClient ensures that the convenience method for
@@ -52,3 +68,71 @@ func Test_NewTestClient(t *testing.T) {
 	// by commands, allowing tests to "force" a command to use the mocked
 	// implementations.
 }
+
+// TestRepositoriesKeychain_Anonymous ensures that template repositories are
+// pulled anonymously when no credentials are stored, rather than prompting,
+// such that repositories can be added in non-interactive environments.
+func TestRepositoriesKeychain_Anonymous(t *testing.T) {
+	_ = FromTempDirectory(t)
+	t.Setenv("HOME", t.TempDir())
+
+	// No stdin: a prompt for credentials would fail reading it.
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	defer stdin.Close()
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = oldStdin }()
+
+	// A registry whose "private" repositories require credentials, addressed
+	// by a name which is not that of a local registry (for which empty
+	// credentials are assumed).  The test server's certificate is that of
+	// example.com.
+	reg := ociregistry.New(ociregistry.Logger(log.New(io.Discard, "", 0)))
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); !ok && strings.HasPrefix(r.URL.Path, "/v2/private/") {
+			w.Header().Add("WWW-Authenticate", "basic")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	rt := server.Client().Transport.(*http.Transport).Clone()
+	rt.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := name.ParseReference("example.com/public/templates:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Write(public, img, remote.WithTransport(rt)); err != nil {
+		t.Fatal(err)
+	}
+	private, err := name.ParseReference("example.com/private/templates:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keychain := newRepositoriesKeychain(t.TempDir(), rt)
+
+	// The public artifact is pulled anonymously
+	if _, err = remote.Image(public, remote.WithTransport(rt), remote.WithAuthFromKeychain(keychain)); err != nil {
+		t.Fatalf("expected the public artifact to be pulled anonymously, got %v", err)
+	}
+
+	// The private one is denied by the registry rather than prompted for
+	_, err = remote.Image(private, remote.WithTransport(rt), remote.WithAuthFromKeychain(keychain))
+	var terr *transport.Error
+	if !errors.As(err, &terr) || terr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the private artifact to be denied, got %v", err)
+	}
+}
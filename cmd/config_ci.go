@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/ory/viper"
@@ -18,7 +19,35 @@ func NewConfigCICmd(
 ) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "ci",
-		Short: "Generate a GitHub Workflow for function deployment",
+		Short: "Generate a CI/CD workflow for function deployment",
+		Long: `Generate a CI/CD workflow for function deployment
+
+Generates a workflow which tests the function and deploys it on every push to
+the current (or given) branch.  The platform is chosen using --platform:
+
+  github   GitHub Actions workflow in ` + ci.DefaultGitHubWorkflowDir + `
+  gitlab   GitLab CI pipeline in ` + ci.DefaultGitLabPipelineFilename + `
+  forgejo  Forgejo Actions workflow in ` + ci.DefaultForgejoWorkflowDir + `
+  gitea    Gitea Actions workflow in ` + ci.DefaultGiteaWorkflowDir + `
+
+Jobs, steps and script lines generated by func are marked with a
+"func:managed" comment.  Re-running the command updates only these items
+(for example the func version, registry variables and runtime test step),
+and preserves jobs and steps which were added by hand.  To take ownership of
+a generated item, remove its marker.  Use --force to overwrite the workflow
+entirely, and --check to verify that the workflow has not drifted from what
+func would generate, for example in a CI job or pre-commit hook.
+
+The secrets and variables which the workflow requires are printed once the
+workflow has been written, and must be created on the platform.
+
+With --promote (GitHub and GitLab) the workflow instead builds the image once
+and pushes it, deploys that exact image by digest to the staging environment,
+smoke tests it with "func invoke", and then promotes the same digest to the
+production environment.  Each environment has its own kubeconfig secret, and
+deployments to production wait for manual approval when the production
+environment is protected on the platform.
+`,
 		PreRunE: bindEnv(
 			ci.PathFlag,
 			ci.PlatformFlag,
@@ -33,8 +62,12 @@ func NewConfigCICmd(
 			ci.RemoteBuildFlag,
 			ci.SelfHostedRunnerFlag,
 			ci.TestStepFlag,
+			ci.PromoteFlag,
+			ci.StagingEnvironmentFlag,
+			ci.ProductionEnvironmentFlag,
 			ci.BranchFlag,
 			ci.ForceFlag,
+			ci.CheckFlag,
 			ci.VerboseFlag,
 		),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
@@ -42,7 +75,7 @@ func NewConfigCICmd(
 			workflowNameExplicit :=
 				cmd.Flags().Changed(ci.WorkflowNameFlag) || viper.IsSet(ci.WorkflowNameFlag)
 
-			return runConfigCIGitHub(
+			return runConfigCI(
 				loaderSaver,
 				writer,
 				currentBranch,
@@ -58,7 +91,7 @@ func NewConfigCICmd(
 	cmd.Flags().String(
 		ci.PlatformFlag,
 		ci.DefaultPlatform,
-		"Pick a CI/CD platform for which a manifest will be generated. Currently only GitHub is supported.",
+		"Pick a CI/CD platform for which a manifest will be generated ("+ci.SupportedPlatforms()+")",
 	)
 
 	cmd.Flags().String(
@@ -106,7 +139,7 @@ func NewConfigCICmd(
 	cmd.Flags().Bool(
 		ci.RegistryLoginFlag,
 		ci.DefaultRegistryLogin,
-		"Add a registry login step in the github workflow",
+		"Add a registry login step in the workflow",
 	)
 
 	cmd.Flags().Bool(
@@ -125,19 +158,43 @@ func NewConfigCICmd(
 	cmd.Flags().Bool(
 		ci.SelfHostedRunnerFlag,
 		ci.DefaultSelfHostedRunner,
-		"Use a 'self-hosted' runner instead of the default 'ubuntu-latest' for local runner execution",
+		"Use a 'self-hosted' runner instead of the default 'ubuntu-latest' for local runner execution (GitLab: tag jobs 'self-hosted' instead of using shared runners)",
 	)
 
 	cmd.Flags().Bool(
 		ci.TestStepFlag,
 		ci.DefaultTestStep,
-		"Add a language-specific test step (supported: go, node, typescript, python, quarkus)",
+		"Add a step running the function's tests with func test (supported: go, node, typescript, python, quarkus)",
+	)
+
+	cmd.Flags().Bool(
+		ci.PromoteFlag,
+		ci.DefaultPromote,
+		"Build once and promote the image by digest from a staging to a production environment with manual approval",
+	)
+
+	cmd.Flags().String(
+		ci.StagingEnvironmentFlag,
+		ci.DefaultStagingEnvironment,
+		"Name of the staging environment of a promotion workflow",
+	)
+
+	cmd.Flags().String(
+		ci.ProductionEnvironmentFlag,
+		ci.DefaultProductionEnvironment,
+		"Name of the production environment of a promotion workflow",
 	)
 
 	cmd.Flags().Bool(
 		ci.ForceFlag,
 		ci.DefaultForce,
-		"Use to overwrite an existing GitHub workflow",
+		"Use to overwrite an existing workflow, discarding changes made by hand",
+	)
+
+	cmd.Flags().Bool(
+		ci.CheckFlag,
+		ci.DefaultCheck,
+		"Exit non-zero if the func-managed items of the workflow differ from what would be generated, without writing it",
 	)
 
 	addVerboseFlag(cmd, ci.DefaultVerbose)
@@ -145,7 +202,7 @@ func NewConfigCICmd(
 	return cmd
 }
 
-func runConfigCIGitHub(
+func runConfigCI(
 	fnLoaderSaver common.FunctionLoaderSaver,
 	writer ci.WorkflowWriter,
 	currentBranch common.CurrentBranchFunc,
@@ -158,8 +215,17 @@ func runConfigCIGitHub(
 		return err
 	}
 
-	githubWorkflow := ci.NewGitHubWorkflow(cfg, messageWriter)
-	if err := githubWorkflow.Export(cfg.FnGitHubWorkflowFilepath(), writer, cfg.Force(), messageWriter); err != nil {
+	manifest := ci.NewManifest(cfg, messageWriter)
+	if cfg.Check() {
+		if err := manifest.Check(cfg.FnWorkflowFilepath(), writer); err != nil {
+			return err
+		}
+		// best-effort user message; errors are non-critical
+		_, _ = fmt.Fprintf(messageWriter, "%s is up to date\n", cfg.OutputPath())
+		return nil
+	}
+
+	if err := manifest.Export(cfg.FnWorkflowFilepath(), writer, cfg.Force(), messageWriter); err != nil {
 		return err
 	}
 
@@ -97,7 +97,10 @@ func TestNewConfigCICmd_ForceFlagOverwritesExistingWorkflowOnFS(t *testing.T) {
 		assert.Assert(t, yamlContains(content, workflowName))
 	})
 
-	t.Run("overwrite without force flag fails", func(t *testing.T) {
+	t.Run("re-run without force flag keeps user-added jobs", func(t *testing.T) {
+		path := filepath.Join(baseOpts.withFunc.Root, ci.DefaultGitHubWorkflowDir, ci.DefaultGitHubWorkflowFilename)
+		userJob := "  lint:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make lint\n"
+		appendErr := appendToFile(path, userJob)
 		opts := optsIntegration{
 			withFunc: baseOpts.withFunc,
 			args:     append(slices.Clone(baseOpts.args), "--workflow-name="+changedWorkflowName),
@@ -106,6 +109,24 @@ func TestNewConfigCICmd_ForceFlagOverwritesExistingWorkflowOnFS(t *testing.T) {
 		err := runConfigCiCmdIntegration(t, opts)
 		content := readWorkflowFile(t, opts.withFunc.Root)
 
+		assert.NilError(t, appendErr)
+		assert.NilError(t, err)
+		assert.Assert(t, yamlContains(content, changedWorkflowName))
+		assert.Assert(t, yamlContains(content, "make lint"))
+	})
+
+	t.Run("overwrite of a workflow not managed by func without force flag fails", func(t *testing.T) {
+		path := filepath.Join(baseOpts.withFunc.Root, ci.DefaultGitHubWorkflowDir, ci.DefaultGitHubWorkflowFilename)
+		writeErr := os.WriteFile(path, []byte("name: "+workflowName+"\non: push\n"), 0644)
+		opts := optsIntegration{
+			withFunc: baseOpts.withFunc,
+			args:     append(slices.Clone(baseOpts.args), "--workflow-name="+changedWorkflowName),
+		}
+
+		err := runConfigCiCmdIntegration(t, opts)
+		content := readWorkflowFile(t, opts.withFunc.Root)
+
+		assert.NilError(t, writeErr)
 		assert.ErrorIs(t, err, ci.ErrWorkflowExists)
 		assert.Assert(t, yamlContains(content, workflowName))
 		assert.Assert(t, !strings.Contains(content, changedWorkflowName))
@@ -187,6 +208,16 @@ func runConfigCiCmdIntegration(
 	return cmd.Execute()
 }
 
+func appendToFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(content)
+	return err
+}
+
 func readWorkflowFile(t *testing.T, root string) string {
 	t.Helper()
 
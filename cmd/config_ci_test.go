@@ -326,6 +326,153 @@ func TestNewConfigCICmd_GithubPlatformFlagSupported(t *testing.T) {
 	}
 }
 
+func TestNewConfigCICmd_PlatformManifests(t *testing.T) {
+	testCases := []struct {
+		platform        string
+		expectedPath    string
+		expectedTitle   string
+		expectedContent []string
+	}{
+		{
+			platform:      ci.PlatformGitLab,
+			expectedPath:  ci.DefaultGitLabPipelineFilename,
+			expectedTitle: "GitLab CI Pipeline",
+			expectedContent: []string{
+				`if: $CI_COMMIT_BRANCH == "` + issueBranch + `"`,
+				"- test",
+				"- deploy",
+				"image: golang:1",
+				"func test",
+				`"${KUBECONFIG}"`,
+				"export KUBECONFIG=",
+				"~/.docker/config.json",
+				"FUNC_REGISTRY: ${REGISTRY_LOGIN_URL}/${REGISTRY_USERNAME}",
+				"- func deploy",
+			},
+		},
+		{
+			platform:      ci.PlatformForgejo,
+			expectedPath:  filepath.Join(ci.DefaultForgejoWorkflowDir, ci.DefaultGitHubWorkflowFilename),
+			expectedTitle: "Forgejo Workflow",
+			expectedContent: []string{
+				"uses: https://github.com/actions/checkout@v4",
+				"uses: https://github.com/azure/k8s-set-context@v4",
+				"kubeconfig: ${{ secrets.KUBECONFIG }}",
+				"func deploy",
+			},
+		},
+		{
+			platform:      ci.PlatformGitea,
+			expectedPath:  filepath.Join(ci.DefaultGiteaWorkflowDir, ci.DefaultGitHubWorkflowFilename),
+			expectedTitle: "Gitea Workflow",
+			expectedContent: []string{
+				"uses: https://github.com/docker/login-action@v3",
+				"func deploy",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.platform, func(t *testing.T) {
+			// GIVEN
+			opts := defaultOpts()
+			opts.args = append(opts.args, "--platform="+tc.platform)
+
+			// WHEN
+			result := runConfigCiCmd(t, opts)
+
+			// THEN
+			assert.NilError(t, result.executeErr)
+			assert.Equal(t, result.actualPath, tc.expectedPath)
+			for _, c := range tc.expectedContent {
+				assert.Assert(t, yamlContains(result.gwYamlString, c))
+			}
+			assert.Assert(t, strings.Contains(result.stdOut, tc.expectedTitle+" created at: "+tc.expectedPath))
+		})
+	}
+}
+
+func TestNewConfigCICmd_GitLabPipelineBuilders(t *testing.T) {
+	t.Run("pack builder uses a docker-in-docker service", func(t *testing.T) {
+		opts := defaultOpts()
+		opts.runtime = "node"
+		opts.args = append(opts.args, "--platform=gitlab")
+
+		result := runConfigCiCmd(t, opts)
+
+		assert.NilError(t, result.executeErr)
+		assert.Assert(t, yamlContains(result.gwYamlString, "docker:27-dind"))
+		assert.Assert(t, yamlContains(result.gwYamlString, "DOCKER_HOST: tcp://docker:2375"))
+		assert.Assert(t, yamlContains(result.gwYamlString, "FUNC_BUILDER: pack"))
+	})
+
+	t.Run("remote build needs no container engine", func(t *testing.T) {
+		opts := defaultOpts()
+		opts.args = append(opts.args, "--platform=gitlab", "--remote", "--registry-login=false", "--test-step=false")
+
+		result := runConfigCiCmd(t, opts)
+
+		assert.NilError(t, result.executeErr)
+		assert.Assert(t, yamlContains(result.gwYamlString, `FUNC_REMOTE: "true"`))
+		assert.Assert(t, yamlContains(result.gwYamlString, "FUNC_REGISTRY: ${REGISTRY_URL}"))
+		assert.Assert(t, !strings.Contains(result.gwYamlString, "dind"))
+		assert.Assert(t, !strings.Contains(result.gwYamlString, "stage: test"))
+		assert.Assert(t, !strings.Contains(result.gwYamlString, "config.json"))
+	})
+}
+
+func TestNewConfigCICmd_PromotionWorkflow(t *testing.T) {
+	t.Run("GitHub workflow builds once and promotes by digest", func(t *testing.T) {
+		opts := defaultOpts()
+		opts.args = append(opts.args, "--promote", "--production-environment=prod")
+
+		result := runConfigCiCmd(t, opts)
+
+		assert.NilError(t, result.executeErr)
+		gw := result.gwYamlString
+		assert.Assert(t, yamlContains(gw, "func build --push"))
+		assert.Assert(t, yamlContains(gw, `echo "image=$(cat .func/built-image)" >> "$GITHUB_OUTPUT"`))
+		assert.Assert(t, yamlContains(gw, "image: ${{ steps.build.outputs.image }}"))
+		assert.Assert(t, yamlContains(gw, "environment: staging"))
+		assert.Assert(t, yamlContains(gw, "environment: prod"))
+		assert.Assert(t, yamlContains(gw, "- deploy-staging"))
+		assert.Assert(t, yamlContains(gw, "func invoke --target=remote"))
+		assert.Equal(t, strings.Count(gw, "func deploy --image ${{ needs.build.outputs.image }}"), 2)
+		assert.Assert(t, strings.Contains(result.stdOut, "Create the environments 'staging' and 'prod'"))
+	})
+
+	t.Run("GitLab pipeline promotes to production manually", func(t *testing.T) {
+		opts := defaultOpts()
+		opts.args = append(opts.args, "--promote", "--platform=gitlab", "--verbose")
+
+		result := runConfigCiCmd(t, opts)
+
+		assert.NilError(t, result.executeErr)
+		gp := result.gwYamlString
+		assert.Assert(t, yamlContains(gp, "dotenv: build.env"))
+		assert.Assert(t, yamlContains(gp, "name: staging"))
+		assert.Assert(t, yamlContains(gp, "name: production"))
+		assert.Assert(t, yamlContains(gp, "when: manual"))
+		assert.Equal(t, strings.Count(gp, `func deploy --image "${IMAGE}"`), 2)
+		assert.Assert(t, strings.Contains(result.stdOut, fmt.Sprintf(ci.PromotionPlainText, "staging", "production")))
+	})
+
+	t.Run("unsupported combinations fail", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"--promote", "--platform=forgejo"},
+			{"--promote", "--remote"},
+			{"--promote", "--staging-environment=prod", "--production-environment=prod"},
+		} {
+			opts := defaultOpts()
+			opts.args = append(opts.args, args...)
+
+			result := runConfigCiCmd(t, opts)
+
+			assert.Assert(t, result.executeErr != nil, "expected error for %v", args)
+		}
+	})
+}
+
 func TestNewConfigCICmd_PlatformFlagErrors(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -335,12 +482,12 @@ func TestNewConfigCICmd_PlatformFlagErrors(t *testing.T) {
 		{
 			name:        "empty platform value",
 			platformArg: "--platform=",
-			expectedErr: fmt.Sprintf("platform must not be empty, supported: %s", ci.DefaultPlatform),
+			expectedErr: fmt.Sprintf("platform must not be empty, supported: %s", ci.SupportedPlatforms()),
 		},
 		{
 			name:        "unsupported platform value",
 			platformArg: "--platform=unsupported",
-			expectedErr: fmt.Sprintf("unsupported support is not implemented, supported: %s", ci.DefaultPlatform),
+			expectedErr: fmt.Sprintf("unsupported support is not implemented, supported: %s", ci.SupportedPlatforms()),
 		},
 	}
 
@@ -375,7 +522,23 @@ func TestNewConfigCICmd_ForceFlagOverwritesExistingWorkflow(t *testing.T) {
 		assert.Assert(t, !strings.Contains(result.stdOut, forceWarning))
 	})
 
-	t.Run("overwrite without force flag fails", func(t *testing.T) {
+	t.Run("re-run without force flag updates func-managed items", func(t *testing.T) {
+		opts := defaultOpts()
+		opts.withWriter = sharedWriter
+		opts.args = append(opts.args, "--workflow-name="+changedWorkflowName)
+
+		result := runConfigCiCmd(t, opts)
+
+		assert.NilError(t, result.executeErr)
+		assert.Assert(t, yamlContains(result.gwYamlString, changedWorkflowName))
+		assert.Assert(t, strings.Contains(result.stdOut, "Updating func-managed items"))
+		assert.Assert(t, !strings.Contains(result.stdOut, forceWarning))
+	})
+
+	t.Run("overwrite of a workflow not managed by func without force flag fails", func(t *testing.T) {
+		unmanaged := "name: " + workflowName + "\non: push\n"
+		sharedWriter.Buffer.Reset()
+		sharedWriter.Buffer.WriteString(unmanaged)
 		opts := defaultOpts()
 		opts.withWriter = sharedWriter
 		opts.args = append(opts.args, "--workflow-name="+changedWorkflowName)
@@ -383,8 +546,7 @@ func TestNewConfigCICmd_ForceFlagOverwritesExistingWorkflow(t *testing.T) {
 		result := runConfigCiCmd(t, opts)
 
 		assert.ErrorIs(t, result.executeErr, ci.ErrWorkflowExists)
-		assert.Assert(t, yamlContains(result.gwYamlString, workflowName))
-		assert.Assert(t, !strings.Contains(result.gwYamlString, changedWorkflowName))
+		assert.Equal(t, result.gwYamlString, unmanaged)
 		assert.Assert(t, !strings.Contains(result.stdOut, forceWarning))
 	})
 
@@ -402,11 +564,76 @@ func TestNewConfigCICmd_ForceFlagOverwritesExistingWorkflow(t *testing.T) {
 	})
 }
 
+func TestNewConfigCICmd_ReRunPreservesUserItems(t *testing.T) {
+	sharedWriter := ci.NewBufferWriter()
+	opts := defaultOpts()
+	opts.withWriter = sharedWriter
+	result := runConfigCiCmd(t, opts)
+	assert.NilError(t, result.executeErr)
+
+	userStep := "      - name: Lint\n        run: make lint\n"
+	userJob := "  notify:\n    runs-on: ubuntu-latest\n    steps:\n      - run: ./notify.sh\n"
+	edited := strings.Replace(result.gwYamlString, "      # func:managed\n      - name: Setup Kubernetes context", userStep+"      # func:managed\n      - name: Setup Kubernetes context", 1)
+	sharedWriter.Buffer.Reset()
+	sharedWriter.Buffer.WriteString(edited + userJob)
+
+	opts = defaultOpts()
+	opts.withWriter = sharedWriter
+	opts.args = append(opts.args, "--test-step=false")
+	result = runConfigCiCmd(t, opts)
+
+	assert.NilError(t, result.executeErr)
+	assert.Assert(t, !strings.Contains(result.gwYamlString, "Run tests"), "managed test step should be removed")
+	assert.Assert(t, yamlContains(result.gwYamlString, "- name: Lint"))
+	assert.Assert(t, yamlContains(result.gwYamlString, "./notify.sh"))
+	assert.Assert(t, strings.Index(result.gwYamlString, "name: Lint") < strings.Index(result.gwYamlString, "name: Setup Kubernetes context"),
+		"user step should keep its position")
+}
+
+func TestNewConfigCICmd_CheckFlag(t *testing.T) {
+	sharedWriter := ci.NewBufferWriter()
+
+	t.Run("missing workflow has drifted", func(t *testing.T) {
+		opts := defaultOpts()
+		opts.withWriter = sharedWriter
+		opts.args = append(opts.args, "--check")
+
+		result := runConfigCiCmd(t, opts)
+
+		assert.ErrorIs(t, result.executeErr, ci.ErrWorkflowDrift)
+	})
+
+	t.Run("up to date workflow passes", func(t *testing.T) {
+		opts := defaultOpts()
+		opts.withWriter = sharedWriter
+		assert.NilError(t, runConfigCiCmd(t, opts).executeErr)
+		generated := sharedWriter.Buffer.String()
+
+		opts.args = append(opts.args, "--check")
+		result := runConfigCiCmd(t, opts)
+
+		assert.NilError(t, result.executeErr)
+		assert.Assert(t, strings.Contains(result.stdOut, "is up to date"))
+		assert.Equal(t, sharedWriter.Buffer.String(), generated, "--check must not write the workflow")
+	})
+
+	t.Run("changed configuration has drifted", func(t *testing.T) {
+		opts := defaultOpts()
+		opts.withWriter = sharedWriter
+		opts.args = append(opts.args, "--check", "--registry-url-variable-name=MY_REGISTRY_URL", "--registry-login=false")
+
+		result := runConfigCiCmd(t, opts)
+
+		assert.ErrorIs(t, result.executeErr, ci.ErrWorkflowDrift)
+	})
+}
+
 func TestNewConfigCICmd_VerboseFlagPrintsWorkflowDetails(t *testing.T) {
 	t.Run("verbose flag prints default Github Workflow configuration", func(t *testing.T) {
 		opts := defaultOpts()
 		opts.args = append(opts.args, "--verbose")
 		expectedMessage := fmt.Sprintf(ci.MainLayoutPlainText,
+			githubTitle,
 			defaultOutputPath,
 			ci.DefaultWorkflowName,
 			issueBranch,
@@ -447,6 +674,7 @@ func TestNewConfigCICmd_VerboseFlagPrintsWorkflowDetails(t *testing.T) {
 			"--registry-url-variable-name=DEV_REGISTRY_URL",
 		)
 		expectedMessage := fmt.Sprintf(ci.MainLayoutPlainText,
+			githubTitle,
 			defaultOutputPath,
 			customWorkflowName,
 			issueBranch,
@@ -477,6 +705,7 @@ func TestNewConfigCICmd_VerboseFlagPrintsWorkflowDetails(t *testing.T) {
 			"--registry-login=false",
 		)
 		expectedMessage := fmt.Sprintf(ci.MainLayoutPlainText,
+			githubTitle,
 			defaultOutputPath,
 			ci.DefaultWorkflowName,
 			issueBranch,
@@ -501,7 +730,9 @@ func TestNewConfigCICmd_PostExportMessageShown(t *testing.T) {
 	t.Run("a message is shown with all secrets and variables for k8 and registry which needs creation", func(t *testing.T) {
 		opts := defaultOpts()
 		expectedMessage := fmt.Sprintf(ci.PostExportManyPlainText,
+			githubTitle,
 			defaultOutputPath,
+			githubHost,
 			"secrets."+ci.DefaultKubeconfigSecretName,
 			"secrets."+ci.DefaultRegistryPassSecretName,
 			"vars."+ci.DefaultRegistryLoginUrlVariableName,
@@ -518,7 +749,9 @@ func TestNewConfigCICmd_PostExportMessageShown(t *testing.T) {
 		opts := defaultOpts()
 		opts.args = append(opts.args, "--registry-login=false")
 		expectedMessage := fmt.Sprintf(ci.PostExportOnePlainText,
+			githubTitle,
 			defaultOutputPath,
+			githubHost,
 			"secrets."+ci.DefaultKubeconfigSecretName,
 		)
 
@@ -558,29 +791,29 @@ func TestNewConfigCICmd_TestStepPerRuntime(t *testing.T) {
 		expectedRun string
 	}{
 		{
-			name:        "go runtime adds go test step",
+			name:        "go runtime adds func test step",
 			runtime:     "go",
-			expectedRun: "go test ./...",
+			expectedRun: "func test",
 		},
 		{
-			name:        "nodejs runtime adds npm test step",
+			name:        "nodejs runtime installs dependencies and adds func test step",
 			runtime:     "node",
-			expectedRun: "npm ci && npm test",
+			expectedRun: "npm ci && func test",
 		},
 		{
-			name:        "typescript runtime adds npm test step",
+			name:        "typescript runtime installs dependencies and adds func test step",
 			runtime:     "typescript",
-			expectedRun: "npm ci && npm test",
+			expectedRun: "npm ci && func test",
 		},
 		{
-			name:        "python runtime adds python -m pytest step",
+			name:        "python runtime installs dependencies and adds func test step",
 			runtime:     "python",
-			expectedRun: "pip install . && python -m pytest",
+			expectedRun: "pip install . && func test",
 		},
 		{
-			name:        "quarkus runtime adds mvnw test step",
+			name:        "quarkus runtime adds func test step",
 			runtime:     "quarkus",
-			expectedRun: "./mvnw test",
+			expectedRun: "func test",
 		},
 	}
 
@@ -764,6 +997,8 @@ const (
 	issueBranch        = "issue-778-current-branch"
 	fnName             = "github-ci-func"
 	forceWarning       = "WARNING: --force flag is set, overwriting existing GitHub Workflow file"
+	githubTitle        = "GitHub Workflow"
+	githubHost         = "github.com"
 	customWorkflowName = "Deploy Checkout Service"
 	runTestStepName    = "Run tests"
 )
@@ -906,7 +1141,7 @@ func assertDefaultWorkflowWithBranch(t *testing.T, actualGw, branch string) {
 	assert.Assert(t, yamlContains(actualGw, "actions/checkout@v4"))
 
 	assert.Assert(t, yamlContains(actualGw, "Run tests"))
-	assert.Assert(t, yamlContains(actualGw, "go test ./..."))
+	assert.Assert(t, yamlContains(actualGw, "func test"))
 
 	assert.Assert(t, yamlContains(actualGw, "Setup Kubernetes context"))
 	assert.Assert(t, yamlContains(actualGw, "azure/k8s-set-context@v4"))
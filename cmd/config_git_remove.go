@@ -22,10 +22,14 @@ func NewConfigGitRemoveCmd(newClient ClientFactory) *cobra.Command {
 	directory or from the directory specified with --path.
 
 	It also removes any generated resources that are used for Git based build and deployment,
-	such as local generated Pipelines resources and any resources generated on the cluster.
+	such as local generated Pipelines resources, any resources generated on the cluster
+	and the webhook on the Git repository, so that the repository stops sending events.
+
+	Use --rotate-webhook-secret to keep the configuration and replace the secret
+	used to validate webhook payloads, both on the Git repository and on the cluster.
 	`,
 		SuggestFor: []string{"rem", "rmeove", "del", "dle"},
-		PreRunE:    bindEnv("path", "delete-local", "delete-cluster"),
+		PreRunE:    bindEnv("path", "delete-local", "delete-cluster", "delete-remote", "rotate-webhook-secret"),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			return runConfigGitRemoveCmd(cmd, newClient)
 		},
@@ -40,6 +44,8 @@ func NewConfigGitRemoveCmd(newClient ClientFactory) *cobra.Command {
 	// Resources generated related Flags:
 	cmd.Flags().Bool("delete-local", false, "Delete local resources (pipeline templates).")
 	cmd.Flags().Bool("delete-cluster", false, "Delete cluster resources (credentials and config on the cluster).")
+	cmd.Flags().Bool("delete-remote", false, "Delete remote resources (webhook on the Git repository).")
+	cmd.Flags().Bool("rotate-webhook-secret", false, "Rotate the webhook secret on the Git repository and on the cluster instead of deleting resources.")
 
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
@@ -66,22 +72,27 @@ func newConfigGitRemoveConfig(_ *cobra.Command) (c configGitRemoveConfig) {
 	flagSet := false
 
 	// decide what resources we should delete:
-	// - by default all resources
+	// - by default all resources, or none when rotating the webhook secret
 	// - if any parameter is explicitly specified then get value from parameters
-	deleteLocal := true
-	deleteCluster := true
-	if viper.HasChanged("delete-local") || viper.HasChanged("delete-cluster") {
+	rotate := viper.GetBool("rotate-webhook-secret")
+	deleteLocal := !rotate
+	deleteCluster := !rotate
+	deleteRemote := !rotate
+	if viper.HasChanged("delete-local") || viper.HasChanged("delete-cluster") || viper.HasChanged("delete-remote") {
 		deleteLocal = viper.GetBool("delete-local")
 		deleteCluster = viper.GetBool("delete-cluster")
+		deleteRemote = viper.GetBool("delete-remote")
 		flagSet = true
 	}
 
 	c = configGitRemoveConfig{
-		flagSet: flagSet,
+		flagSet: flagSet || rotate,
 
 		metadata: pipelines.PacMetadata{
 			ConfigureLocalResources:   deleteLocal,
 			ConfigureClusterResources: deleteCluster,
+			ConfigureRemoteResources:  deleteRemote,
+			RotateWebhookSecret:       rotate,
 		},
 	}
 
@@ -121,11 +132,29 @@ func (c configGitRemoveConfig) Prompt(f fn.Function) (configGitRemoveConfig, err
 			return c, err
 		}
 		c.metadata.ConfigureClusterResources = deleteCluster
+
+		deleteRemote := true
+		if err := survey.AskOne(&survey.Confirm{
+			Message: "Do you want to delete the webhook on the Git repository?",
+			Help:    "Delete the webhook which sends Git events to Pipelines as Code, requires the access token stored on the cluster.",
+			Default: deleteRemote,
+		}, &deleteRemote, survey.WithValidator(survey.Required)); err != nil {
+			return c, err
+		}
+		c.metadata.ConfigureRemoteResources = deleteRemote
 	}
 
 	return c, nil
 }
 
+// Validate the config passes an initial consistency check
+func (c configGitRemoveConfig) Validate() error {
+	if c.metadata.RotateWebhookSecret && (c.metadata.ConfigureRemoteResources || c.metadata.ConfigureClusterResources) {
+		return fmt.Errorf("--rotate-webhook-secret cannot be used when deleting the webhook or the cluster resources")
+	}
+	return nil
+}
+
 // Configure the given function.  Updates a function struct with all
 // configurable values.  Note that the config already includes function's
 // current values, as they were passed through via flag defaults.
@@ -159,6 +188,13 @@ func runConfigGitRemoveCmd(cmd *cobra.Command, newClient ClientFactory) (err err
 	if cfg, err = cfg.Prompt(f); err != nil {
 		return
 	}
+	if err = cfg.Validate(); err != nil {
+		return
+	}
+
+	// resources are removed based on the function's current Git settings,
+	// which Configure may clear
+	current := f
 	if f, err = cfg.Configure(f); err != nil { // Updates f with deploy cfg
 		return
 	}
@@ -166,5 +202,5 @@ func runConfigGitRemoveCmd(cmd *cobra.Command, newClient ClientFactory) (err err
 	client, done := newClient(ClientConfig{Verbose: cfg.Verbose})
 	defer done()
 
-	return client.RemovePAC(cmd.Context(), f, cfg.metadata)
+	return client.RemovePAC(cmd.Context(), current, cfg.metadata)
 }
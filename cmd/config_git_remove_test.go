@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	"knative.dev/func/pkg/pipelines"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestConfigGitRemove_Resources ensures the flags select which resources are
+// removed, and that the provider receives the function's Git settings as
+// they were before being removed from the function.
+func TestConfigGitRemove_Resources(t *testing.T) {
+	const url = "https://example.com/user/repo"
+
+	tests := []struct {
+		name string
+		args []string
+		want pipelines.PacMetadata
+	}{
+		{
+			name: "webhook only",
+			args: []string{"--delete-remote"},
+			want: pipelines.PacMetadata{ConfigureRemoteResources: true},
+		},
+		{
+			name: "all resources",
+			args: []string{"--delete-local", "--delete-cluster", "--delete-remote"},
+			want: pipelines.PacMetadata{ConfigureLocalResources: true, ConfigureClusterResources: true, ConfigureRemoteResources: true},
+		},
+		{
+			name: "rotate webhook secret",
+			args: []string{"--rotate-webhook-secret"},
+			want: pipelines.PacMetadata{RotateWebhookSecret: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := FromTempDirectory(t)
+			f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root})
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Build.Git.URL = url
+			if err = f.Write(); err != nil {
+				t.Fatal(err)
+			}
+
+			pipeliner := mock.NewPipelinesProvider()
+			pipeliner.RemovePACFn = func(f fn.Function) error {
+				if f.Build.Git.URL != url {
+					t.Errorf("expected git URL %q, got %q", url, f.Build.Git.URL)
+				}
+				return nil
+			}
+
+			cmd := NewConfigGitRemoveCmd(NewTestClient(fn.WithPipelinesProvider(pipeliner)))
+			cmd.SetArgs(tt.args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !pipeliner.RemovePACInvoked {
+				t.Fatal("pipelines provider was not invoked")
+			}
+			if pipeliner.RemovePACMetadata != tt.want {
+				t.Errorf("expected metadata %+v, got %+v", tt.want, pipeliner.RemovePACMetadata)
+			}
+		})
+	}
+}
+
+// TestConfigGitRemove_RotateConflict ensures the webhook secret can't be
+// rotated while deleting the resources which use it.
+func TestConfigGitRemove_RotateConflict(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	pipeliner := mock.NewPipelinesProvider()
+	cmd := NewConfigGitRemoveCmd(NewTestClient(fn.WithPipelinesProvider(pipeliner)))
+	cmd.SetArgs([]string{"--rotate-webhook-secret", "--delete-remote"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error rotating the secret of a deleted webhook")
+	}
+	if pipeliner.RemovePACInvoked {
+		t.Fatal("pipelines provider should not be invoked")
+	}
+}
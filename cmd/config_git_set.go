@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -146,7 +147,7 @@ func newConfigGitSetConfig(_ *cobra.Command) (c configGitSetConfig) {
 	return c
 }
 
-func (c configGitSetConfig) Prompt(f fn.Function) (configGitSetConfig, error) {
+func (c configGitSetConfig) Prompt(ctx context.Context, f fn.Function) (configGitSetConfig, error) {
 	var err error
 	if c.buildConfig, err = c.buildConfig.Prompt(); err != nil {
 		return c, err
@@ -216,7 +217,7 @@ func (c configGitSetConfig) Prompt(f fn.Function) (configGitSetConfig, error) {
 	if c.metadata.ConfigureRemoteResources {
 		// Configure Git provider
 		if c.metadata.GitProvider == "" {
-			provider, err := git.GitProviderName(c.GitURL)
+			provider, err := git.GitProviderName(ctx, c.GitURL)
 			if err != nil {
 				msg := "Please select the type of the Git platform provider to setup webhook:"
 				if err = survey.AskOne(&survey.Select{
@@ -294,7 +295,7 @@ func runConfigGitSetCmd(cmd *cobra.Command, newClient ClientFactory) (err error)
 	if f, err = fn.NewFunction(cfg.Path); err != nil {
 		return
 	}
-	if cfg, err = cfg.Prompt(f); err != nil {
+	if cfg, err = cfg.Prompt(cmd.Context(), f); err != nil {
 		return
 	}
 	if err = cfg.Validate(cmd); err != nil {
@@ -3,7 +3,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 	"text/tabwriter"
 	"text/template"
@@ -12,6 +14,7 @@ import (
 	"github.com/ory/viper"
 	"github.com/spf13/cobra"
 
+	"knative.dev/func/cmd/prompt"
 	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/utils"
@@ -37,7 +40,7 @@ NAME
 
 SYNOPSIS
 	{{.Name}} create [-l|--language] [-t|--template] [-r|--repository]
-	            [-p |--path] [-c|--confirm]  [-v|--verbose]
+	            [--param <name>=<value>] [-p |--path] [-c|--confirm]  [-v|--verbose]
 
 DESCRIPTION
 	Creates a new function project.
@@ -58,6 +61,12 @@ DESCRIPTION
 
 	To install more language runtimes and their templates see '{{.Name}} repository'.
 
+	Template Parameters:
+	Templates may declare parameters in their manifest.yaml, whose values are
+	rendered into the new function's files.  Provide them with --param, which
+	may be repeated.  With --confirm, parameters not provided are prompted for.
+	Parameters with which the function was created are recorded in func.lock.
+
 
 EXAMPLES
 	o Create a Node.js function in the current directory (the default path) which
@@ -69,6 +78,10 @@ EXAMPLES
 
 	o Create a Go function which handles CloudEvents in ./myfunc.
 	  $ {{.Name}} create -l go -t cloudevents myfunc
+
+	o Create a Go function from a template of an installed repository which
+	  declares a 'module' parameter.
+	  $ {{.Name}} create -l go -t boson/hello-world --param module=example.com/myfunc myfunc
 		`,
 		SuggestFor: []string{"vreate", "creaet", "craete", "new"},
 		PreRunE:    bindEnv("language", "template", "repository", "confirm", "verbose", "path"),
@@ -88,6 +101,7 @@ EXAMPLES
 	cmd.Flags().StringP("language", "l", cfg.Language, "Language Runtime (see help text for list) ($FUNC_LANGUAGE)")
 	cmd.Flags().StringP("template", "t", fn.DefaultTemplate, "Function template. (see help text for list) ($FUNC_TEMPLATE)")
 	cmd.Flags().StringP("repository", "r", "", "URI to a Git repository containing the specified template ($FUNC_REPOSITORY)")
+	cmd.Flags().StringArray("param", []string{}, "Value of a parameter declared by the template in the form NAME=VALUE. May be provided multiple times")
 
 	addConfirmFlag(cmd, cfg.Confirm)
 	// Add --path flag (default "") for consistency with other commands.
@@ -135,14 +149,29 @@ func runCreate(cmd *cobra.Command, args []string, newClient ClientFactory) (err
 		return
 	}
 
+	// Prompt for the template's parameters not provided (--confirm)
+	if cfg.Confirm {
+		t, err := client.Templates().Get(cfg.Runtime, cfg.Template)
+		if err != nil {
+			return err
+		}
+		promptForParams := prompt.NewPromptForTemplateParameters(os.Stdin, os.Stdout, os.Stderr)
+		if cfg.Params, err = promptForParams(t.Parameters(), cfg.Params); err != nil {
+			return err
+		}
+	}
+
 	// Create
 	_, err = client.Init(fn.Function{
-		Name:     cfg.Name,
-		Root:     cfg.Path,
-		Runtime:  cfg.Runtime,
-		Template: cfg.Template,
+		Name:               cfg.Name,
+		Root:               cfg.Path,
+		Runtime:            cfg.Runtime,
+		Template:           cfg.Template,
+		TemplateParameters: cfg.Params,
 	})
-	if err != nil {
+	if errors.Is(err, fn.ErrTemplateParameterRequired) {
+		return fmt.Errorf("%w\nProvide it with --param <name>=<value>, or when prompted with --confirm", err)
+	} else if err != nil {
 		return err
 	}
 	// Confirm
@@ -168,6 +197,9 @@ type createConfig struct {
 
 	// Name of the function
 	Name string
+
+	// Params are values of the parameters declared by the template.
+	Params map[string]string
 }
 
 // newCreateConfig returns a config populated from the current execution context
@@ -223,7 +255,13 @@ func newCreateConfig(cmd *cobra.Command, args []string, newClient ClientFactory)
 	// Config is the final default values based off the execution context.
 	// When prompting, these become the defaults presented.
 
+	params, err := parseTemplateParams(cmd)
+	if err != nil {
+		return
+	}
+
 	cfg = createConfig{
+		Params:     params,
 		Name:       dirName, // TODO: refactor to be git-like
 		Path:       absolutePath,
 		Repository: viper.GetString("repository"),
@@ -265,9 +303,29 @@ func newCreateConfig(cmd *cobra.Command, args []string, newClient ClientFactory)
 		fmt.Printf("Repository:   %v\n", cfg.Repository) // show only the override
 	}
 	fmt.Printf("Template:     %v\n", cfg.Template)
+	for _, name := range slices.Sorted(maps.Keys(cfg.Params)) {
+		fmt.Printf("Parameter:    %v=%v\n", name, cfg.Params[name])
+	}
 	return
 }
 
+// parseTemplateParams returns the template parameters provided via --param.
+func parseTemplateParams(cmd *cobra.Command) (map[string]string, error) {
+	pp, err := cmd.Flags().GetStringArray("param")
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{}
+	for _, p := range pp {
+		name, value, ok := strings.Cut(p, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid template parameter %q. Expected NAME=VALUE", p)
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
 // singleCommand that could be used by the current user to minimally recreate the current state.
 func singleCommand(cmd *cobra.Command, args []string, cfg createConfig) string {
 	var b strings.Builder
@@ -279,6 +337,9 @@ func singleCommand(cmd *cobra.Command, args []string, cfg createConfig) string {
 	if cmd.Flags().Lookup("repository").Changed {
 		b.WriteString(" -r " + cfg.Repository)
 	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Params)) {
+		b.WriteString(fmt.Sprintf(" --param %v=%v", name, cfg.Params[name]))
+	}
 	if cmd.Flags().Lookup("verbose").Changed {
 		b.WriteString(fmt.Sprintf(" -v %v", cfg.Verbose))
 	}
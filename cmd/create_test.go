@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	fn "knative.dev/func/pkg/functions"
 	. "knative.dev/func/pkg/testing"
 	"knative.dev/func/pkg/utils"
 )
@@ -98,3 +101,48 @@ func TestCreate_ConfigOptional(t *testing.T) {
 	// Not failing is success. Config files or settings beyond what are
 	// automatically written to the given config home are currently optional.
 }
+
+// TestCreate_TemplateParameters ensures that values of the parameters
+// declared by a template are accepted via --param, and that required
+// parameters which were not provided are reported.
+func TestCreate_TemplateParameters(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	tpl := filepath.Join(root, "repository", "go", "params")
+	if err := os.MkdirAll(tpl, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "parameters:\n  - name: module\n    required: true\n"
+	if err := os.WriteFile(filepath.Join(tpl, "manifest.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tpl, "go.mod.tmpl"), []byte("module {{.module}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := "file://" + filepath.ToSlash(filepath.Join(root, "repository"))
+
+	cmd := NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"-l", "go", "-t", "params", "-r", repo, "a"})
+	if err := cmd.Execute(); !errors.Is(err, fn.ErrTemplateParameterRequired) {
+		t.Fatalf("expected ErrTemplateParameterRequired, got %v", err)
+	}
+
+	cmd = NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"-l", "go", "-t", "params", "-r", repo, "--param", "module=example.com/b", "b"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	bb, err := os.ReadFile(filepath.Join(root, "b", "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bb) != "module example.com/b\n" {
+		t.Fatalf("unexpected go.mod %q", bb)
+	}
+
+	cmd = NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"-l", "go", "-t", "params", "-r", repo, "--param", "module", "c"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for a parameter without a value")
+	}
+}
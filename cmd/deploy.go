@@ -134,7 +134,7 @@ EXAMPLES
 			"git-url", "image", "namespace", "path", "platform", "push", "pvc-size",
 			"service-account", "deployer", "registry", "registry-insecure",
 			"registry-authfile", "remote", "username", "password", "token", "verbose",
-			"remote-storage-class"),
+			"remote-storage-class", "no-cache", "quiet"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runDeploy(cmd, newClient)
 		},
@@ -207,6 +207,10 @@ EXAMPLES
 		"Push the function image to registry before deploying. ($FUNC_PUSH)")
 	cmd.Flags().String("platform", "",
 		"Optionally specify a specific platform to build for (e.g. linux/amd64). ($FUNC_PLATFORM)")
+	cmd.Flags().Bool("no-cache", false,
+		"Reset the function's build cache on the cluster before building. Only applicable with --remote and the tekton remote builder. ($FUNC_NO_CACHE)")
+	cmd.Flags().Bool("quiet", false,
+		"Print only the progress of remote builds instead of streaming their logs. ($FUNC_QUIET)")
 	cmd.Flags().StringP("username", "", "",
 		"Username to use when pushing to the registry. ($FUNC_USERNAME)")
 	cmd.Flags().StringP("password", "", "",
@@ -284,6 +288,11 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 		return
 	}
 
+	// Only the Tekton remote builder keeps a build cache which can be reset
+	if cfg.NoCache && f.Build.RemoteBuilder == fn.RemoteBuilderShipwright {
+		return fmt.Errorf("resetting the build cache (--no-cache) is not supported by the %v remote builder", f.Build.RemoteBuilder)
+	}
+
 	changingNamespace := func(f fn.Function) bool {
 		// We're changing namespace if:
 		return f.Deploy.Namespace != "" && // it's already deployed
@@ -541,6 +550,12 @@ type deployConfig struct {
 	// PVCSize configures the PVC size used by the pipeline if --remote flag is set.
 	PVCSize string
 
+	// NoCache resets the build cache of remote builds.
+	NoCache bool
+
+	// Quiet prints only the progress of remote builds, not their logs.
+	Quiet bool
+
 	// Timestamp the built container with the current date and time.
 	// This is currently only supported by the Pack builder.
 	Timestamp bool
@@ -561,6 +576,8 @@ func newDeployConfig(cmd *cobra.Command) deployConfig {
 		Remote:             viper.GetBool("remote"),
 		RemoteStorageClass: viper.GetString("remote-storage-class"),
 		PVCSize:            viper.GetString("pvc-size"),
+		NoCache:            viper.GetBool("no-cache"),
+		Quiet:              viper.GetBool("quiet"),
 		Timestamp:          viper.GetBool("build-timestamp"),
 		ServiceAccountName: viper.GetString("service-account"),
 		Deployer:           viper.GetString("deployer"),
@@ -756,6 +773,11 @@ func (c deployConfig) Validate(cmd *cobra.Command) (err error) {
 		return errors.New("git settings (--git-url --git-dir and --git-branch) are only applicable when triggering remote deployments (--remote)")
 	}
 
+	// The build cache is only reset for remote builds
+	if !c.Remote && c.NoCache {
+		return errors.New("resetting the build cache (--no-cache) is only applicable when triggering remote deployments (--remote)")
+	}
+
 	// Git URL can contain at maximum one '#'
 	urlParts := strings.Split(c.GitURL, "#")
 	if len(urlParts) > 2 {
@@ -780,26 +802,32 @@ func (c deployConfig) clientOptions() ([]fn.Option, error) {
 	t := newTransport(c.RegistryInsecure)
 	creds := newCredentialsProvider(config.Dir(), t, c.RegistryAuthfile)
 
-	// Override the pipelines provider to use custom credentials
-	// This is needed for remote builds (deploy --remote)
-	o = append(o, fn.WithPipelinesProvider(newTektonPipelinesProvider(creds, c.Verbose)))
-
 	// Add the appropriate deployer based on deploy type
 	deployer := c.Deployer
 	if deployer == "" {
 		deployer = knative.KnativeDeployerName // default to knative for backwards compatibility
 	}
 
+	var d fn.Deployer
 	switch deployer {
 	case knative.KnativeDeployerName:
-		o = append(o, fn.WithDeployer(newKnativeDeployer(c.Verbose)))
+		d = newKnativeDeployer(c.Verbose)
 	case k8s.KubernetesDeployerName:
-		o = append(o, fn.WithDeployer(newK8sDeployer(c.Verbose)))
+		d = newK8sDeployer(c.Verbose)
 	case keda.KedaDeployerName:
-		o = append(o, fn.WithDeployer(newKedaDeployer(c.Verbose)))
+		d = newKedaDeployer(c.Verbose)
 	default:
 		return o, fmt.Errorf("unsupported deploy type: %s (supported: %s, %s, %s)", deployer, knative.KnativeDeployerName, k8s.KubernetesDeployerName, keda.KedaDeployerName)
 	}
+	o = append(o, fn.WithDeployer(d))
+
+	// Override the pipelines provider to use custom credentials
+	// This is needed for remote builds (deploy --remote)
+	o = append(o, fn.WithPipelinesProvider(newPipelinesProvider(creds, d, remoteBuildConfig{
+		Verbose: c.Verbose,
+		Quiet:   c.Quiet,
+		NoCache: c.NoCache,
+	})))
 
 	return o, nil
 }
@@ -611,6 +611,47 @@ func TestDeploy_GitArgsUsed(t *testing.T) {
 	}
 }
 
+// TestDeploy_NoCacheRequiresRemote ensures that resetting the build cache is
+// only accepted for remote deployments with a remote builder which has one.
+func TestDeploy_NoCacheRequiresRemote(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewDeployCmd(NewTestClient(
+		fn.WithDeployer(mock.NewDeployer()),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithPipelinesProvider(mock.NewPipelinesProvider()),
+		fn.WithRegistry(TestRegistry),
+	))
+	cmd.SetArgs([]string{"--no-cache"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --no-cache without --remote to fail")
+	}
+
+	cmd.SetArgs([]string{"--remote", "--no-cache"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Shipwright remote builder keeps no build cache to reset
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Build.RemoteBuilder = fn.RemoteBuilderShipwright
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+	cmd.SetArgs([]string{"--remote", "--no-cache"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --no-cache with the shipwright remote builder to fail")
+	}
+}
+
 // TestDeploy_GitURLBranch ensures that a --git-url which specifies the branch
 // in the URL is equivalent to providing --git-branch
 func TestDeploy_GitURLBranch(t *testing.T) {
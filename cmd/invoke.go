@@ -1,19 +1,32 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/ory/viper"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 
 	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/utils"
 )
 
+// ErrInvocationsFailed is returned when replayed invocations did not meet
+// their expectations, such that the command exits non-zero.
+var ErrInvocationsFailed = errors.New("invocations failed")
+
 func NewInvokeCmd(newClient ClientFactory) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "invoke",
@@ -25,7 +38,11 @@ NAME
 SYNOPSIS
 	{{rootCmdUse}} invoke [-t|--target] [-f|--format]
 	             [--id] [--source] [--type] [--data] [--file] [--content-type]
+	             [--request-type] [--request-path] [--query] [--header] [--extension]
+	             [--ce-mode] [--subject] [--data-schema]
 	             [-s|--save] [-p|--path] [-i|--insecure] [-c|--confirm] [-v|--verbose]
+	             [--load] [--rps] [--duration] [--concurrency] [-o|--output]
+	             [--name] [--all]
 
 DESCRIPTION
 	Invokes the function by sending a test request to the currently running
@@ -60,12 +77,65 @@ DESCRIPTION
 	  would send a JPEG base64 encoded in the "data" POST parameter:
 	    {{rootCmdUse}} invoke --file=example.jpeg --content-type=image/jpeg
 
+	Request
+	  Any HTTP method can be used with --request-type, for example PUT, PATCH
+	  or DELETE.  Functions serving REST-style routes can be invoked at a path
+	  relative to their route using --request-path, with query parameters
+	  (--query), and additional headers (--header) provided repeatedly:
+	    {{rootCmdUse}} invoke --request-type=PATCH --request-path=/orders/42 \
+	      --query=dryRun=true --header="Authorization: Bearer $TOKEN"
+	  To see the response status code and headers, use --output json or yaml.
+
+	CloudEvents
+	  When using the "cloudevent" format, events are sent in binary content
+	  mode by default (attributes as HTTP headers).  To send the event as
+	  brokers may deliver it, use --ce-mode:
+	    structured  The event is sent as an application/cloudevents+json body.
+	    batch       Events are sent as an application/cloudevents-batch+json
+	                body.  Data which is a JSON array is sent as one event per
+	                element, each with an ID suffixed by its position.
+	  The optional subject and dataschema attributes are set using --subject
+	  and --data-schema, and extension attributes using --extension:
+	    {{rootCmdUse}} invoke -f=cloudevent --ce-mode=structured \
+	      --subject=orders/42 --extension=partitionkey=42
+	  An event returned by the function is validated, and the invocation
+	  fails if it is not a valid CloudEvent.
+
 	Message Format
 	  By default functions are sent messages which match the invocation format
 	  of the template they were created using; for example "http" or "cloudevent".
 	  To override this behavior, use the --format (-f) flag.
 	    {{rootCmdUse}} invoke -f=cloudevent -t=http://my-sink.my-cluster
 
+	Invocation Collections
+	  Named invocations can be recorded in the function's ` + fn.InvocationsFile + ` file,
+	  each defining its headers, CloudEvent attributes and data (or a file
+	  relative to the function root), along with expectations on the response
+	  status and body.  To record the current invocation under a name:
+	    {{rootCmdUse}} invoke --data='{"name":"alice"}' --save=alice
+	  Recorded invocations are replayed by name with --name, or all in order
+	  with --all, reporting whether each met its expectations.  This can be
+	  used as a smoke test after deployment:
+	    {{rootCmdUse}} invoke --target=remote --all
+	  For example, an invocation asserting its response:
+	    invocations:
+	    - name: alice
+	      data: '{"name":"alice"}'
+	      headers:
+	        X-Tenant: acme
+	      expect:
+	        status: 200
+	        contains: ["alice"]
+
+	Load Testing
+	  With --load the message is sent repeatedly for the given --duration by
+	  --concurrency concurrent workers at a combined rate of --rps requests
+	  per second (unlimited if zero).  Instead of the response, a report of
+	  latency percentiles, the error rate and a histogram of response status
+	  codes is printed.  This can be used to verify autoscaling settings such
+	  as the scale target and concurrency limits before rollout.
+	    {{rootCmdUse}} invoke --load --rps=50 --duration=1m --concurrency=20
+
 EXAMPLES
 
 	o Invoke the default (local or remote) running function with default values
@@ -101,12 +171,26 @@ EXAMPLES
 
 	o In case you need to specifically send GET request
 		$ {{rootCmdUse}} invoke --request-type=GET
+
+	o Delete a resource of a REST-style function and show the full response
+		$ {{rootCmdUse}} invoke --request-type=DELETE --request-path=/items/42 -o json
+
+	o Send a batch of two events to a CloudEvent function
+		$ {{rootCmdUse}} invoke -f=cloudevent --ce-mode=batch --data='[{"id":1},{"id":2}]'
+
+	o Replay all recorded invocations against the deployed function
+		$ {{rootCmdUse}} invoke --target=remote --all
+
+	o Load test the remote function for 30 seconds at 100 requests per second
+		$ {{rootCmdUse}} invoke --target=remote --load --rps=100 --duration=30s
 `,
 		SuggestFor: []string{"emit", "emti", "send", "emit", "exec", "nivoke",
 			"onvoke", "unvoke", "knvoke", "imvoke", "ihvoke", "ibvoke"},
 		PreRunE: bindEnv("path", "format", "target", "id", "source", "type",
-			"data", "content-type", "request-type", "file", "insecure",
-			"confirm", "verbose"),
+			"data", "content-type", "request-type", "request-path", "query", "header",
+			"extension", "file", "insecure", "ce-mode", "subject", "data-schema",
+			"confirm", "verbose", "load", "rps", "duration", "concurrency", "output",
+			"name", "all", "save"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runInvoke(cmd, args, newClient)
 		},
@@ -125,10 +209,25 @@ EXAMPLES
 	cmd.Flags().StringP("source", "", fn.DefaultInvokeSource, "Source value for the request data. ($FUNC_SOURCE)")
 	cmd.Flags().StringP("type", "", fn.DefaultInvokeType, "Type value for the request data. ($FUNC_TYPE)")
 	cmd.Flags().StringP("content-type", "", fn.DefaultInvokeContentType, "Content Type of the data. ($FUNC_CONTENT_TYPE)")
-	cmd.Flags().StringP("request-type", "", fn.DefaultInvokeRequestType, "Type of request to use. Can be POST or GET. ($FUNC_REQUEST_TYPE)")
+	cmd.Flags().StringP("request-type", "", fn.DefaultInvokeRequestType, "HTTP method of the request, such as GET, POST, PUT, PATCH or DELETE. ($FUNC_REQUEST_TYPE)")
+	cmd.Flags().String("request-path", "", "Path relative to the function's route to which the request is sent, such as /orders/42. ($FUNC_REQUEST_PATH)")
+	cmd.Flags().StringArray("query", []string{}, "Query parameter to add to the request in the form NAME=VALUE.  May be provided multiple times. ($FUNC_QUERY)")
+	cmd.Flags().StringArrayP("header", "H", []string{}, "HTTP header to add to the request in the form 'NAME: VALUE'.  May be provided multiple times. ($FUNC_HEADER)")
+	cmd.Flags().StringArray("extension", []string{}, "CloudEvent extension attribute in the form NAME=VALUE.  May be provided multiple times. ($FUNC_EXTENSION)")
+	cmd.Flags().String("ce-mode", fn.CloudEventModeBinary, fmt.Sprintf("CloudEvent content mode of the message when using the cloudevent format (%v). ($FUNC_CE_MODE)", strings.Join(fn.CloudEventModes, "|")))
+	cmd.Flags().String("subject", "", "CloudEvent subject attribute of the message. ($FUNC_SUBJECT)")
+	cmd.Flags().String("data-schema", "", "CloudEvent dataschema attribute (URI) of the message. ($FUNC_DATA_SCHEMA)")
 	cmd.Flags().StringP("data", "", fn.DefaultInvokeData, "Data to send in the request. ($FUNC_DATA)")
 	cmd.Flags().StringP("file", "", "", "Path to a file to use as data. Overrides --data flag and should be sent with a correct --content-type. ($FUNC_FILE)")
 	cmd.Flags().BoolP("insecure", "i", false, "Allow insecure server connections when using SSL. ($FUNC_INSECURE)")
+	cmd.Flags().Bool("load", false, "Load test the function by sending the message repeatedly and reporting statistics. ($FUNC_LOAD)")
+	cmd.Flags().Int("rps", 0, "Requests per second to send when load testing.  Zero is unlimited. ($FUNC_RPS)")
+	cmd.Flags().Duration("duration", fn.DefaultLoadDuration, "Duration of the load test. ($FUNC_DURATION)")
+	cmd.Flags().Int("concurrency", fn.DefaultLoadConcurrency, "Maximum number of concurrent requests when load testing. ($FUNC_CONCURRENCY)")
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|yaml).  Structured formats include the response status code and headers. ($FUNC_OUTPUT)")
+	cmd.Flags().String("name", "", fmt.Sprintf("Replay the named invocation recorded in %v. ($FUNC_NAME)", fn.InvocationsFile))
+	cmd.Flags().Bool("all", false, fmt.Sprintf("Replay all invocations recorded in %v. ($FUNC_ALL)", fn.InvocationsFile))
+	cmd.Flags().StringP("save", "s", "", fmt.Sprintf("Record the invocation under the given name in %v. ($FUNC_SAVE)", fn.InvocationsFile))
 	addConfirmFlag(cmd, cfg.Confirm)
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
@@ -139,7 +238,7 @@ EXAMPLES
 // Run
 func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err error) {
 	// Gather flag values for the invocation
-	cfg, err := newInvokeConfig()
+	cfg, err := newInvokeConfig(cmd)
 	if err != nil {
 		return
 	}
@@ -166,7 +265,8 @@ func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err err
 	}
 
 	// Client instance from env vars, flags, args and user prompts (if --confirm)
-	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.Insecure})
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.Insecure},
+		fn.WithInvokeOutput(cmd.OutOrStdout()))
 	defer done()
 
 	// Message to send the running function built from parameters gathered
@@ -179,6 +279,13 @@ func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err err
 		RequestType: strings.ToUpper(cfg.RequestType),
 		Data:        cfg.Data,
 		Format:      cfg.Format,
+		Path:        cfg.RequestPath,
+		Headers:     cfg.Headers,
+		Query:       cfg.Query,
+		Extensions:  cfg.Extensions,
+		Subject:     cfg.Subject,
+		DataSchema:  cfg.DataSchema,
+		Mode:        cfg.Mode,
 	}
 
 	// If --file was specified, use its content for message data
@@ -190,11 +297,57 @@ func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err err
 		m.Data = content
 	}
 
+	// Replay recorded invocations
+	if cfg.All || cfg.Name != "" {
+		var names []string
+		if !cfg.All {
+			names = []string{cfg.Name}
+		}
+		results, err := client.Replay(cmd.Context(), cfg.Path, cfg.Target, names...)
+		if err != nil {
+			return err
+		}
+		write(cmd.OutOrStdout(), invocationResults(results), cfg.Output)
+		for _, r := range results {
+			if !r.Passed {
+				return ErrInvocationsFailed
+			}
+		}
+		return nil
+	}
+
+	// Load test
+	if cfg.Load {
+		report, err := client.InvokeLoad(cmd.Context(), cfg.Path, cfg.Target, m, fn.LoadOptions{
+			RPS:         cfg.RPS,
+			Duration:    cfg.Duration,
+			Concurrency: cfg.Concurrency,
+		})
+		if err != nil {
+			return err
+		}
+		write(cmd.OutOrStdout(), loadReport(report), cfg.Output)
+		return nil
+	}
+
 	// Invoke
-	metadata, body, err := client.Invoke(cmd.Context(), cfg.Path, cfg.Target, m)
+	resp, err := client.InvokeWithResponse(cmd.Context(), cfg.Path, cfg.Target, m)
+
+	// Structured output includes the response status code and headers, and
+	// is written even if the response status indicates an error.
+	if cfg.Output == string(JSON) || cfg.Output == string(YAML) {
+		if resp.StatusCode != 0 {
+			write(cmd.OutOrStdout(), invokeResponse(resp), cfg.Output)
+		}
+		if err != nil {
+			return err
+		}
+		return cfg.save(cmd, f, m)
+	}
 	if err != nil {
 		return err
 	}
+	metadata, body := resp.Headers, resp.Body
 
 	// When Verbose
 	// - Print an explicit "Received response" indicator
@@ -205,24 +358,42 @@ func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err err
 		// stdout could be confusing on a first-time run, viewing a proper echo.
 		// user feedback suggests this actually be placed behind the --verbose
 		// setting:
-		fmt.Println("Function invoked.  Response:")
+		fmt.Fprintln(cmd.OutOrStdout(), "Function invoked.  Response:")
 
 		if len(metadata) > 0 {
-			fmt.Println("  Metadata:")
+			fmt.Fprintln(cmd.OutOrStdout(), "  Metadata:")
 		}
 		for k, vv := range metadata {
 			values := strings.Join(vv, ";")
 			fmt.Fprintf(cmd.OutOrStdout(), "    %v: %v\n", k, values)
 		}
 		if len(metadata) > 0 {
-			fmt.Println("  Content:")
+			fmt.Fprintln(cmd.OutOrStdout(), "  Content:")
 		}
 	}
 
 	// Always print the response's default stringification
 	// Note body already includes a linebreak.
 	fmt.Fprint(cmd.OutOrStdout(), body)
-	return
+
+	return cfg.save(cmd, f, m)
+}
+
+// save the invocation message to the function's collection if requested.
+func (c invokeConfig) save(cmd *cobra.Command, f fn.Function, m fn.InvokeMessage) error {
+	if c.Save == "" {
+		return nil
+	}
+	ii, err := fn.LoadInvocations(f.Root)
+	if err != nil {
+		return err
+	}
+	ii.Set(fn.NewInvocation(c.Save, m))
+	if err = ii.Write(f.Root); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Invocation %q saved to %v\n", c.Save, fn.InvocationsFile)
+	return nil
 }
 
 type invokeConfig struct {
@@ -239,9 +410,24 @@ type invokeConfig struct {
 	Confirm     bool
 	Verbose     bool
 	Insecure    bool
+	Load        bool
+	RPS         int
+	Duration    time.Duration
+	Concurrency int
+	Output      string
+	Name        string
+	All         bool
+	Save        string
+	RequestPath string
+	Headers     http.Header
+	Query       url.Values
+	Extensions  map[string]string
+	Mode        string
+	Subject     string
+	DataSchema  string
 }
 
-func newInvokeConfig() (cfg invokeConfig, err error) {
+func newInvokeConfig(cmd *cobra.Command) (cfg invokeConfig, err error) {
 	cfg = invokeConfig{
 		Path:        viper.GetString("path"),
 		Target:      viper.GetString("target"),
@@ -256,6 +442,59 @@ func newInvokeConfig() (cfg invokeConfig, err error) {
 		Confirm:     viper.GetBool("confirm"),
 		Verbose:     viper.GetBool("verbose"),
 		Insecure:    viper.GetBool("insecure"),
+		Load:        viper.GetBool("load"),
+		RPS:         viper.GetInt("rps"),
+		Duration:    viper.GetDuration("duration"),
+		Concurrency: viper.GetInt("concurrency"),
+		Output:      viper.GetString("output"),
+		Name:        viper.GetString("name"),
+		All:         viper.GetBool("all"),
+		Save:        viper.GetString("save"),
+		RequestPath: viper.GetString("request-path"),
+		Mode:        viper.GetString("ce-mode"),
+		Subject:     viper.GetString("subject"),
+		DataSchema:  viper.GetString("data-schema"),
+	}
+
+	headers := stringArrayFlag(cmd, "header")
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return cfg, fmt.Errorf("invalid header %q, expected 'NAME: VALUE'", h)
+		}
+		if cfg.Headers == nil {
+			cfg.Headers = http.Header{}
+		}
+		cfg.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	query := stringArrayFlag(cmd, "query")
+	for _, q := range query {
+		name, value, ok := strings.Cut(q, "=")
+		if !ok || name == "" {
+			return cfg, fmt.Errorf("invalid query parameter %q, expected NAME=VALUE", q)
+		}
+		if cfg.Query == nil {
+			cfg.Query = url.Values{}
+		}
+		cfg.Query.Add(name, value)
+	}
+	extensions := stringArrayFlag(cmd, "extension")
+	for _, e := range extensions {
+		name, value, ok := strings.Cut(e, "=")
+		if !ok || name == "" {
+			return cfg, fmt.Errorf("invalid extension attribute %q, expected NAME=VALUE", e)
+		}
+		if cfg.Extensions == nil {
+			cfg.Extensions = map[string]string{}
+		}
+		cfg.Extensions[name] = value
+	}
+
+	if cfg.Name != "" && cfg.All {
+		return cfg, errors.New("only one of --name and --all may be provided")
+	}
+	if (cfg.Name != "" || cfg.All) && (cfg.Load || cfg.Save != "") {
+		return cfg, errors.New("recorded invocations can not be replayed with --load or --save")
 	}
 
 	// If file was passed, read it in as data
@@ -296,6 +535,21 @@ func newInvokeConfig() (cfg invokeConfig, err error) {
 	return
 }
 
+// stringArrayFlag returns the values of an array flag, or the lines of its
+// environment variable when the flag is not provided.  Array flags are not
+// read with viper, which returns unparsed results for them (see
+// https://github.com/spf13/viper/issues/380)
+func stringArrayFlag(cmd *cobra.Command, name string) []string {
+	if !cmd.Flags().Changed(name) {
+		env := "FUNC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			return strings.Split(v, "\n")
+		}
+	}
+	vv, _ := cmd.Flags().GetStringArray(name)
+	return vv
+}
+
 func (c invokeConfig) prompt() (invokeConfig, error) {
 	var qs []*survey.Question
 
@@ -432,3 +686,151 @@ func (c invokeConfig) prompt() (invokeConfig, error) {
 
 	return c, nil
 }
+
+// Output Formatting (serializers)
+// -------------------------------
+
+type loadReport fn.LoadReport
+
+func (r loadReport) Human(w io.Writer) error {
+	fmt.Fprintf(w, "Load test of %s (%s) completed in %s\n", r.Target, r.Format, r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "  Requests:    %d (%.2f/s)\n", r.Requests, r.RPS)
+	fmt.Fprintf(w, "  Errors:      %d (%.2f%%)\n", r.Errors, r.ErrorRate*100)
+
+	fmt.Fprintln(w, "  Latency:")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "    min\tmean\tp50\tp90\tp95\tp99\tmax\n")
+	l := r.Latency
+	fmt.Fprintf(tw, "    %s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		round(l.Min), round(l.Mean), round(l.P50), round(l.P90), round(l.P95), round(l.P99), round(l.Max))
+	tw.Flush()
+
+	if len(r.StatusCodes) > 0 {
+		fmt.Fprintln(w, "  Status codes:")
+		codes := make([]int, 0, len(r.StatusCodes))
+		for code := range r.StatusCodes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "    %d: %d\n", code, r.StatusCodes[code])
+		}
+	}
+	if len(r.ErrorMessages) > 0 {
+		fmt.Fprintln(w, "  Error messages:")
+		msgs := make([]string, 0, len(r.ErrorMessages))
+		for msg := range r.ErrorMessages {
+			msgs = append(msgs, msg)
+		}
+		// most frequent first
+		sort.Slice(msgs, func(i, j int) bool {
+			if r.ErrorMessages[msgs[i]] != r.ErrorMessages[msgs[j]] {
+				return r.ErrorMessages[msgs[i]] > r.ErrorMessages[msgs[j]]
+			}
+			return msgs[i] < msgs[j]
+		})
+		for _, msg := range msgs {
+			fmt.Fprintf(w, "    %d: %s\n", r.ErrorMessages[msg], msg)
+		}
+	}
+	return nil
+}
+
+func (r loadReport) Plain(w io.Writer) error {
+	return r.Human(w)
+}
+
+func (r loadReport) JSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+func (r loadReport) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(r)
+}
+
+func (r loadReport) URL(w io.Writer) error {
+	fmt.Fprintln(w, r.Target)
+	return nil
+}
+
+type invokeResponse fn.InvokeResponse
+
+func (r invokeResponse) Human(w io.Writer) error {
+	fmt.Fprintf(w, "HTTP %d\n", r.StatusCode)
+	names := make([]string, 0, len(r.Headers))
+	for name := range r.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s: %s\n", name, strings.Join(r.Headers[name], ", "))
+	}
+	fmt.Fprintf(w, "\n%s", r.Body)
+	return nil
+}
+
+func (r invokeResponse) Plain(w io.Writer) error {
+	return r.Human(w)
+}
+
+func (r invokeResponse) JSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+func (r invokeResponse) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(r)
+}
+
+func (r invokeResponse) URL(w io.Writer) error {
+	return r.Human(w)
+}
+
+type invocationResults []fn.InvocationResult
+
+func (rr invocationResults) Human(w io.Writer) error {
+	passed := 0
+	for _, r := range rr {
+		status := "PASS"
+		if r.Passed {
+			passed++
+		} else {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s  %s (HTTP %d, %s)\n", status, r.Name, r.Status, r.Duration.Round(time.Millisecond))
+		for _, f := range r.Failures {
+			fmt.Fprintf(w, "      %s\n", f)
+		}
+	}
+	fmt.Fprintf(w, "%d of %d invocations passed\n", passed, len(rr))
+	return nil
+}
+
+func (rr invocationResults) Plain(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", "NAME", "PASSED", "STATUS", "DURATION")
+	for _, r := range rr {
+		fmt.Fprintf(tw, "%s\t%t\t%d\t%s\n", r.Name, r.Passed, r.Status, r.Duration)
+	}
+	return nil
+}
+
+func (rr invocationResults) JSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(rr)
+}
+
+func (rr invocationResults) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(rr)
+}
+
+func (rr invocationResults) URL(w io.Writer) error {
+	return rr.Plain(w)
+}
+
+// round a latency for display.
+func round(d time.Duration) time.Duration {
+	if d > time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(10 * time.Microsecond)
+}
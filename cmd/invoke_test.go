@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -78,3 +83,226 @@ func TestInvoke(t *testing.T) {
 		t.Fatal("function was not invoked")
 	}
 }
+
+// TestInvoke_Load ensures that the --load flag repeatedly invokes the target
+// and reports the results.
+func TestInvoke_Load(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	var invocations int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&invocations, 1)
+	}))
+	t.Cleanup(server.Close)
+
+	cmd := NewInvokeCmd(NewTestClient())
+	cmd.SetArgs([]string{"--target", server.URL, "--load", "--duration", "100ms",
+		"--concurrency", "2", "--output", "json"})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var report fn.LoadReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("output is not a JSON load report: %v\n%s", err, out.String())
+	}
+	if report.Requests == 0 || report.Requests != int(atomic.LoadInt32(&invocations)) {
+		t.Fatalf("expected %v requests reported, got %v", invocations, report.Requests)
+	}
+	if report.StatusCodes[200] != report.Requests || report.Errors != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+// TestInvoke_LoadReportErrorMessages ensures error messages of a load report
+// are printed most frequent first, ties ordered by message.
+func TestInvoke_LoadReportErrorMessages(t *testing.T) {
+	report := loadReport{
+		Errors: 6,
+		ErrorMessages: map[string]int{
+			"connection reset":   1,
+			"timeout":            3,
+			"connection refused": 1,
+			"EOF":                1,
+		},
+	}
+	out := &bytes.Buffer{}
+	if err := report.Human(out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `  Error messages:
+    3: timeout
+    1: EOF
+    1: connection refused
+    1: connection reset
+`
+	if !strings.HasSuffix(out.String(), expected) {
+		t.Fatalf("expected error messages\n%s\ngot\n%s", expected, out.String())
+	}
+}
+
+// TestInvoke_Request ensures the request method, path, query parameters and
+// headers are sent as provided, and that structured output includes the
+// response status code and headers.
+func TestInvoke_Request(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		received = req
+		res.Header().Set("X-Result", "deleted")
+		res.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(res, "OK")
+	}))
+	t.Cleanup(server.Close)
+
+	cmd := NewInvokeCmd(NewTestClient())
+	cmd.SetArgs([]string{"--target", server.URL, "--request-type", "delete",
+		"--request-path", "/items/42", "--query", "dryRun=true",
+		"--header", "Authorization: Bearer token", "--output", "json"})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if received.Method != http.MethodDelete {
+		t.Fatalf("expected method DELETE, got %v", received.Method)
+	}
+	if received.URL.Path != "/items/42" {
+		t.Fatalf("expected path /items/42, got %v", received.URL.Path)
+	}
+	if received.URL.Query().Get("dryRun") != "true" {
+		t.Fatalf("expected query dryRun=true, got %v", received.URL.RawQuery)
+	}
+	if received.Header.Get("Authorization") != "Bearer token" {
+		t.Fatalf("expected Authorization header, got %v", received.Header)
+	}
+
+	var resp fn.InvokeResponse
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		t.Fatalf("output is not a JSON response: %v\n%s", err, out.String())
+	}
+	if resp.StatusCode != http.StatusAccepted || resp.Body != "OK" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if len(resp.Headers["X-Result"]) != 1 || resp.Headers["X-Result"][0] != "deleted" {
+		t.Fatalf("expected response header X-Result, got %v", resp.Headers)
+	}
+}
+
+// TestInvoke_RequestHeadersFromEnv ensures headers may be provided in the
+// environment, one per line, and that a Content-Type header takes precedence
+// over --content-type.
+func TestInvoke_RequestHeadersFromEnv(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		received = req
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("FUNC_HEADER", "Content-Type: application/merge-patch+json\nX-Tenant: acme")
+	cmd := NewInvokeCmd(NewTestClient())
+	cmd.SetArgs([]string{"--target", server.URL, "--request-type", "patch",
+		"--content-type", "application/json"})
+	cmd.SetOut(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ct := received.Header.Values("Content-Type"); len(ct) != 1 || ct[0] != "application/merge-patch+json" {
+		t.Fatalf("expected Content-Type from header, got %v", ct)
+	}
+	if received.Header.Get("X-Tenant") != "acme" {
+		t.Fatalf("expected X-Tenant header, got %v", received.Header)
+	}
+}
+
+// TestInvoke_InvalidHeader ensures malformed headers are rejected.
+func TestInvoke_InvalidHeader(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewInvokeCmd(NewTestClient())
+	cmd.SetArgs([]string{"--target", "http://localhost:1", "--header", "no-separator"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for an invalid header")
+	}
+}
+
+// TestInvoke_SaveAndReplay ensures that an invocation can be recorded with
+// --save and later replayed by name or with --all.
+func TestInvoke_SaveAndReplay(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		_, _ = res.Write(b)
+	}))
+	t.Cleanup(server.Close)
+
+	// Record
+	cmd := NewInvokeCmd(NewTestClient())
+	cmd.SetArgs([]string{"--target", server.URL, "--data", "hello", "--save", "greeting"})
+	cmd.SetOut(&bytes.Buffer{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	ii, err := fn.LoadInvocations(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv, err := ii.Get("greeting")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add an expectation which is met and one which is not
+	inv.Expect.Body = "hello"
+	ii.Set(inv)
+	ii.Set(fn.Invocation{Name: "unmet", Expect: fn.InvocationExpectation{Status: 404}})
+	if err = ii.Write(root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replay by name
+	cmd = NewInvokeCmd(NewTestClient())
+	cmd.SetArgs([]string{"--target", server.URL, "--name", "greeting", "--output", "json"})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	var results []fn.InvocationResult
+	if err = json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("output is not JSON results: %v\n%s", err, out.String())
+	}
+	if len(results) != 1 || !results[0].Passed {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	// Replay all
+	cmd = NewInvokeCmd(NewTestClient())
+	cmd.SetArgs([]string{"--target", server.URL, "--all"})
+	cmd.SetOut(&bytes.Buffer{})
+	if err = cmd.Execute(); !errors.Is(err, ErrInvocationsFailed) {
+		t.Fatalf("expected ErrInvocationsFailed, got %v", err)
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/gateway"
 )
 
 func NewListCmd(newClient ClientFactory) *cobra.Command {
@@ -22,7 +23,8 @@ func NewListCmd(newClient ClientFactory) *cobra.Command {
 		Short: "List deployed functions",
 		Long: `List deployed functions
 
-Lists deployed functions.
+Lists deployed functions. With --local, lists the functions running locally
+instead, with their routes on the local gateway of 'func run'.
 `,
 		Example: `
 # List all functions in the current namespace with human readable output
@@ -33,10 +35,13 @@ Lists deployed functions.
 
 # List all functions in all namespaces with JSON output
 {{rootCmdUse}} list --all-namespaces --output json
+
+# List all functions running locally with their routes
+{{rootCmdUse}} list --local
 `,
 		SuggestFor: []string{"lsit"},
 		Aliases:    []string{"ls"},
-		PreRunE:    bindEnv("all-namespaces", "output", "namespace", "verbose"),
+		PreRunE:    bindEnv("all-namespaces", "local", "output", "namespace", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runList(cmd, args, newClient)
 		},
@@ -68,6 +73,7 @@ Lists deployed functions.
 	// Flags
 	cmd.Flags().BoolP("all-namespaces", "A", false, "List functions in all namespaces. If set, the --namespace flag is ignored.")
 	cmd.Flags().StringP("namespace", "n", defaultNamespace(fn.Function{}, false), "The namespace for which to list functions. ($FUNC_NAMESPACE)")
+	cmd.Flags().Bool("local", false, "List the functions running locally rather than deployed. ($FUNC_LOCAL)")
 	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|xml|yaml) ($FUNC_OUTPUT)")
 	addVerboseFlag(cmd, cfg.Verbose)
 
@@ -84,6 +90,10 @@ func runList(cmd *cobra.Command, _ []string, newClient ClientFactory) (err error
 		return err
 	}
 
+	if cfg.Local {
+		return runListLocal(cfg)
+	}
+
 	client, done := newClient(ClientConfig{Verbose: cfg.Verbose})
 	defer done()
 
@@ -124,10 +134,28 @@ To see functions here:
 	return
 }
 
+// runListLocal lists the functions running locally, as registered by
+// `func run`, removing the registrations of those which stopped.
+func runListLocal(cfg listConfig) error {
+	instances, err := gateway.NewRegistry(config.InstancesPath()).Running()
+	if err != nil {
+		return err
+	}
+	if len(instances) == 0 && Format(cfg.Output) == Human {
+		fmt.Println(`no functions running locally
+
+'func list --local' shows functions started with 'func run'.`)
+		return nil
+	}
+	write(os.Stdout, localItems(instances), cfg.Output)
+	return nil
+}
+
 // CLI Configuration (parameters)
 // ------------------------------
 
 type listConfig struct {
+	Local     bool
 	Namespace string
 	Output    string
 	Verbose   bool
@@ -135,6 +163,7 @@ type listConfig struct {
 
 func newListConfig(cmd *cobra.Command) (cfg listConfig, err error) {
 	cfg = listConfig{
+		Local:     viper.GetBool("local"),
 		Namespace: viper.GetString("namespace"),
 		Output:    viper.GetString("output"),
 		Verbose:   viper.GetBool("verbose"),
@@ -145,6 +174,12 @@ func newListConfig(cmd *cobra.Command) (cfg listConfig, err error) {
 		cfg.Namespace = ""
 	}
 
+	// local instances are not namespaced
+	if cfg.Local && (cmd.Flags().Changed("namespace") || viper.GetBool("all-namespaces")) {
+		err = errors.New("--local cannot be combined with --namespace or --all-namespaces")
+		return
+	}
+
 	// specifying both -A and --namespace is logically inconsistent
 	if cmd.Flags().Changed("namespace") && viper.GetBool("all-namespaces") {
 		err = errors.New("both --namespace and --all-namespaces specified")
@@ -189,3 +224,37 @@ func (items listItems) URL(w io.Writer) error {
 	}
 	return nil
 }
+
+type localItems []gateway.Instance
+
+func (items localItems) Human(w io.Writer) error {
+	return items.Plain(w)
+}
+
+func (items localItems) Plain(w io.Writer) error {
+
+	// minwidth, tabwidth, padding, padchar, flags
+	tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tabWriter.Flush()
+
+	fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", "NAME", "RUNTIME", "ADDRESS", "ROUTE", "PATH")
+	for _, item := range items {
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", item.Name, item.Runtime, item.Address, item.Route, item.Root)
+	}
+	return nil
+}
+
+func (items localItems) JSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(items)
+}
+
+func (items localItems) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(items)
+}
+
+func (items localItems) URL(w io.Writer) error {
+	for _, item := range items {
+		fmt.Fprintf(w, "%s\n", item.Route)
+	}
+	return nil
+}
@@ -107,3 +107,25 @@ func TestList_Namespace(t *testing.T) {
 		})
 	}
 }
+
+// TestList_Local ensures --local lists the functions running locally without
+// connecting to the cluster, and is not combined with namespace options.
+func TestList_Local(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	lister := mock.NewLister()
+	cmd := NewListCmd(NewTestClient(fn.WithListers(lister)))
+	cmd.SetArgs([]string{"--local", "--output", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if lister.ListInvoked {
+		t.Fatal("the lister was invoked for local functions")
+	}
+
+	cmd = NewListCmd(NewTestClient(fn.WithListers(lister)))
+	cmd.SetArgs([]string{"--local", "--all-namespaces"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --local with --all-namespaces to error")
+	}
+}
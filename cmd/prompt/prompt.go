@@ -2,6 +2,7 @@ package prompt
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"golang.org/x/term"
 
 	"knative.dev/func/pkg/creds"
+	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/oci"
 )
 
@@ -131,3 +133,79 @@ you can install docker credential helper https://github.com/docker/docker-creden
 		return resp, nil
 	}
 }
+
+// NewPromptForTemplateParameters returns a prompt for the values of the given
+// template parameters which were not already provided.  Parameters left empty
+// take their default.  Returned are the provided and prompted values.
+func NewPromptForTemplateParameters(in io.Reader, out, errOut io.Writer) func(params []fn.TemplateParameter, provided map[string]string) (map[string]string, error) {
+	return func(params []fn.TemplateParameter, provided map[string]string) (map[string]string, error) {
+		values := make(map[string]string, len(provided))
+		for k, v := range provided {
+			values[k] = v
+		}
+
+		var (
+			fr     terminal.FileReader
+			ok     bool
+			isTerm bool
+		)
+		if fr, ok = in.(terminal.FileReader); ok {
+			isTerm = term.IsTerminal(int(fr.Fd()))
+		}
+		reader := bufio.NewReader(in)
+
+		for _, p := range params {
+			if _, ok := values[p.Name]; ok {
+				continue
+			}
+			var value string
+			if isTerm {
+				var prompt survey.Prompt
+				switch {
+				case len(p.Options) > 0:
+					prompt = &survey.Select{Message: p.Message() + ":", Options: p.Options, Default: p.Default}
+				case p.Type == fn.ParameterTypeBool:
+					prompt = &survey.Input{Message: p.Message() + " (true/false):", Default: p.Default}
+				default:
+					prompt = &survey.Input{Message: p.Message() + ":", Default: p.Default}
+				}
+				validate := func(ans interface{}) error {
+					v, _ := ans.(string)
+					if v == "" {
+						if p.Required {
+							return fmt.Errorf("%v is required", p.Name)
+						}
+						return nil
+					}
+					return p.Validate(v)
+				}
+				if err := survey.AskOne(prompt, &value, survey.WithValidator(validate),
+					survey.WithStdio(fr, out.(terminal.FileWriter), errOut)); err != nil {
+					return nil, err
+				}
+			} else {
+				if p.Default != "" {
+					fmt.Fprintf(out, "%v (%v): ", p.Message(), p.Default)
+				} else {
+					fmt.Fprintf(out, "%v: ", p.Message())
+				}
+				line, err := reader.ReadString('\n')
+				if err != nil && !errors.Is(err, io.EOF) { // end of input takes defaults
+					return nil, err
+				}
+				if value = strings.Trim(line, "\r\n"); value != "" {
+					if err = p.Validate(value); err != nil {
+						return nil, err
+					}
+				}
+			}
+			if value == "" {
+				value = p.Default
+			}
+			if value != "" {
+				values[p.Name] = value
+			}
+		}
+		return values, nil
+	}
+}
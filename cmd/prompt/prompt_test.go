@@ -11,7 +11,9 @@ import (
 
 	"github.com/Netflix/go-expect"
 	"github.com/creack/pty"
+	"github.com/google/go-cmp/cmp"
 	"github.com/hinshun/vt10x"
+	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/oci"
 )
 
@@ -80,3 +82,28 @@ func Test_NewPromptForCredentials(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewPromptForTemplateParameters(t *testing.T) {
+	params := []fn.TemplateParameter{
+		{Name: "module", Required: true},
+		{Name: "greeting", Default: "Hello"},
+		{Name: "db", Options: []string{"postgres", "mysql"}},
+		{Name: "docs", Type: fn.ParameterTypeBool},
+	}
+	provided := map[string]string{"db": "mysql"}
+
+	paramsPrompt := NewPromptForTemplateParameters(strings.NewReader("example.com/f\r\n\r\ntrue\r\n"), io.Discard, io.Discard)
+	values, err := paramsPrompt(params, provided)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"module": "example.com/f", "greeting": "Hello", "db": "mysql", "docs": "true"}
+	if diff := cmp.Diff(expected, values); diff != "" {
+		t.Errorf("unexpected values (-want, +got): %v", diff)
+	}
+
+	paramsPrompt = NewPromptForTemplateParameters(strings.NewReader("x\r\n\r\nmaybe\r\n"), io.Discard, io.Discard)
+	if _, err = paramsPrompt(params, provided); err == nil {
+		t.Error("expected an error for an invalid bool")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/ory/viper"
@@ -30,6 +31,7 @@ SYNOPSIS
 	{{rootCmdUse}} repo list [-r|--repositories] [-c|--confirm] [-v|--verbose]
 	{{rootCmdUse}} repo add <name> <url>[-r|--repositories] [-c|--confirm] [-v|--verbose]
 	{{rootCmdUse}} repo rename <old> <new> [-r|--repositories] [-c|--confirm] [-v|--verbose]
+	{{rootCmdUse}} repo update [name] [--ref <ref>] [-c|--confirm] [-v|--verbose]
 	{{rootCmdUse}} repo remove <name> [-r|--repositories] [-c|--confirm] [-v|--verbose]
 
 DESCRIPTION
@@ -61,6 +63,22 @@ DESCRIPTION
 			--template hello-world \
 			--repository https://github.com/boson-project/templates
 
+	Versions:
+	A repository can be pinned to a branch, a tag or a commit by appending it
+	to the URL as a fragment when it is added.  Without one, the repository's
+	default branch is assumed.  Functions record the version of the template
+	from which they were created in func.lock, such that the changes to the
+	template since can be shown with '{{rootCmdUse}} templates diff'.
+
+	OCI Artifacts:
+	Repositories can also be distributed through a container registry as an
+	OCI artifact, added with a URL of the form oci://<registry>/<repository>
+	followed by a :tag or @digest.  The artifact's layers may be zip archives,
+	such as written by '{{rootCmdUse}} templates publish', or tar archives of
+	the repository.  Tags are resolved to the artifact's digest, to which the
+	installed repository is pinned until updated.  Credentials are those used
+	for pushing functions to the registry.
+
 	Alternative Repositories Location:
 	Repositories are stored on disk in ~/.config/func/repositories by default.
 	This location can be altered by setting the FUNC_REPOSITORIES_PATH
@@ -95,6 +113,14 @@ COMMANDS
 	  repositories can be renamed.
 	    $ {{rootCmdUse}} repository rename <name> <new name>
 
+	update
+	  Update installed repositories, or the named repository, to the latest
+	  version of what they are pinned to.  Repositories pinned to a branch are
+	  updated to its latest commit, repositories pinned to a semver tag to the
+	  highest released version.  Repositories pinned to a commit are unchanged.
+	  To pin the repository to another branch, tag or commit, use --ref.
+	    $ {{rootCmdUse}} repository update [name] [--ref <ref>]
+
 	remove
 	  Remove a repository by name.  Removes the repository from local storage
 	  entirely.  When in confirm mode (--confirm) it will confirm before
@@ -137,6 +163,17 @@ EXAMPLES
 	  default
 	  functastic
 
+	o Add a repository pinned to a tag and later update it to the latest release
+	  $ {{rootCmdUse}} repository add functastic https://github.com/knative-extensions/func-tastic#v1.0.0
+	  $ {{rootCmdUse}} repository update functastic
+	  Updated functastic from v1.0.0 (1a2b3c4) to v1.1.0 (5d6e7f8)
+
+	o Pin an installed repository to a specific commit
+	  $ {{rootCmdUse}} repository update functastic --ref 1a2b3c4
+
+	o Add a repository distributed as an OCI artifact
+	  $ {{rootCmdUse}} repository add templates oci://registry.example.com/org/templates:v1.0.0
+
 	o Remove an installed repository
 	  $ {{rootCmdUse}} repository list
 	  default
@@ -162,6 +199,7 @@ EXAMPLES
 	cmd.AddCommand(NewRepositoryListCmd(newClient))
 	cmd.AddCommand(NewRepositoryAddCmd(newClient))
 	cmd.AddCommand(NewRepositoryRenameCmd(newClient))
+	cmd.AddCommand(NewRepositoryUpdateCmd(newClient))
 	cmd.AddCommand(NewRepositoryRemoveCmd(newClient))
 
 	return cmd
@@ -230,6 +268,29 @@ func NewRepositoryRenameCmd(newClient ClientFactory) *cobra.Command {
 	return cmd
 }
 
+func NewRepositoryUpdateCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Short:      "Update repositories",
+		Use:        "update [name]",
+		Aliases:    []string{"up"},
+		SuggestFor: []string{"upgrade", "pull"},
+		PreRunE:    bindEnv("confirm", "verbose", "ref"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRepositoryUpdate(cmd, args, newClient)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+	cmd.Flags().String("ref", "", "Branch, tag or commit to which to pin the repository ($FUNC_REF)")
+	addConfirmFlag(cmd, cfg.Confirm)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
 func NewRepositoryRemoveCmd(newClient ClientFactory) *cobra.Command {
 	cmd := &cobra.Command{
 		Short:      "Remove a repository",
@@ -274,7 +335,7 @@ func runRepository(cmd *cobra.Command, args []string, newClient ClientFactory) (
 		Name: "Action",
 		Prompt: &survey.Select{
 			Message: "Operation to perform:",
-			Options: []string{"list", "add", "rename", "remove"},
+			Options: []string{"list", "add", "rename", "update", "remove"},
 			Default: "list",
 		}}
 	answer := struct{ Action string }{}
@@ -290,6 +351,8 @@ func runRepository(cmd *cobra.Command, args []string, newClient ClientFactory) (
 		return runRepositoryAdd(cmd, args, newClient)
 	case "rename":
 		return runRepositoryRename(cmd, args, newClient)
+	case "update":
+		return runRepositoryUpdate(cmd, args, newClient)
 	case "remove":
 		return runRepositoryRemove(cmd, args, newClient)
 	}
@@ -325,7 +388,7 @@ func runRepositoryList(_ *cobra.Command, newClient ClientFactory) (err error) {
 }
 
 // Add
-func runRepositoryAdd(_ *cobra.Command, args []string, newClient ClientFactory) (err error) {
+func runRepositoryAdd(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
 	// Supports both composable, discrete CLI commands or prompt-based "config"
 	// by setting the argument values (name and ulr) to value of positional args,
 	// but only requires them if not prompting.  If prompting, those values
@@ -412,7 +475,7 @@ func runRepositoryAdd(_ *cobra.Command, args []string, newClient ClientFactory)
 
 	// Add repository
 	var n string
-	if n, err = client.Repositories().Add(params.Name, params.URL); err != nil {
+	if n, err = client.Repositories().Add(cmd.Context(), params.Name, params.URL); err != nil {
 		return
 	}
 	if cfg.Verbose {
@@ -491,6 +554,105 @@ func runRepositoryRename(_ *cobra.Command, args []string, newClient ClientFactor
 	return
 }
 
+// Update
+func runRepositoryUpdate(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
+	cfg, err := newRepositoryConfig()
+	if err != nil {
+		return
+	}
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose})
+	defer done()
+
+	// Preconditions
+	if len(args) > 1 {
+		return fmt.Errorf("usage: func repository update [name] [--ref <ref>]")
+	}
+
+	// Extract Params
+	params := struct {
+		Name string
+		Ref  string
+	}{
+		Ref: viper.GetString("ref"),
+	}
+	if len(args) > 0 {
+		params.Name = args[0]
+	}
+
+	// Repositories installed according to the client
+	// (does not include the builtin default)
+	repositories, err := installedRepositories(client)
+	if err != nil {
+		return
+	}
+	if len(repositories) == 0 {
+		return errors.New("no repositories installed. use 'add' to install")
+	}
+
+	// Confirm (interactive prompt mode)
+	if cfg.Confirm && interactiveTerminal() {
+		questions := []*survey.Question{
+			{
+				Name:     "Name",
+				Validate: survey.Required,
+				Prompt: &survey.Select{
+					Message: "Repository to update:",
+					Options: repositories,
+				},
+			}, {
+				Name: "Ref",
+				Prompt: &survey.Input{
+					Message: "Branch, tag or commit (optional):",
+					Default: params.Ref,
+				},
+			},
+		}
+		if err = survey.Ask(questions, &params); err != nil {
+			return // for any reason, including interrupt, is a nonzero exit
+		}
+	} else if cfg.Confirm {
+		fmt.Fprintf(os.Stdout, "Repository: %v\n", params.Name)
+		fmt.Fprintf(os.Stdout, "Ref:        %v\n", params.Ref)
+	}
+
+	// Without a name, all installed repositories are updated
+	names := repositories
+	if params.Name != "" {
+		names = []string{params.Name}
+	} else if params.Ref != "" {
+		return errors.New("a repository name is required to pin a repository with --ref")
+	}
+
+	for _, name := range names {
+		old, _ := client.Repositories().Get(name) // for reporting only
+		updated, err := client.Repositories().Update(cmd.Context(), name, params.Ref)
+		if err != nil {
+			return fmt.Errorf("cannot update repository '%v': %w", name, err)
+		}
+		if updated.Commit() == old.Commit() && updated.Ref() == old.Ref() {
+			fmt.Fprintf(os.Stdout, "%v is up to date at %v\n", name, repositoryVersion(updated))
+		} else {
+			fmt.Fprintf(os.Stdout, "Updated %v from %v to %v\n", name, repositoryVersion(old), repositoryVersion(updated))
+		}
+	}
+	return
+}
+
+// repositoryVersion returns a short description of the version of a
+// repository, for example "main (1a2b3c4)".
+func repositoryVersion(r fn.Repository) string {
+	commit := r.Commit()
+	if algorithm, hex, ok := strings.Cut(commit, ":"); ok && len(hex) > 12 {
+		commit = algorithm + ":" + hex[:12] // digest of an OCI artifact
+	} else if !ok && len(commit) > 7 {
+		commit = commit[:7]
+	}
+	if r.Ref() == "" || strings.HasPrefix(r.Commit(), r.Ref()) {
+		return commit
+	}
+	return fmt.Sprintf("%v (%v)", r.Ref(), commit)
+}
+
 // Remove
 func runRepositoryRemove(_ *cobra.Command, args []string, newClient ClientFactory) (err error) {
 	cfg, err := newRepositoryConfig()
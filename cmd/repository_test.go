@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"testing"
 
 	. "knative.dev/func/pkg/testing"
@@ -164,3 +165,46 @@ func TestRepository_Remove(t *testing.T) {
 		t.Fatalf("expected:\n'%v'\ngot:\n'%v'\n", expect, output)
 	}
 }
+
+// TestRepository_Update ensures that the 'update' subcommand updates a
+// repository pinned to a tag to the latest release, and re-pins it with --ref.
+func TestRepository_Update(t *testing.T) {
+	url, commits := ServeVersionedRepo(t)
+	_ = FromTempDirectory(t)
+
+	var (
+		add    = NewRepositoryAddCmd(NewClient)
+		update = NewRepositoryUpdateCmd(NewClient)
+		stdout = piped(t)
+	)
+	add.SetArgs([]string{"versioned", url + "#v1.0.0"})
+	if err := add.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	update.SetArgs([]string{})
+	if err := update.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	update.SetArgs([]string{"versioned", "--ref", commits[0]})
+	if err := update.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	update.SetArgs([]string{"versioned", "--ref", commits[0]})
+	if err := update.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := fmt.Sprintf(`Updated versioned from v1.0.0 (%.7[1]s) to v1.1.0 (%.7[2]s)
+Updated versioned from v1.1.0 (%.7[2]s) to %.7[1]s
+versioned is up to date at %.7[1]s`, commits[0], commits[1])
+	if output := stdout(); output != expect {
+		t.Fatalf("expected:\n'%v'\ngot:\n'%v'\n", expect, output)
+	}
+
+	// Pinning requires a name
+	update.SetArgs([]string{"--ref", "main"})
+	if err := update.Execute(); err == nil {
+		t.Fatal("expected an error pinning without a repository name")
+	}
+}
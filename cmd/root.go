@@ -95,6 +95,7 @@ Learn more about Knative at: https://knative.dev`, cfg.Name),
 			Commands: []*cobra.Command{
 				NewRunCmd(newClient),
 				NewInvokeCmd(newClient),
+				NewTestCmd(newClient),
 				NewBuildCmd(newClient),
 			},
 		},
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ory/viper"
@@ -16,6 +19,8 @@ import (
 	"knative.dev/func/pkg/config"
 	"knative.dev/func/pkg/docker"
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/gateway"
+	"knative.dev/func/pkg/k8s"
 	"knative.dev/func/pkg/oci"
 )
 
@@ -30,7 +35,8 @@ NAME
 SYNOPSIS
 	{{rootCmdUse}} run [-r|--registry] [-i|--image] [-e|--env] [--build]
 				 [-b|--builder] [--builder-image] [-c|--confirm]
-	             [--address] [--json] [-v|--verbose]
+	             [--address] [-w|--watch] [--debug] [--resolve-from]
+	             [--container-runtime] [--gateway] [--json] [-v|--verbose]
 
 DESCRIPTION
 	Run the function locally.
@@ -45,6 +51,56 @@ DESCRIPTION
 	  builder when available. You can alter this by using the --builder flag
 	  eg: --builder=s2i.
 
+	Container Runtimes
+	  Containerized runs use the Docker daemon by default. With
+	  --container-runtime=podman the function is run through the libpod API of
+	  Podman, found at CONTAINER_HOST or at the default socket locations, and
+	  with --container-runtime=nerdctl through containerd using nerdctl. With
+	  either, functions built by the Host builder are run in a container by
+	  loading the OCI image written to .func/build, with no Docker daemon
+	  required. Images built by Pack or S2i must be available to the runtime.
+
+	Local Gateway
+	  With --gateway, such as --gateway=127.0.0.1:8090, functions running
+	  locally are routed by name from a gateway listening on that address,
+	  such that http://<name>.localhost:8090 reaches the function <name>
+	  whichever port it was started on. The gateway is served by one of the running 'func
+	  run' processes, another taking over when it exits. Instances of the
+	  same function are balanced round robin. Use '{{rootCmdUse}} list
+	  --local' to list the running instances and their routes.
+
+	Services
+	  Auxiliary containers declared in run.services of func.yaml, such as
+	  databases or message brokers, are started on a network shared with the
+	  function before it is started and removed when the run ends. They are
+	  kept while the function is restarted with --watch, such that their
+	  data is retained. Their addresses are injected as envs, for example
+	  REDIS_HOST, REDIS_PORT and REDIS_ADDRESS for a service named "redis"
+	  listening on port 6379. Services are started for containerized runs
+	  with the Docker daemon.
+
+	Debugging
+	  With --debug the function is run under the debugger of its runtime, to
+	  which an IDE can attach on the debug port printed (and included in the
+	  --json output). Go functions run under Delve and Python functions under
+	  debugpy, both with --builder=host. Node and TypeScript functions enable
+	  the inspector (--inspect) and Spring Boot and Quarkus functions JDWP in
+	  their containers.
+
+	Secrets and ConfigMaps
+	  Envs and volumes referencing Secrets and ConfigMaps are resolved for
+	  containerized runs when --resolve-from is provided. With
+	  --resolve-from=cluster they are read from the function's namespace on
+	  the current cluster. Otherwise the value is the path of a local file
+	  defining them:
+	    secrets:
+	      db:
+	        dsn: postgres://localhost/dev
+	    configMaps:
+	      proxy-config:
+	        proxy.yaml: "listen: 9090"
+	  Secret and ConfigMap volumes are mounted read-only into the container.
+
 	Process Scaffolding
 	  This is an Experimental Feature currently available only to Go and Python
 	  projects. When running a function with --builder=host, the function is
@@ -68,13 +124,28 @@ EXAMPLES
 	o Run the function locally on a specific address.
 	  $ {{rootCmdUse}} run --address='[::]:8081'
 
+	o Run the function locally, rebuilding and restarting it whenever its
+	  source changes.
+	  $ {{rootCmdUse}} run --watch
+
+	o Run a Go function locally under the Delve debugger.
+	  $ {{rootCmdUse}} run --builder=host --debug
+
+	o Run the function locally with the Secrets and ConfigMaps it references
+	  read from the cluster.
+	  $ {{rootCmdUse}} run --resolve-from=cluster
+
+	o Run a function built by the Host builder in a Podman container.
+	  $ {{rootCmdUse}} run --builder=host --container-runtime=podman
+
 	o Run the function locally and output JSON with the service address.
 	  $ {{rootCmdUse}} run --json
 `,
 		SuggestFor: []string{"rnu"},
 		PreRunE: bindEnv("build", "builder", "builder-image", "base-image",
 			"confirm", "env", "image", "path", "registry",
-			"start-timeout", "verbose", "address", "json"),
+			"start-timeout", "verbose", "address", "json", "resolve-from", "watch",
+			"debug", "container-runtime", "gateway"),
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return runRun(cmd, newClient)
 		},
@@ -131,6 +202,16 @@ EXAMPLES
 	cmd.Flags().String("address", "",
 		"Interface and port on which to bind and listen. Default is 127.0.0.1:8080, or an available port if 8080 is not available. ($FUNC_ADDRESS)")
 	cmd.Flags().Bool("json", false, "Output as JSON. ($FUNC_JSON)")
+	cmd.Flags().BoolP("watch", "w", false,
+		"Watch the function's source, rebuilding and restarting the function on the same address when it changes. ($FUNC_WATCH)")
+	cmd.Flags().Bool("debug", false,
+		"Run the function under the debugger of its runtime, listening on the debug port printed. ($FUNC_DEBUG)")
+	cmd.Flags().String("resolve-from", "",
+		"Resolve Secrets and ConfigMaps referenced by envs and volumes from the current cluster (\"cluster\") or from a local file at the given path. ($FUNC_RESOLVE_FROM)")
+	cmd.Flags().String("gateway", "",
+		fmt.Sprintf("Address of the local gateway routing http://<name>.localhost:<port> to the functions running locally, such as %v. Empty (default) disables routing. ($FUNC_GATEWAY)", gateway.DefaultAddress))
+	cmd.Flags().String("container-runtime", "",
+		fmt.Sprintf("Container runtime running the function in lieu of the Docker daemon. Supported runtimes are %v. With --builder=host the function is run in a container of this runtime rather than on the host. ($FUNC_CONTAINER_RUNTIME)", strings.Join(oci.ContainerRuntimes, ", ")))
 
 	// Oft-shared flags:
 	addConfirmFlag(cmd, cfg.Confirm)
@@ -173,7 +254,17 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 		return
 	}
 
-	container := f.Build.Builder != "host"
+	container := f.Build.Builder != "host" || cfg.ContainerRuntime != ""
+
+	// Init containers and sidecars are rendered only by the deployers
+	if n := len(f.Deploy.InitContainers) + len(f.Deploy.Sidecars); n > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %d init container(s) and sidecar(s) declared in func.yaml are not run locally. Only the function container is started.\n", n)
+	}
+
+	// Services are started by the docker runner only
+	if len(f.Run.Services) > 0 && (!container || cfg.ContainerRuntime != "") {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %d service(s) declared in func.yaml are only started for containerized runs with the Docker daemon and are not started for this run.\n", len(f.Run.Services))
+	}
 
 	// Ignore the verbose flag if JSON output
 	if cfg.JSON {
@@ -185,12 +276,31 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	if err != nil {
 		return
 	}
-	if container {
-		clientOptions = append(clientOptions, fn.WithRunner(docker.NewRunner(cfg.Verbose, os.Stdout, os.Stderr)))
+	if cfg.ContainerRuntime != "" {
+		runtime, err := oci.NewContainerRuntime(cfg.ContainerRuntime, cfg.Verbose)
+		if err != nil {
+			return err
+		}
+		clientOptions = append(clientOptions, fn.WithRunner(oci.NewRunner(runtime, cfg.Verbose, os.Stdout, os.Stderr)))
+	} else if container {
+		runnerOptions, err := cfg.runnerOptions(f)
+		if err != nil {
+			return err
+		}
+		runner := docker.NewRunner(cfg.Verbose, os.Stdout, os.Stderr, runnerOptions...)
+		defer func() {
+			if err := runner.Close(); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Services stop error. %v\n", err)
+			}
+		}()
+		clientOptions = append(clientOptions, fn.WithRunner(runner))
 	}
 	if cfg.StartTimeout != 0 {
 		clientOptions = append(clientOptions, fn.WithStartTimeout(cfg.StartTimeout))
 	}
+	if cfg.Debug && !container {
+		clientOptions = append(clientOptions, fn.WithRunDebug(true))
+	}
 
 	client, done := newClient(ClientConfig{Verbose: cfg.Verbose}, clientOptions...)
 	defer done()
@@ -199,11 +309,11 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	//
 	// If requesting to run via the container, build the container if it is
 	// either out-of-date or a build was explicitly requested.
+	var buildOptions []fn.BuildOption
 	if container {
 		var digested bool
 
-		buildOptions, err := cfg.buildOptions()
-		if err != nil {
+		if buildOptions, err = cfg.buildOptions(); err != nil {
 			return err
 		}
 
@@ -257,22 +367,37 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 		return wrapRunError(err, cfg.Address)
 	}
 	defer func() {
+		if job == nil { // a restart failed while watching
+			return
+		}
 		if err = job.Stop(); err != nil {
 			fmt.Fprintf(cmd.OutOrStderr(), "Job stop error. %v", err)
 		}
 	}()
 
+	// Gateway
+	//
+	// Register the running job, listed by `func list --local` and routed by
+	// the function's name on the local gateway if enabled.
+	route, register, unregister := startGateway(cmd.Context(), cmd.ErrOrStderr(), f, cfg.Gateway)
+	defer unregister()
+	register(job)
+
 	// Output based on format
 	if cfg.JSON {
 		// Create JSON output structure
 		output := struct {
-			Address string `json:"address"`
-			Host    string `json:"host"`
-			Port    string `json:"port"`
+			Address   string `json:"address"`
+			Host      string `json:"host"`
+			Port      string `json:"port"`
+			DebugPort string `json:"debugPort,omitempty"`
+			Route     string `json:"route,omitempty"`
 		}{
-			Address: fmt.Sprintf("http://%s:%s", job.Host, job.Port),
-			Host:    job.Host,
-			Port:    job.Port,
+			Address:   fmt.Sprintf("http://%s:%s", job.Host, job.Port),
+			Host:      job.Host,
+			Port:      job.Port,
+			DebugPort: job.DebugPort,
+			Route:     route,
 		}
 
 		jsonData, err := json.Marshal(output)
@@ -281,7 +406,29 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 		}
 		fmt.Fprintln(cmd.OutOrStdout(), string(jsonData))
 	} else {
-		fmt.Fprintf(cmd.OutOrStderr(), "Function running on %s\n", net.JoinHostPort(job.Host, job.Port))
+		printRunning(cmd.OutOrStderr(), job, route)
+	}
+
+	// Watch
+	//
+	// Rebuild and restart the function on changes to its source until
+	// stopped. The host runner builds the function itself on each run.
+	if cfg.Watch {
+		rebuild := func(f fn.Function) (fn.Function, error) {
+			if !container {
+				return f, nil
+			}
+			if err := client.Scaffold(cmd.Context(), f, ""); err != nil {
+				return f, err
+			}
+			return client.Build(cmd.Context(), f, buildOptions...)
+		}
+		started := func(job *fn.Job) {
+			register(job)
+			printRunning(cmd.ErrOrStderr(), job, route)
+		}
+		job, err = watch(cmd, client, f, job, rebuild, started)
+		return
 	}
 
 	select {
@@ -306,6 +453,114 @@ func runRun(cmd *cobra.Command, newClient ClientFactory) (err error) {
 	return
 }
 
+// watch the function's source, rebuilding and restarting the function on the
+// address of the given job when it changes. Failed builds keep the running
+// function. Returns the job last started, once the command's context is done.
+func watch(cmd *cobra.Command, client *fn.Client, f fn.Function, job *fn.Job, rebuild func(fn.Function) (fn.Function, error), started func(*fn.Job)) (*fn.Job, error) {
+	var (
+		ctx     = cmd.Context()
+		out     = cmd.ErrOrStderr()
+		address = net.JoinHostPort(job.Host, job.Port)
+		errs    = job.Errors
+	)
+	changes, err := fn.Watch(ctx, f.Root, fn.DefaultWatchDebounce)
+	if err != nil {
+		return job, fmt.Errorf("cannot watch %v: %w", f.Root, err)
+	}
+	fmt.Fprintf(out, "Watching %s for changes\n", f.Root)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if !errors.Is(ctx.Err(), context.Canceled) {
+				return job, ctx.Err()
+			}
+			return job, nil
+		case err := <-errs:
+			fmt.Fprintf(out, "Function exited: %v. Waiting for changes\n", err)
+			errs = nil
+		case paths := <-changes:
+			fmt.Fprintf(out, "Detected changes in %s. Rebuilding\n", summarizePaths(paths))
+			built, err := rebuild(f)
+			if err != nil {
+				fmt.Fprintf(out, "Build failed: %v. Waiting for changes\n", err)
+				continue
+			}
+			f = built
+
+			fmt.Fprintln(out, "Restarting function")
+			if job != nil {
+				if err = job.Stop(); err != nil {
+					fmt.Fprintf(out, "Job stop error. %v\n", err)
+				}
+			}
+			if job, err = client.Run(ctx, f, fn.RunWithAddress(address)); err != nil {
+				fmt.Fprintf(out, "Restart failed: %v. Waiting for changes\n", err)
+				job, errs = nil, nil
+				continue
+			}
+			errs = job.Errors
+			started(job)
+		}
+	}
+}
+
+// printRunning prints the address of the running function, its route on the
+// local gateway, and the address of its debugger when debugging.
+func printRunning(out io.Writer, job *fn.Job, route string) {
+	fmt.Fprintf(out, "Function running on %s\n", net.JoinHostPort(job.Host, job.Port))
+	if route != "" {
+		fmt.Fprintf(out, "Routed from %s\n", route)
+	}
+	if job.DebugPort != "" {
+		fmt.Fprintf(out, "Debugger listening on %s\n", net.JoinHostPort(job.Host, job.DebugPort))
+	}
+}
+
+// startGateway runs the local gateway on the given address until the context
+// is done, unless the gateway of another run already listens on it or the
+// address is empty.  Returned are the function's route on the gateway, empty
+// if disabled, and functions (un)registering the running job of the function
+// as a local instance.
+func startGateway(ctx context.Context, out io.Writer, f fn.Function, address string) (route string, register func(*fn.Job), unregister func()) {
+	registry := gateway.NewRegistry(config.InstancesPath())
+	if address != "" {
+		go func() {
+			if err := gateway.New(registry, address).Run(ctx); err != nil {
+				fmt.Fprintf(out, "Warning: local gateway stopped. %v\n", err)
+			}
+		}()
+		route = gateway.Route(f.Name, address)
+	}
+
+	unregister = func() {}
+	register = func(job *fn.Job) {
+		remove, err := registry.Register(gateway.Instance{
+			Name:    f.Name,
+			Runtime: f.Runtime,
+			Root:    f.Root,
+			Address: net.JoinHostPort(job.Host, job.Port),
+			Route:   route,
+			Started: time.Now(),
+		})
+		if err != nil {
+			fmt.Fprintf(out, "Warning: function not registered as a local instance. %v\n", err)
+			return
+		}
+		unregister = func() { _ = remove() }
+	}
+	return route, register, func() { unregister() }
+}
+
+// summarizePaths for printing, listing only the first few.
+func summarizePaths(paths []string) string {
+	const max = 3
+	if len(paths) <= max {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:max], ", "), len(paths)-max)
+}
+
 type runConfig struct {
 	buildConfig // further embeds config.Global
 
@@ -325,6 +580,24 @@ type runConfig struct {
 
 	// JSON output format
 	JSON bool
+
+	// Watch the function's source, rebuilding and restarting it on changes.
+	Watch bool
+
+	// Debug runs the function under the debugger of its runtime.
+	Debug bool
+
+	// ResolveFrom is where Secrets and ConfigMaps referenced by the function
+	// are resolved from: "cluster" or the path of a local overrides file.
+	ResolveFrom string
+
+	// ContainerRuntime runs the function in lieu of the Docker daemon, such
+	// as "podman" or "nerdctl".
+	ContainerRuntime string
+
+	// Gateway is the address of the local gateway routing to the function
+	// by name. Empty disables routing.
+	Gateway string
 }
 
 func newRunConfig(cmd *cobra.Command) (c runConfig) {
@@ -335,6 +608,12 @@ func newRunConfig(cmd *cobra.Command) (c runConfig) {
 		StartTimeout: viper.GetDuration("start-timeout"),
 		Address:      viper.GetString("address"),
 		JSON:         viper.GetBool("json"),
+		ResolveFrom:  viper.GetString("resolve-from"),
+		Watch:        viper.GetBool("watch"),
+		Debug:        viper.GetBool("debug"),
+
+		ContainerRuntime: viper.GetString("container-runtime"),
+		Gateway:          viper.GetString("gateway"),
 	}
 	// NOTE: .Env should be viper.GetStringSlice, but this returns unparsed
 	// results and appears to be an open issue since 2017:
@@ -362,6 +641,27 @@ func (c runConfig) Configure(f fn.Function) (fn.Function, error) {
 	return f, err
 }
 
+// runnerOptions returns the options of the container runner.
+func (c runConfig) runnerOptions(f fn.Function) ([]docker.RunnerOpt, error) {
+	oo := []docker.RunnerOpt{docker.WithDebug(c.Debug)}
+	switch c.ResolveFrom {
+	case "":
+	case "cluster":
+		namespace := f.Namespace
+		if namespace == "" {
+			namespace = f.Deploy.Namespace
+		}
+		oo = append(oo, docker.WithResourceResolver(k8s.ResourceResolver{Namespace: namespace}))
+	default:
+		overrides, err := docker.LoadResourceOverrides(c.ResolveFrom)
+		if err != nil {
+			return nil, err
+		}
+		oo = append(oo, docker.WithResourceResolver(overrides))
+	}
+	return oo, nil
+}
+
 func (c runConfig) Prompt() (runConfig, error) {
 	var err error
 
@@ -390,6 +690,43 @@ func (c runConfig) Validate(cmd *cobra.Command, f fn.Function) (err error) {
 		}
 	}
 
+	// Watching rebuilds the function
+	if c.Watch {
+		if build, err := strconv.ParseBool(c.Build); err == nil && !build {
+			return errors.New("--watch cannot be used with --build=false, as changes are rebuilt")
+		}
+		if c.Image != "" {
+			if digested, err := isDigested(c.Image); err == nil && digested {
+				return errors.New("--watch cannot be used with a digested --image, as changes are rebuilt")
+			}
+		}
+	}
+
+	// Other container runtimes run the function's image as is
+	if c.ContainerRuntime != "" {
+		if !slices.Contains(oci.ContainerRuntimes, c.ContainerRuntime) {
+			return fmt.Errorf("unsupported container runtime %q. Supported runtimes are %v", c.ContainerRuntime, strings.Join(oci.ContainerRuntimes, ", "))
+		}
+		if c.ResolveFrom != "" {
+			return errors.New("--resolve-from is only supported with the Docker daemon, not with --container-runtime")
+		}
+		if c.Debug {
+			return errors.New("--debug is only supported with the Docker daemon or --builder=host, not with --container-runtime")
+		}
+	}
+
+	// Debugging in a container is checked before the image is built
+	if c.Debug && c.Builder != "host" && c.ContainerRuntime == "" {
+		if err = docker.CheckDebug(f.Runtime); err != nil {
+			return
+		}
+	}
+
+	// Secrets and ConfigMaps are only resolved into containers
+	if c.ResolveFrom != "" && c.Builder == "host" {
+		return errors.New("--resolve-from is only supported for containerized runs, not with --builder=host")
+	}
+
 	// Validate address port if provided
 	if c.Address != "" {
 		host, port, err := net.SplitHostPort(c.Address)
@@ -419,7 +756,7 @@ func (c runConfig) Validate(cmd *cobra.Command, f fn.Function) (err error) {
 
 	// When the docker runner respects the StartTimeout, this validation check
 	// can be removed
-	if c.StartTimeout != 0 && f.Build.Builder != "host" {
+	if c.StartTimeout != 0 && f.Build.Builder != "host" && c.ContainerRuntime == "" {
 		return errors.New("the ability to specify the startup timeout for containerized runs is coming soon")
 	}
 
@@ -1,8 +1,15 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -603,3 +610,191 @@ func TestRun_BaseImage(t *testing.T) {
 		})
 	}
 }
+
+// TestRun_ResolveFromRequiresContainer ensures Secrets and ConfigMaps are
+// only resolved for containerized runs.
+func TestRun_ResolveFromRequiresContainer(t *testing.T) {
+	root := FromTempDirectory(t)
+	_, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewRunCmd(NewTestClient(fn.WithRunner(mock.NewRunner())))
+	cmd.SetArgs([]string{"--builder=host", "--resolve-from=cluster"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--resolve-from") {
+		t.Fatalf("expected a --resolve-from error, got %v", err)
+	}
+}
+
+// TestRun_ContainerRuntimeValidation ensures unsupported container runtimes
+// and options which require the Docker daemon are rejected.
+func TestRun_ContainerRuntimeValidation(t *testing.T) {
+	root := FromTempDirectory(t)
+	_, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--container-runtime=crio"}, "unsupported container runtime"},
+		{[]string{"--container-runtime=podman", "--resolve-from=cluster"}, "--resolve-from"},
+		{[]string{"--container-runtime=nerdctl", "--debug"}, "--debug"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			cmd := NewRunCmd(NewTestClient(fn.WithRunner(mock.NewRunner())))
+			cmd.SetArgs(append([]string{"--builder=host"}, tt.args...))
+			if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestRun_DebugValidation ensures that debugging a containerized function
+// of a runtime whose debugger is not in its image is rejected before it is
+// built.
+func TestRun_DebugValidation(t *testing.T) {
+	root := FromTempDirectory(t)
+	_, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := mock.NewBuilder()
+	cmd := NewRunCmd(NewTestClient(fn.WithBuilder(builder), fn.WithRunner(mock.NewRunner())))
+	cmd.SetArgs([]string{"--builder=pack", "--debug"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--builder=host") {
+		t.Fatalf("expected a debugging error, got %v", err)
+	}
+	if builder.BuildInvoked {
+		t.Fatal("expected the function not to be built")
+	}
+}
+
+// TestRun_Watch ensures the function is rebuilt and restarted on the same
+// address when its source changes.
+func TestRun_Watch(t *testing.T) {
+	root := FromTempDirectory(t)
+	_, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addresses := make(chan string, 10)
+	runner := mock.NewRunner()
+	runner.RunFn = func(_ context.Context, f fn.Function, addr string, _ time.Duration) (*fn.Job, error) {
+		addresses <- addr
+		errs := make(chan error, 1)
+		stop := func() error { return nil }
+		return fn.NewJob(f, "127.0.0.1", "8081", errs, stop, false)
+	}
+	var builds atomic.Int32
+	builder := mock.NewBuilder()
+	builder.BuildFn = func(fn.Function) error {
+		builds.Add(1)
+		return nil
+	}
+
+	cmd := NewRunCmd(NewTestClient(
+		fn.WithRunner(runner),
+		fn.WithBuilder(builder),
+		fn.WithRegistry("ghcr.com/reg"),
+	))
+	cmd.SetArgs([]string{"--watch"})
+
+	// The source is watched once reported as such on stderr.
+	stderr, stderrW := io.Pipe()
+	cmd.SetErr(stderrW)
+	watching := make(chan struct{})
+	go func() {
+		s := bufio.NewScanner(stderr)
+		for s.Scan() {
+			if strings.HasPrefix(s.Text(), "Watching ") {
+				close(watching)
+				break
+			}
+		}
+		_, _ = io.Copy(io.Discard, stderr)
+	}()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	runErrCh := make(chan error, 1)
+	go func() {
+		_, err := cmd.ExecuteContextC(ctx)
+		_ = stderrW.Close()
+		runErrCh <- err
+	}()
+
+	select {
+	case <-addresses:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the function to run")
+	}
+	select {
+	case <-watching:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the source to be watched")
+	}
+	if err = os.WriteFile(filepath.Join(root, "handle.go"), []byte("package function"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case addr := <-addresses:
+		if addr != "127.0.0.1:8081" {
+			t.Errorf("expected the restart on the same address, got %q", addr)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the function to restart")
+	}
+	if n := builds.Load(); n < 2 {
+		t.Errorf("expected the function to be rebuilt, got %d builds", n)
+	}
+
+	cancel()
+	if err = <-runErrCh; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRun_DebugJSON ensures the debug port is included in the JSON output
+// for IDE integration.
+func TestRun_DebugJSON(t *testing.T) {
+	root := FromTempDirectory(t)
+	_, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runner := mock.NewRunner()
+	runner.RunFn = func(_ context.Context, f fn.Function, _ string, _ time.Duration) (*fn.Job, error) {
+		job, err := fn.NewJob(f, "127.0.0.1", "8080", nil, nil, false)
+		if job != nil {
+			job.DebugPort = "2345"
+		}
+		return job, err
+	}
+
+	cmd := NewRunCmd(NewTestClient(
+		fn.WithRunner(runner),
+		fn.WithRegistry("ghcr.com/reg"),
+	))
+	cmd.SetArgs([]string{"--builder=host", "--debug", "--json"})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel() // return once running
+	if _, err = cmd.ExecuteContextC(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"debugPort":"2345"`) {
+		t.Errorf("expected the debug port in the JSON output, got %q", out.String())
+	}
+}
@@ -1,18 +1,23 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/ory/viper"
 	"github.com/spf13/cobra"
 
+	"knative.dev/func/pkg/builders"
 	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/oci"
 )
 
 // ErrTemplateRepoDoesNotExist is a sentinel error if a template repository responds with 404 status code
@@ -28,6 +33,9 @@ NAME
 
 SYNOPSIS
 	{{rootCmdUse}} templates [language] [--json] [-r|--repository]
+	{{rootCmdUse}} templates diff [--ref <ref>] [-p|--path]
+	{{rootCmdUse}} templates lint [path] [--build]
+	{{rootCmdUse}} templates publish [path] --tag <tag> [-o|--output] [--build]
 
 DESCRIPTION
 	List all templates available, optionally for a specific language runtime.
@@ -41,6 +49,12 @@ DESCRIPTION
 
 	To see all available language runtimes, see the 'languages' command.
 
+	To show the changes made to the template of a function since the function
+	was created from it, use the 'diff' subcommand.
+
+	To check a template repository being authored, use the 'lint'
+	subcommand.  To tag and package it for distribution, use 'publish'.
+
 
 EXAMPLES
 
@@ -56,6 +70,7 @@ EXAMPLES
 	o Return Go templates in a specific repository
 		$ {{rootCmdUse}} templates go --repository=https://github.com/boson-project/templates
 `,
+		Args:    cobra.ArbitraryArgs, // language, validated when run
 		PreRunE: bindEnv("json", "repository", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runTemplates(cmd, args, newClient)
@@ -71,6 +86,172 @@ EXAMPLES
 	cmd.Flags().StringP("repository", "r", "", "URI to a specific repository to consider ($FUNC_REPOSITORY)")
 	addVerboseFlag(cmd, cfg.Verbose)
 
+	cmd.AddCommand(NewTemplatesDiffCmd(newClient))
+	cmd.AddCommand(NewTemplatesLintCmd())
+	cmd.AddCommand(NewTemplatesPublishCmd())
+
+	return cmd
+}
+
+func NewTemplatesDiffCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show changes to a function's template since its creation",
+		Long: `
+NAME
+	{{rootCmdUse}} templates diff - show changes to a function's template
+
+SYNOPSIS
+	{{rootCmdUse}} templates diff [--ref <ref>] [-p|--path]
+
+DESCRIPTION
+	Show, as a patch, what changed in the template of a function since the
+	function was created from it.
+
+	The version of the template from which a function was created is recorded
+	in its func.lock.  Changes are shown up to the version of the template's
+	repository currently installed, which can be updated using
+	'{{rootCmdUse}} repository update', or else up to its latest version.  To
+	compare with a specific branch, tag or commit (for repositories
+	distributed as OCI artifacts, a tag or digest) use --ref.
+
+	Only templates of git repositories and OCI artifacts are versioned.
+	Functions created from the default, embedded templates can not be
+	compared.
+
+EXAMPLES
+
+	o Show changes to the template of the function in the current directory
+	  $ {{rootCmdUse}} templates diff
+
+	o Show changes to the template up to the tag v2.0.0 of its repository
+	  $ {{rootCmdUse}} templates diff --ref v2.0.0
+`,
+		SuggestFor: []string{"dif", "changes"},
+		PreRunE:    bindEnv("path", "ref", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTemplatesDiff(cmd, newClient)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().String("ref", "", "Branch, tag or commit of the template's repository to compare with ($FUNC_REF)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
+func NewTemplatesLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint [path]",
+		Short: "Check a template repository",
+		Long: `
+NAME
+	{{rootCmdUse}} templates lint - check a template repository
+
+SYNOPSIS
+	{{rootCmdUse}} templates lint [path] [--build]
+
+DESCRIPTION
+	Check the template repository at the given path, by default the current
+	directory, for issues which would otherwise only surface when creating
+	functions from it.
+
+	Checked are:
+	  o The manifest.yaml files of the repository, its runtimes and templates,
+	    which may not contain unknown fields or invalid values.
+	  o The templates path of the repository, which must contain runtimes,
+	    each of which must contain templates.
+	  o That a function can be created from each template.  Required template
+	    parameters without a default are given sample values.
+	  o That the signature of functions of runtimes with scaffolding (go,
+	    python) is detected.
+	  o That functions of runtimes supported by the host builder build, unless
+	    --build=false.
+
+	Each issue found is printed, prefixed with the path within the
+	repository to which it pertains.
+
+EXAMPLES
+
+	o Check the template repository in the current directory
+	  $ {{rootCmdUse}} templates lint
+
+	o Check a template repository without building its templates
+	  $ {{rootCmdUse}} templates lint ./my-templates --build=false
+`,
+		SuggestFor: []string{"lnit", "check"},
+		Args:       cobra.MaximumNArgs(1),
+		PreRunE:    bindEnv("build", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTemplatesLint(cmd, args)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().Bool("build", true, "Build the templates supported by the host builder ($FUNC_BUILD)")
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
+func NewTemplatesPublishCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish [path]",
+		Short: "Tag and package a template repository",
+		Long: `
+NAME
+	{{rootCmdUse}} templates publish - tag and package a template repository
+
+SYNOPSIS
+	{{rootCmdUse}} templates publish [path] --tag <tag> [-o|--output] [--build]
+
+DESCRIPTION
+	Publish the template repository at the given path, by default the current
+	directory, which must be a git repository without uncommitted changes.
+
+	The repository is first checked as with '{{rootCmdUse}} templates lint'.
+	Its current commit is then tagged, and the repository's files at that
+	commit written as a zip archive, laid out as the templates embedded in
+	{{rootCmdUse}}.  The archive is by default named <repository>-<tag>.zip.
+
+	The tag is created locally.  Push it for it to be available to users
+	adding the repository with '{{rootCmdUse}} repository add'.
+
+EXAMPLES
+
+	o Publish the template repository in the current directory as v1.0.0
+	  $ {{rootCmdUse}} templates publish --tag v1.0.0
+
+	o Publish a template repository to a specific archive
+	  $ {{rootCmdUse}} templates publish ./my-templates --tag v1.0.0 -o templates.zip
+`,
+		Args:    cobra.MaximumNArgs(1),
+		PreRunE: bindEnv("tag", "output", "build", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTemplatesPublish(cmd, args)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().String("tag", "", "Tag of the published version, for example v1.0.0 ($FUNC_TAG)")
+	cmd.Flags().StringP("output", "o", "", "Path of the zip archive to write.  Default is <repository>-<tag>.zip ($FUNC_OUTPUT)")
+	cmd.Flags().Bool("build", true, "Build the templates supported by the host builder ($FUNC_BUILD)")
+	addVerboseFlag(cmd, cfg.Verbose)
+
 	return cmd
 }
 
@@ -163,6 +344,104 @@ func runTemplates(cmd *cobra.Command, args []string, newClient ClientFactory) (e
 	return
 }
 
+func runTemplatesDiff(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	var (
+		path = viper.GetString("path")
+		ref  = viper.GetString("ref")
+	)
+	f, err := fn.NewFunction(path)
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return fn.NewErrNotInitialized(f.Root)
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose")})
+	defer done()
+
+	diff, err := client.Templates().Diff(cmd.Context(), f.Root, ref)
+	if errors.Is(err, fn.ErrLockNotFound) {
+		return fmt.Errorf("%w. Only functions created with a version of func recording the template version can be compared", err)
+	} else if err != nil {
+		return
+	}
+	if diff == "" {
+		fmt.Fprintln(cmd.ErrOrStderr(), "No changes to the template")
+		return
+	}
+	fmt.Fprint(cmd.OutOrStdout(), diff)
+	return
+}
+
+func runTemplatesLint(cmd *cobra.Command, args []string) error {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+	return lintTemplates(cmd, path)
+}
+
+// lintTemplates of the repository at path, printing the issues found, which
+// are returned as an error.
+func lintTemplates(cmd *cobra.Command, path string) error {
+	verbose := viper.GetBool("verbose")
+	opts := []fn.LintOption{}
+	if viper.GetBool("build") {
+		opts = append(opts, fn.LintWithBuilder(oci.NewBuilder(builders.Host, verbose), oci.IsSupported))
+	}
+	if verbose {
+		opts = append(opts, fn.LintWithProgress(cmd.ErrOrStderr()))
+	}
+	issues, err := fn.LintRepository(cmd.Context(), path, opts...)
+	if err != nil {
+		return err
+	}
+	for _, i := range issues {
+		fmt.Fprintln(cmd.OutOrStdout(), i)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("found %v issue(s) in template repository %v", len(issues), path)
+	}
+	return nil
+}
+
+func runTemplatesPublish(cmd *cobra.Command, args []string) (err error) {
+	var (
+		path   = "."
+		tag    = viper.GetString("tag")
+		output = viper.GetString("output")
+	)
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if tag == "" {
+		return errors.New("a tag is required. Provide one with --tag")
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return
+	}
+	if output == "" {
+		output = filepath.Base(path) + "-" + tag + ".zip"
+	}
+
+	if err = lintTemplates(cmd, path); err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	commit, err := fn.PublishRepository(path, tag, &buf)
+	if err != nil {
+		return
+	}
+	if err = os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Tagged %.7s as %v and wrote %v\n", commit, tag, output)
+	fmt.Fprintf(cmd.ErrOrStderr(), "Push the tag to make it available: git push origin %v\n", tag)
+	return
+}
+
 type templatesConfig struct {
 	Verbose    bool
 	Repository string // Consider only a specific repository (URI)
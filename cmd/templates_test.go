@@ -1,11 +1,22 @@
 package cmd
 
 import (
+	"archive/zip"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/google/go-cmp/cmp"
 	"gotest.tools/v3/assert"
+
+	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/filesystem"
+	fn "knative.dev/func/pkg/functions"
 	. "knative.dev/func/pkg/testing"
 )
 
@@ -149,3 +160,103 @@ func TestTemplates_WrongRepositoryUrl(t *testing.T) {
 	err := cmd.Execute()
 	assert.Assert(t, err != nil)
 }
+
+// TestTemplates_Diff ensures that the 'diff' subcommand shows the changes to
+// a function's template since its creation.
+func TestTemplates_Diff(t *testing.T) {
+	url, _ := ServeVersionedRepo(t)
+	root := FromTempDirectory(t)
+
+	add := NewRepositoryAddCmd(NewClient)
+	add.SetArgs([]string{"versioned", url + "#v1.0.0"})
+	if err := add.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	client := fn.New(fn.WithRepositoriesPath(config.RepositoriesPath()))
+	if _, err := client.Init(fn.Function{Root: root, Runtime: "go", Template: "versioned/versioned"}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := piped(t)
+	cmd := NewTemplatesCmd(NewClient)
+	cmd.SetArgs([]string{"diff", "--ref", "v1.1.0"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	diff := buf()
+	if !strings.Contains(diff, "diff --git a/handle.go b/handle.go") || !strings.Contains(diff, "+// Version 2") {
+		t.Fatalf("unexpected diff:\n%v", diff)
+	}
+}
+
+// TestTemplates_Lint ensures that the 'lint' subcommand prints the issues
+// found in a template repository and fails.
+func TestTemplates_Lint(t *testing.T) {
+	root := FromTempDirectory(t)
+	if err := os.MkdirAll(filepath.Join(root, "custom", "tpl"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "custom", "tpl", "manifest.yaml"), []byte("invoke: grpc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := piped(t)
+	cmd := NewTemplatesCmd(NewClient)
+	cmd.SetArgs([]string{"lint", "--build=false"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected linting to fail")
+	}
+	if out := buf(); !strings.Contains(out, `custom/tpl/manifest.yaml: invoke "grpc" is invalid`) {
+		t.Fatalf("unexpected output:\n%v", out)
+	}
+}
+
+// TestTemplates_Publish ensures that the 'publish' subcommand tags the
+// template repository and writes its archive.
+func TestTemplates_Publish(t *testing.T) {
+	root := FromTempDirectory(t)
+	if err := os.MkdirAll(filepath.Join(root, "custom", "tpl"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "custom", "tpl", "README.md"), []byte("example\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = wt.Add("."); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "xyz", Email: "xyz@abc.com", When: time.Now()}
+	if _, err = wt.Commit("init", &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewTemplatesCmd(NewClient)
+	cmd.SetArgs([]string{"publish", "--build=false"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected publishing without a tag to fail")
+	}
+
+	cmd = NewTemplatesCmd(NewClient)
+	cmd.SetArgs([]string{"publish", "--tag", "v1.0.0", "--build=false"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.Tag("v1.0.0"); err != nil {
+		t.Fatalf("expected tag v1.0.0: %v", err)
+	}
+	zr, err := zip.OpenReader(filepath.Join(root, filepath.Base(root)+"-v1.0.0.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	if _, err = filesystem.NewZipFS(&zr.Reader).Stat("custom/tpl/README.md"); err != nil {
+		t.Fatal(err)
+	}
+}
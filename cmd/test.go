@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// ErrTestsFailed is returned when the function's test suite ran but did not
+// pass, such that the command exits non-zero.
+var ErrTestsFailed = errors.New("tests failed")
+
+func NewTestCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [-- <test tool arguments>]",
+		Short: "Run the function's tests",
+		Long: `
+NAME
+	{{rootCmdUse}} test - Run the function's tests
+
+SYNOPSIS
+	{{rootCmdUse}} test [--integration] [--address] [-o|--output]
+	             [-p|--path] [-v|--verbose] [-- <test tool arguments>]
+
+DESCRIPTION
+	Runs the function's tests using the language-native test tool of its
+	runtime in the function's root directory:
+	  go:                  go test ./...
+	  python:              python -m pytest
+	  node, typescript:    npm test
+	  quarkus, springboot: ./mvnw test (or mvn test)
+	  rust:                cargo test
+
+	Arguments following "--" are passed to the test tool.
+
+	Integration Tests
+	  With --integration the function is first started locally (see
+	  {{rootCmdUse}} run) and stopped once the tests complete.  The address of
+	  the running function is provided to the tests in the ` + fn.TestAddressEnv + `
+	  environment variable.  Functions which are not built using the host
+	  builder must already be built (see {{rootCmdUse}} build).
+
+	The command exits non-zero if the tests do not pass.
+
+EXAMPLES
+
+	o Run the function's unit tests
+	  $ {{rootCmdUse}} test
+
+	o Run the function's tests against a locally running instance
+	  $ {{rootCmdUse}} test --integration
+
+	o Pass additional arguments to the test tool
+	  $ {{rootCmdUse}} test -- -run TestHandle -v
+
+	o Output the test result as JSON
+	  $ {{rootCmdUse}} test --output json
+`,
+		SuggestFor: []string{"tset", "tests"},
+		PreRunE:    bindEnv("integration", "address", "output", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTest(cmd, args, newClient)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().Bool("integration", false, "Run the function locally for the duration of the tests. ($FUNC_INTEGRATION)")
+	cmd.Flags().String("address", "",
+		"Interface and port on which the function listens when testing with --integration. ($FUNC_ADDRESS)")
+	cmd.Flags().StringP("output", "o", "human", "Output format of the test result (human|plain|json|yaml) ($FUNC_OUTPUT)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	if err := cmd.RegisterFlagCompletionFunc("output", CompleteOutputFormatList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+
+	return cmd
+}
+
+func runTest(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
+	cfg := newTestConfig(args)
+
+	f, err := fn.NewFunction(cfg.Path)
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return NewErrNotInitializedFromPath(f.Root, "test")
+	}
+	if err = cfg.Validate(f); err != nil {
+		return
+	}
+
+	// When the result is to be machine-readable, the test tool's own output
+	// is sent to stderr such that stdout contains only the result.
+	out := cmd.OutOrStdout()
+	if cfg.Output == string(JSON) || cfg.Output == string(YAML) {
+		out = cmd.ErrOrStderr()
+	}
+
+	clientOptions := []fn.Option{fn.WithTester(fn.NewTester(cfg.Verbose, out, cmd.ErrOrStderr()))}
+	if cfg.Integration && f.Build.Builder != "host" {
+		runner := docker.NewRunner(cfg.Verbose, out, cmd.ErrOrStderr())
+		defer func() {
+			if err := runner.Close(); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Services stop error. %v\n", err)
+			}
+		}()
+		clientOptions = append(clientOptions, fn.WithRunner(runner))
+	}
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose}, clientOptions...)
+	defer done()
+
+	result, err := client.Test(cmd.Context(), f,
+		fn.TestWithIntegration(cfg.Integration),
+		fn.TestWithAddress(cfg.Address),
+		fn.TestWithArgs(cfg.Args))
+	if err != nil {
+		return
+	}
+
+	write(cmd.OutOrStdout(), testResult(result), cfg.Output)
+
+	if !result.Passed {
+		return ErrTestsFailed
+	}
+	return
+}
+
+type testConfig struct {
+	Path        string
+	Integration bool
+	Address     string
+	Output      string
+	Verbose     bool
+	Args        []string
+}
+
+func newTestConfig(args []string) testConfig {
+	return testConfig{
+		Path:        viper.GetString("path"),
+		Integration: viper.GetBool("integration"),
+		Address:     viper.GetString("address"),
+		Output:      viper.GetString("output"),
+		Verbose:     viper.GetBool("verbose"),
+		Args:        args,
+	}
+}
+
+func (c testConfig) Validate(f fn.Function) error {
+	if c.Address != "" && !c.Integration {
+		return errors.New("--address is only applicable when testing with --integration")
+	}
+	if c.Integration && f.Build.Builder != "host" && !f.Built() {
+		return errors.New("the function must be built before testing with --integration. run 'func build' first")
+	}
+	return nil
+}
+
+// Output Formatting (serializers)
+// -------------------------------
+
+type testResult fn.TestResult
+
+func (r testResult) Human(w io.Writer) error {
+	status := "PASS"
+	if !r.Passed {
+		status = fmt.Sprintf("FAIL (exit code %d)", r.ExitCode)
+	}
+	fmt.Fprintf(w, "%s: %s (%s)\n", status, r.Command, r.Duration.Round(time.Millisecond))
+	if r.Address != "" {
+		fmt.Fprintf(w, "Tested against function running at %s\n", r.Address)
+	}
+	return nil
+}
+
+func (r testResult) Plain(w io.Writer) error {
+	fmt.Fprintf(w, "Runtime %s\n", r.Runtime)
+	fmt.Fprintf(w, "Command %s\n", r.Command)
+	fmt.Fprintf(w, "Passed %t\n", r.Passed)
+	fmt.Fprintf(w, "ExitCode %d\n", r.ExitCode)
+	fmt.Fprintf(w, "Duration %s\n", r.Duration)
+	if r.Address != "" {
+		fmt.Fprintf(w, "Address %s\n", r.Address)
+	}
+	return nil
+}
+
+func (r testResult) JSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+func (r testResult) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(r)
+}
+
+func (r testResult) URL(w io.Writer) error {
+	fmt.Fprintln(w, r.Address)
+	return nil
+}
+
+var _ Formatter = testResult{}
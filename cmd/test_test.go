@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestTest_Default ensures that the test command invokes the tester in the
+// function's root, passing through trailing arguments, and without running
+// the function.
+func TestTest_Default(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	tester := mock.NewTester()
+	runner := mock.NewRunner()
+	cmd := NewTestCmd(NewTestClient(fn.WithTester(tester), fn.WithRunner(runner)))
+	cmd.SetArgs([]string{"--", "-run", "TestHandle"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !tester.TestInvoked {
+		t.Fatal("tester was not invoked")
+	}
+	if tester.RootRequested != root {
+		t.Fatalf("expected root %q, got %q", root, tester.RootRequested)
+	}
+	if !reflect.DeepEqual(tester.ArgsRequested, []string{"-run", "TestHandle"}) {
+		t.Fatalf("unexpected test tool arguments %v", tester.ArgsRequested)
+	}
+	if runner.RunInvoked {
+		t.Fatal("function should not be run without --integration")
+	}
+}
+
+// TestTest_Integration ensures the function is run when testing with
+// --integration, and its address is reported in the structured output.
+func TestTest_Integration(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Build: fn.BuildSpec{Builder: "host"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tester := mock.NewTester()
+	runner := mock.NewRunner()
+	cmd := NewTestCmd(NewTestClient(fn.WithTester(tester), fn.WithRunner(runner)))
+	cmd.SetArgs([]string{"--integration", "--output", "json"})
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !runner.RunInvoked {
+		t.Fatal("function was not run for integration tests")
+	}
+	var result fn.TestResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("output is not a JSON test result: %v\n%s", err, out.String())
+	}
+	if !result.Passed || result.Address != "http://127.0.0.1:8080" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+// TestTest_Failed ensures that failing tests cause the command to fail.
+func TestTest_Failed(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	tester := mock.NewTester()
+	tester.TestFn = func(context.Context, fn.Function, []string, []string) (fn.TestResult, error) {
+		return fn.TestResult{Runtime: "go", ExitCode: 1}, nil
+	}
+	cmd := NewTestCmd(NewTestClient(fn.WithTester(tester)))
+	cmd.SetArgs([]string{})
+	cmd.SetOut(&bytes.Buffer{})
+	if err := cmd.Execute(); !errors.Is(err, ErrTestsFailed) {
+		t.Fatalf("expected ErrTestsFailed, got %v", err)
+	}
+}
+
+// TestTest_IntegrationRequiresBuild ensures that containerized functions
+// must be built before being tested with --integration.
+func TestTest_IntegrationRequiresBuild(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Build: fn.BuildSpec{Builder: "pack"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tester := mock.NewTester()
+	cmd := NewTestCmd(NewTestClient(fn.WithTester(tester)))
+	cmd.SetArgs([]string{"--integration"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error testing an unbuilt containerized function")
+	}
+	if tester.TestInvoked {
+		t.Fatal("tester should not be invoked")
+	}
+}
@@ -22,6 +22,10 @@ const (
 	// Repositories is the default directory for repositoires.
 	Repositories = "repositories"
 
+	// Instances is the default directory in which functions running locally
+	// are registered.
+	Instances = "instances"
+
 	// DefaultLanguage is intentionaly undefined.
 	DefaultLanguage = ""
 
@@ -209,6 +213,16 @@ func RepositoriesPath() string {
 	return path
 }
 
+// InstancesPath returns the full path at which functions running locally
+// are registered. Use FUNC_INSTANCES_PATH to override default.
+func InstancesPath() string {
+	path := filepath.Join(Dir(), Instances)
+	if e := os.Getenv("FUNC_INSTANCES_PATH"); e != "" {
+		path = e
+	}
+	return path
+}
+
 // CreatePaths is a convenience function for creating the on-disk func config
 // structure. All operations should be tolerant of nonexistent disk
 // footprint where possible (for example listing repositories should not
@@ -81,6 +81,52 @@ func CheckAuth(ctx context.Context, image string, credentials oci.Credentials, t
 	return nil
 }
 
+// CheckPullAuth verifies that credentials can be used to pull the image
+func CheckPullAuth(ctx context.Context, image string, credentials oci.Credentials, trans http.RoundTripper) error {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return fmt.Errorf("cannot parse image reference: %w", err)
+	}
+
+	kc := keyChain{
+		user: credentials.Username,
+		pwd:  credentials.Password,
+	}
+
+	_, err = remote.Head(ref, remote.WithAuthFromKeychain(kc), remote.WithTransport(trans), remote.WithContext(ctx))
+	var transportErr *transport.Error
+	if errors.As(err, &transportErr) {
+		if transportErr.StatusCode == 401 || transportErr.StatusCode == 403 {
+			return ErrUnauthorized
+		}
+		return nil // such as the image not existing, regardless of credentials
+	}
+	return err
+}
+
+// NewKeychain returns a keychain which resolves the credentials of a resource
+// using the given provider, for use with go-containerregistry.  Resources for
+// which no credentials are found are accessed anonymously.
+func NewKeychain(ctx context.Context, provider oci.CredentialsProvider) authn.Keychain {
+	return providerKeychain{ctx: ctx, provider: provider}
+}
+
+type providerKeychain struct {
+	ctx      context.Context
+	provider oci.CredentialsProvider
+}
+
+func (k providerKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	credentials, err := k.provider(k.ctx, resource.String())
+	if errors.Is(err, ErrCredentialsNotFound) || (err == nil && credentials == oci.Credentials{}) {
+		return authn.Anonymous, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
 type ChooseCredentialHelperCallback func(available []string) (string, error)
 
 type credentialsProvider struct {
@@ -12,9 +12,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -25,6 +27,9 @@ import (
 	"time"
 
 	"github.com/docker/docker-credential-helpers/credentials"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
 
 	"knative.dev/func/pkg/creds"
 	"knative.dev/func/pkg/oci"
@@ -220,6 +225,65 @@ func TestCheckAuth(t *testing.T) {
 	}
 }
 
+func TestCheckPullAuth(t *testing.T) {
+	const (
+		uname = "testuser"
+		pwd   = "testpwd"
+	)
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != uname || p != pwd {
+			w.Header().Add("WWW-Authenticate", "basic")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	image := strings.TrimPrefix(server.URL, "http://") + "/someorg/someimage:sometag"
+
+	// The image not existing is not a matter of credentials
+	err := creds.CheckPullAuth(t.Context(), image, oci.Credentials{Username: uname, Password: pwd}, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = creds.CheckPullAuth(t.Context(), image, oci.Credentials{Username: uname, Password: "badpwd"}, http.DefaultTransport)
+	if !errors.Is(err, creds.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestNewKeychain(t *testing.T) {
+	provider := func(ctx context.Context, image string) (oci.Credentials, error) {
+		if strings.HasPrefix(image, "private.example.com/") {
+			return oci.Credentials{Username: "testuser", Password: "testpwd"}, nil
+		}
+		return oci.Credentials{}, creds.ErrCredentialsNotFound
+	}
+	kc := creds.NewKeychain(t.Context(), provider)
+
+	for repository, expected := range map[string]authn.AuthConfig{
+		"private.example.com/someorg/someimage": {Username: "testuser", Password: "testpwd"},
+		"public.example.com/someorg/someimage":  {},
+	} {
+		repo, err := name.NewRepository(repository)
+		if err != nil {
+			t.Fatal(err)
+		}
+		auth, err := kc.Resolve(repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := auth.Authorization()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *cfg != expected {
+			t.Errorf("expected %+v for %v, got %+v", expected, repository, *cfg)
+		}
+	}
+}
+
 func TestCheckAuthEmptyCreds(t *testing.T) {
 
 	localhost, _, _ := startServer(t, "", "")
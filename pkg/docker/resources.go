@@ -0,0 +1,257 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+	"gopkg.in/yaml.v2"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// ResourceResolver provides the data of the Secrets and ConfigMaps referenced
+// by a function's envs and volumes, such that a local run sees the same
+// values as the deployed function.
+type ResourceResolver interface {
+	Secret(ctx context.Context, name string) (map[string][]byte, error)
+	ConfigMap(ctx context.Context, name string) (map[string][]byte, error)
+}
+
+// ResourceOverrides is a ResourceResolver reading Secrets and ConfigMaps from
+// a local file, for running functions without access to a cluster:
+//
+//	secrets:
+//	  db:
+//	    dsn: postgres://localhost/dev
+//	configMaps:
+//	  proxy-config:
+//	    proxy.yaml: |
+//	      listen: 9090
+type ResourceOverrides struct {
+	Secrets    map[string]map[string]string `yaml:"secrets"`
+	ConfigMaps map[string]map[string]string `yaml:"configMaps"`
+}
+
+// LoadResourceOverrides reads ResourceOverrides from the file at path.
+func LoadResourceOverrides(path string) (o ResourceOverrides, err error) {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return o, fmt.Errorf("cannot read resource overrides: %w", err)
+	}
+	if err = yaml.Unmarshal(bb, &o); err != nil {
+		return o, fmt.Errorf("cannot parse resource overrides %v: %w", path, err)
+	}
+	return
+}
+
+func (o ResourceOverrides) Secret(_ context.Context, name string) (map[string][]byte, error) {
+	return overrideData("Secret", name, o.Secrets)
+}
+
+func (o ResourceOverrides) ConfigMap(_ context.Context, name string) (map[string][]byte, error) {
+	return overrideData("ConfigMap", name, o.ConfigMaps)
+}
+
+func overrideData(kind, name string, resources map[string]map[string]string) (map[string][]byte, error) {
+	data, ok := resources[name]
+	if !ok {
+		return nil, fmt.Errorf("%v %q is not defined in the resource overrides", kind, name)
+	}
+	bb := make(map[string][]byte, len(data))
+	for k, v := range data {
+		bb[k] = []byte(v)
+	}
+	return bb, nil
+}
+
+// resourceRefPattern matches {{ secret:name[:key] }} and
+// {{ configMap:name[:key] }} env values.
+var resourceRefPattern = regexp.MustCompile(`^{{\s*(secret|configMap)\s*:\s*([-._a-zA-Z0-9]+)\s*(?::\s*([-._a-zA-Z0-9]+)\s*)?}}$`)
+
+// resolveEnvs returns the envs with references to Secrets and ConfigMaps
+// replaced by their values. References to the local environment are kept,
+// to be interpolated as for any run.
+func resolveEnvs(ctx context.Context, r ResourceResolver, envs []fn.Env) ([]fn.Env, error) {
+	resolved := make([]fn.Env, 0, len(envs))
+	for _, e := range envs {
+		if e.Value == nil {
+			resolved = append(resolved, e)
+			continue
+		}
+		m := resourceRefPattern.FindStringSubmatch(*e.Value)
+		if m == nil {
+			resolved = append(resolved, e)
+			continue
+		}
+		kind, name, key := m[1], m[2], m[3]
+		data, err := resourceData(ctx, r, kind, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Name == nil { // all keys of the resource, eg. {{ secret:name }}
+			keys := make([]string, 0, len(data))
+			for k := range data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				resolved = append(resolved, fn.Env{Name: ptr(k), Value: ptr(string(data[k]))})
+			}
+			continue
+		}
+		if key == "" {
+			return nil, fmt.Errorf("env %q references %v %q without a key", *e.Name, kind, name)
+		}
+		v, ok := data[key]
+		if !ok {
+			return nil, fmt.Errorf("env %q references key %q which is not present in %v %q", *e.Name, key, kind, name)
+		}
+		resolved = append(resolved, fn.Env{Name: e.Name, Value: ptr(string(v))})
+	}
+	return resolved, nil
+}
+
+// materializeVolumes writes the ConfigMap volumes of the function to a
+// directory, returning read-only bind mounts of them. The directory is
+// private to the current user, while the files within are readable by all
+// users of the container, as functions run as non-root users of their images.
+// It is to be removed once the container is gone. Secret volumes are instead
+// returned as a tar archive to be copied into the container before it starts,
+// such that Secret values are not written to the host. EmptyDir volumes are
+// mounted as tmpfs.
+func materializeVolumes(ctx context.Context, r ResourceResolver, volumes []fn.Volume) (mounts []mount.Mount, secrets []byte, dir string, err error) {
+	var (
+		buf bytes.Buffer
+		tw  *tar.Writer
+	)
+	for i, v := range volumes {
+		if v.Path == nil {
+			continue
+		}
+		var kind, name string
+		switch {
+		case v.Secret != nil:
+			kind, name = "secret", *v.Secret
+		case v.ConfigMap != nil:
+			kind, name = "configMap", *v.ConfigMap
+		case v.EmptyDir != nil:
+			mounts = append(mounts, mount.Mount{Type: mount.TypeTmpfs, Target: *v.Path})
+			continue
+		default:
+			continue // PersistentVolumeClaims have no local counterpart
+		}
+
+		data, err := resourceData(ctx, r, kind, name)
+		if err != nil {
+			return mounts, secrets, dir, err
+		}
+		for k := range data {
+			if !resourceKeyPattern.MatchString(k) || k == "." || k == ".." {
+				return mounts, secrets, dir, fmt.Errorf("%v %q has invalid key %q", kind, name, k)
+			}
+		}
+
+		if kind == "secret" {
+			if tw == nil {
+				tw = tar.NewWriter(&buf)
+			}
+			if err = writeSecretVolume(tw, *v.Path, data); err != nil {
+				return mounts, secrets, dir, err
+			}
+			continue
+		}
+
+		if dir == "" {
+			if dir, err = os.MkdirTemp("", "func-run-volumes-"); err != nil {
+				return mounts, secrets, dir, err
+			}
+		}
+		source := filepath.Join(dir, fmt.Sprintf("%d-%s-%s", i, kind, name))
+		if err = os.MkdirAll(source, 0o755); err != nil {
+			return mounts, secrets, dir, err
+		}
+		if err = os.Chmod(source, 0o755); err != nil { // regardless of umask
+			return mounts, secrets, dir, err
+		}
+		for k, bb := range data {
+			p := filepath.Join(source, k)
+			if err = os.WriteFile(p, bb, 0o644); err != nil {
+				return mounts, secrets, dir, err
+			}
+			if err = os.Chmod(p, 0o644); err != nil {
+				return mounts, secrets, dir, err
+			}
+		}
+		mounts = append(mounts, mount.Mount{Type: mount.TypeBind, Source: source, Target: *v.Path, ReadOnly: true})
+	}
+	if tw != nil {
+		if err = tw.Close(); err != nil {
+			return
+		}
+		secrets = buf.Bytes()
+	}
+	return
+}
+
+// resourceKeyPattern matches the keys of Secrets and ConfigMaps, which are
+// the names of the files of their volumes.
+var resourceKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// writeSecretVolume writes the keys of a Secret to the archive as files of
+// the directory at target, readable by all users of the container.
+func writeSecretVolume(tw *tar.Writer, target string, data map[string][]byte) error {
+	dir := strings.TrimPrefix(path.Clean(target), "/")
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: dir + "/", Mode: 0o755}); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		hdr := &tar.Header{Typeflag: tar.TypeReg, Name: dir + "/" + k, Mode: 0o444, Size: int64(len(data[k]))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resourceData(ctx context.Context, r ResourceResolver, kind, name string) (map[string][]byte, error) {
+	if kind == "secret" {
+		return r.Secret(ctx, name)
+	}
+	return r.ConfigMap(ctx, name)
+}
+
+// usesResources returns true if the function's envs or volumes reference
+// Secrets or ConfigMaps.
+func usesResources(f fn.Function) bool {
+	for _, e := range f.Run.Envs {
+		if e.Value != nil && resourceRefPattern.MatchString(*e.Value) {
+			return true
+		}
+	}
+	for _, v := range f.Run.Volumes {
+		if v.Secret != nil || v.ConfigMap != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func ptr(s string) *string { return &s }
@@ -0,0 +1,157 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/google/go-cmp/cmp"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+func testOverrides() ResourceOverrides {
+	return ResourceOverrides{
+		Secrets:    map[string]map[string]string{"db": {"dsn": "postgres://localhost/dev", "user": "alice"}},
+		ConfigMaps: map[string]map[string]string{"proxy-config": {"proxy.yaml": "listen: 9090"}},
+	}
+}
+
+func TestResolveEnvs(t *testing.T) {
+	envs := []fn.Env{
+		{Name: ptr("PLAIN"), Value: ptr("value")},
+		{Name: ptr("LOCAL"), Value: ptr("{{ env:HOME }}")},
+		{Name: ptr("DSN"), Value: ptr("{{ secret:db:dsn }}")},
+		{Value: ptr("{{ configMap:proxy-config }}")},
+	}
+	resolved, err := resolveEnvs(context.Background(), testOverrides(), envs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	for _, e := range resolved {
+		got[*e.Name] = *e.Value
+	}
+	expected := map[string]string{
+		"PLAIN":      "value",
+		"LOCAL":      "{{ env:HOME }}", // interpolated later, as for any run
+		"DSN":        "postgres://localhost/dev",
+		"proxy.yaml": "listen: 9090",
+	}
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("expected %v=%q, got %q", k, v, got[k])
+		}
+	}
+
+	// missing resources and keys are errors
+	for _, value := range []string{"{{ secret:missing:dsn }}", "{{ secret:db:missing }}"} {
+		if _, err = resolveEnvs(context.Background(), testOverrides(), []fn.Env{{Name: ptr("X"), Value: ptr(value)}}); err == nil {
+			t.Errorf("expected an error resolving %v", value)
+		}
+	}
+}
+
+func TestMaterializeVolumes(t *testing.T) {
+	volumes := []fn.Volume{
+		{Secret: ptr("db"), Path: ptr("/etc/db")},
+		{ConfigMap: ptr("proxy-config"), Path: ptr("/etc/proxy")},
+		{EmptyDir: &fn.EmptyDir{}, Path: ptr("/tmp/scratch")},
+	}
+	mounts, secrets, dir, err := materializeVolumes(context.Background(), testOverrides(), volumes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	if len(mounts) != 2 {
+		t.Fatalf("expected 2 mounts, got %v", mounts)
+	}
+	if m := mounts[0]; m.Type != mount.TypeBind || m.Target != "/etc/proxy" || !m.ReadOnly {
+		t.Errorf("unexpected config map mount %+v", m)
+	}
+	bb, err := os.ReadFile(filepath.Join(mounts[0].Source, "proxy.yaml"))
+	if err != nil || string(bb) != "listen: 9090" {
+		t.Errorf("config map key not materialized: %q, %v", bb, err)
+	}
+	if m := mounts[1]; m.Type != mount.TypeTmpfs || m.Target != "/tmp/scratch" {
+		t.Errorf("unexpected empty dir mount %+v", m)
+	}
+
+	// Secrets are archived to be copied into the container, not written to
+	// the host, readable by the non-root users as which functions run.
+	files := map[string]string{}
+	tr := tar.NewReader(bytes.NewReader(secrets))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		bb, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[fmt.Sprintf("%v %o", hdr.Name, hdr.Mode)] = string(bb)
+	}
+	expected := map[string]string{
+		"etc/db/ 755":     "",
+		"etc/db/dsn 444":  "postgres://localhost/dev",
+		"etc/db/user 444": "alice",
+	}
+	if diff := cmp.Diff(expected, files); diff != "" {
+		t.Errorf("unexpected secrets archive (-want, +got): %v", diff)
+	}
+
+	// The directory is private to the user, its volumes readable by the
+	// users of the container.
+	for path, mode := range map[string]os.FileMode{
+		dir:              0o700,
+		mounts[0].Source: 0o755,
+		filepath.Join(mounts[0].Source, "proxy.yaml"): 0o644,
+	} {
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != mode {
+			t.Errorf("expected %v to have mode %v, got %v", path, mode, fi.Mode().Perm())
+		}
+	}
+
+	// Keys are file names, which may not reach outside of the volume
+	for _, key := range []string{"../../.bashrc", "a/b", "..", "."} {
+		overrides := ResourceOverrides{ConfigMaps: map[string]map[string]string{"proxy-config": {key: "x"}}}
+		_, _, d, err := materializeVolumes(context.Background(), overrides, volumes[1:2])
+		os.RemoveAll(d)
+		if err == nil {
+			t.Errorf("expected an error for key %q", key)
+		}
+	}
+}
+
+func TestLoadResourceOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overrides.yaml")
+	data := "secrets:\n  db:\n    dsn: postgres://localhost/dev\nconfigMaps:\n  proxy-config:\n    proxy.yaml: 'listen: 9090'\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	o, err := LoadResourceOverrides(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret, err := o.Secret(context.Background(), "db"); err != nil || string(secret["dsn"]) != "postgres://localhost/dev" {
+		t.Errorf("unexpected secret %v, %v", secret, err)
+	}
+	if _, err := o.ConfigMap(context.Background(), "missing"); err == nil {
+		t.Error("expected an error for an undefined config map")
+	}
+}
@@ -1,15 +1,20 @@
 package docker
 
 import (
+	"bytes"
 	"context"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
@@ -39,20 +44,50 @@ func (e ErrNoImage) Error() string {
 	return "Function has no associated image. Has it been built?"
 }
 
-// Runner starts and stops functions as local containers.
+// Runner starts and stops functions as local containers.  Services of the
+// function are started with its first run and kept across its restarts, such
+// that their state survives them, until the runner is closed.
 type Runner struct {
-	verbose bool // Verbose logging
-	out     io.Writer
-	errOut  io.Writer
+	verbose  bool // Verbose logging
+	out      io.Writer
+	errOut   io.Writer
+	resolver ResourceResolver
+	debug    bool
+
+	mu       sync.Mutex
+	services *services // Services started by the runner, if any
+}
+
+type RunnerOpt func(*Runner)
+
+// WithDebug enables the debugger of the function's runtime, publishing its
+// port. Supported for runtimes whose debugger is part of the runtime itself:
+// Node (--inspect) and the JVM (JDWP).
+func WithDebug(debug bool) RunnerOpt {
+	return func(n *Runner) {
+		n.debug = debug
+	}
+}
+
+// WithResourceResolver resolves the Secrets and ConfigMaps referenced by the
+// function's envs and volumes, which are otherwise passed through as is.
+func WithResourceResolver(r ResourceResolver) RunnerOpt {
+	return func(n *Runner) {
+		n.resolver = r
+	}
 }
 
 // NewRunner creates an instance of a docker-backed runner.
-func NewRunner(verbose bool, out, errOut io.Writer) *Runner {
-	return &Runner{
+func NewRunner(verbose bool, out, errOut io.Writer, opts ...RunnerOpt) *Runner {
+	n := &Runner{
 		verbose: verbose,
 		out:     out,
 		errOut:  errOut,
 	}
+	for _, o := range opts {
+		o(n)
+	}
+	return n
 }
 
 // Run the function.
@@ -65,6 +100,13 @@ func (n *Runner) Run(ctx context.Context, f fn.Function, address string, startTi
 		id   string           // ID of running container
 		conn net.Conn         // Connection to container's stdio
 
+		dir       string        // Directory of materialized volumes
+		mounts    []mount.Mount // Volumes of the function
+		secrets   []byte        // Archive of Secret volumes to copy into the container
+		debugPort string        // Host port of the debugger, if debugging
+		svcs      *services     // Services of the function, if any
+		netID     string        // Network shared with the services, if any
+
 		// Channels for gathering runtime errors from the container instance
 		copyErrCh  = make(chan error, 10)
 		contBodyCh <-chan container.WaitResponse
@@ -92,9 +134,34 @@ func (n *Runner) Run(ctx context.Context, f fn.Function, address string, startTi
 	if c, _, err = NewClient(client.DefaultDockerHost); err != nil {
 		return job, errors.Wrap(err, "failed to create Docker API client")
 	}
-	if id, err = newContainer(ctx, c, f, host, port, n.verbose); err != nil {
+	if n.debug {
+		if f, err = withDebugEnv(f); err != nil {
+			return
+		}
+		debugPort = choosePort(host, fn.DefaultDebugPort(f.Runtime), DefaultDialTimeout)
+	}
+	if f, mounts, secrets, dir, err = n.resolve(ctx, f); err != nil {
+		return job, errors.Wrap(err, "runner unable to resolve secrets and config maps")
+	}
+	defer func() {
+		if err != nil && dir != "" {
+			_ = os.RemoveAll(dir)
+		}
+	}()
+	if len(f.Run.Services) > 0 {
+		if svcs, err = n.startServices(ctx, newServicesClient, f); err != nil {
+			return job, errors.Wrap(err, "runner unable to start services")
+		}
+		f, netID = withServiceEnvs(f), svcs.network
+	}
+	if id, err = newContainer(ctx, c, f, host, port, debugPort, netID, mounts, n.verbose); err != nil {
 		return job, errors.Wrap(err, "runner unable to create container")
 	}
+	if len(secrets) > 0 {
+		if err = c.CopyToContainer(ctx, id, "/", bytes.NewReader(secrets), container.CopyToContainerOptions{}); err != nil {
+			return job, errors.Wrap(err, "runner unable to copy secrets into container")
+		}
+	}
 	if conn, err = copyStdio(ctx, c, id, copyErrCh, n.out, n.errOut); err != nil {
 		return
 	}
@@ -150,28 +217,35 @@ func (n *Runner) Run(ctx context.Context, f fn.Function, address string, startTi
 	}
 
 	// Stopper
+	// Each resource is released even if releasing another fails.
 	stop := func() error {
 		var (
 			timeout = DefaultStopTimeout
 			ctx     = context.Background()
+			errs    []error
 		)
 		timeoutSecs := int(timeout.Seconds())
 		ctrStopOpts := container.StopOptions{
 			Timeout: &timeoutSecs,
 		}
-		if err = c.ContainerStop(ctx, id, ctrStopOpts); err != nil {
-			return fmt.Errorf("error stopping container %v: %v", id, err)
+		if err := c.ContainerStop(ctx, id, ctrStopOpts); err != nil {
+			errs = append(errs, fmt.Errorf("error stopping container %v: %v", id, err))
 		}
-		if err = c.ContainerRemove(ctx, id, container.RemoveOptions{}); err != nil {
-			return fmt.Errorf("error removing container %v: %v", id, err)
+		if err := c.ContainerRemove(ctx, id, container.RemoveOptions{Force: true}); err != nil {
+			errs = append(errs, fmt.Errorf("error removing container %v: %v", id, err))
 		}
-		if err = conn.Close(); err != nil {
-			return fmt.Errorf("error closing connection to container: %v", err)
+		if dir != "" {
+			if err := os.RemoveAll(dir); err != nil {
+				errs = append(errs, fmt.Errorf("error removing materialized volumes: %v", err))
+			}
+		}
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("error closing connection to container: %v", err))
 		}
-		if err = c.Close(); err != nil {
-			return fmt.Errorf("error closing daemon client: %v", err)
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("error closing daemon client: %v", err))
 		}
-		return nil
+		return stderrors.Join(errs...)
 	}
 
 	if startTimeout > 0 {
@@ -212,7 +286,71 @@ func (n *Runner) Run(ctx context.Context, f fn.Function, address string, startTi
 	}
 
 	// Job reporting port, runtime errors and provides a mechanism for stopping.
-	return fn.NewJob(f, host, port, runtimeErrCh, stop, n.verbose)
+	if job, err = fn.NewJob(f, host, port, runtimeErrCh, stop, n.verbose); err != nil {
+		return
+	}
+	job.DebugPort = debugPort
+	return
+}
+
+// CheckDebug returns an error if functions of the runtime can not be run
+// under their runtime's debugger in a container.
+func CheckDebug(runtime string) error {
+	_, err := debugEnv(runtime)
+	return err
+}
+
+// withDebugEnv returns the function with the environment enabling the
+// debugger of its runtime, listening on the runtime's default debug port.
+func withDebugEnv(f fn.Function) (fn.Function, error) {
+	env, err := debugEnv(f.Runtime)
+	if err != nil {
+		return f, err
+	}
+	f.Run.Envs = append(slices.Clone(f.Run.Envs), env)
+	return f, nil
+}
+
+// debugEnv returns the env enabling the debugger of the runtime.  Debuggers
+// which need to be installed into the image are not supported.
+func debugEnv(runtime string) (fn.Env, error) {
+	switch runtime {
+	case "node", "typescript":
+		return fn.Env{Name: ptr("NODE_OPTIONS"), Value: ptr("--inspect=0.0.0.0:" + fn.DefaultDebugPort(runtime))}, nil
+	case "springboot", "quarkus":
+		return fn.Env{Name: ptr("JAVA_TOOL_OPTIONS"), Value: ptr("-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:" + fn.DefaultDebugPort(runtime))}, nil
+	case "go", "python":
+		return fn.Env{}, fmt.Errorf("debugging %v functions requires the debugger in the image, which is not supported for containers. Run with --builder=host", runtime)
+	default:
+		return fn.Env{}, fmt.Errorf("debugging is not supported for the %q runtime", runtime)
+	}
+}
+
+// resolve the Secrets and ConfigMaps referenced by the function's envs and
+// volumes, returning the function with resolved envs, the mounts of its
+// volumes, the archive of its Secret volumes and the directory into which
+// the others were materialized, if any.
+func (n *Runner) resolve(ctx context.Context, f fn.Function) (fn.Function, []mount.Mount, []byte, string, error) {
+	if n.resolver == nil {
+		if usesResources(f) {
+			fmt.Fprintln(n.errOut, "Warning: Secrets and ConfigMaps referenced by the function are not resolved for this run")
+		}
+		return f, nil, nil, "", nil
+	}
+	envs, err := resolveEnvs(ctx, n.resolver, f.Run.Envs)
+	if err != nil {
+		return f, nil, nil, "", err
+	}
+	f.Run.Envs = envs
+
+	mounts, secrets, dir, err := materializeVolumes(ctx, n.resolver, f.Run.Volumes)
+	if err != nil {
+		if dir != "" {
+			_ = os.RemoveAll(dir)
+		}
+		return f, nil, nil, "", err
+	}
+	return f, mounts, secrets, dir, nil
 }
 
 // Dial the given (tcp) port on the given interface, returning an error if it is
@@ -254,7 +392,7 @@ func choosePort(host, preferredPort string, dialTimeout time.Duration) string {
 
 }
 
-func newContainer(ctx context.Context, c client.APIClient, f fn.Function, host, port string, verbose bool) (id string, err error) {
+func newContainer(ctx context.Context, c client.APIClient, f fn.Function, host, port, debugPort, networkID string, mounts []mount.Mount, verbose bool) (id string, err error) {
 	var (
 		containerCfg container.Config
 		hostCfg      container.HostConfig
@@ -265,6 +403,13 @@ func newContainer(ctx context.Context, c client.APIClient, f fn.Function, host,
 	if hostCfg, err = newHostConfig(host, port); err != nil {
 		return
 	}
+	hostCfg.Mounts = mounts
+	if networkID != "" {
+		hostCfg.NetworkMode = container.NetworkMode(networkID)
+	}
+	if debugPort != "" {
+		publishDebugPort(&containerCfg, &hostCfg, host, debugPort, fn.DefaultDebugPort(f.Runtime))
+	}
 	t, err := c.ContainerCreate(ctx, &containerCfg, &hostCfg, nil, nil, "")
 	if err != nil {
 		return
@@ -315,6 +460,13 @@ func newHostConfig(host, port string) (c container.HostConfig, err error) {
 	return container.HostConfig{PortBindings: ports}, nil
 }
 
+// publishDebugPort exposes the container's debug port on the given host port.
+func publishDebugPort(c *container.Config, h *container.HostConfig, host, hostPort, containerPort string) {
+	debugPort := nat.Port(containerPort + "/tcp")
+	c.ExposedPorts[debugPort] = struct{}{}
+	h.PortBindings[debugPort] = []nat.PortBinding{{HostIP: host, HostPort: hostPort}}
+}
+
 // copy stdin and stdout from the container of the given ID.  Errors encountered
 // during copy are communicated via a provided errs channel.
 func copyStdio(ctx context.Context, c client.APIClient, id string, errs chan error, out, errOut io.Writer) (conn net.Conn, err error) {
@@ -0,0 +1,176 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/random"
+)
+
+// services of a function run, started on a network shared with the
+// function's container, on which they are reachable by name.
+type services struct {
+	c       client.APIClient
+	network string   // ID of the shared network
+	ids     []string // IDs of the service containers
+}
+
+// startServices of the function with the runner's first run, returning those
+// already started by it on later runs.
+func (n *Runner) startServices(ctx context.Context, newClient func() (client.APIClient, error), f fn.Function) (*services, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.services != nil {
+		return n.services, nil
+	}
+	// The services outlive the runs, and therefore their Docker clients
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+	s, err := startServices(ctx, c, f, n.errOut, n.verbose)
+	if err != nil {
+		return nil, errors.Join(err, s.stop(context.Background()), c.Close())
+	}
+	n.services = s
+	return s, nil
+}
+
+// Close the runner, stopping and removing the services started by its runs.
+func (n *Runner) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.services == nil {
+		return nil
+	}
+	s := n.services
+	n.services = nil
+	return errors.Join(s.stop(context.Background()), s.c.Close())
+}
+
+func newServicesClient() (client.APIClient, error) {
+	c, _, err := NewClient(client.DefaultDockerHost)
+	return c, err
+}
+
+// startServices creates the network shared by the function and its services,
+// and starts the services on it.  On error, the services which were started
+// are to be stopped by the caller.
+func startServices(ctx context.Context, c client.APIClient, f fn.Function, errOut io.Writer, verbose bool) (s *services, err error) {
+	s = &services{c: c}
+
+	name := "func-" + f.Name + "-" + strings.ToLower(random.AlphaString(6))
+	res, err := c.NetworkCreate(ctx, name, network.CreateOptions{
+		Driver: "bridge",
+		Labels: map[string]string{"function.knative.dev/name": f.Name},
+	})
+	if err != nil {
+		return s, fmt.Errorf("cannot create network %v: %w", name, err)
+	}
+	s.network = res.ID
+	if verbose {
+		fmt.Fprintf(errOut, "Created network %v for services\n", name)
+	}
+
+	for _, svc := range f.Run.Services {
+		id, err := s.start(ctx, svc, errOut, verbose)
+		if err != nil {
+			return s, fmt.Errorf("cannot start service %q: %w", svc.Name, err)
+		}
+		s.ids = append(s.ids, id)
+	}
+	return s, nil
+}
+
+func (s *services) start(ctx context.Context, svc fn.Service, errOut io.Writer, verbose bool) (id string, err error) {
+	if err = pullMissing(ctx, s.c, svc.Image, errOut); err != nil {
+		return
+	}
+	envs, err := fn.Interpolate(svc.Envs)
+	if err != nil {
+		return
+	}
+	cfg := container.Config{
+		Image:      svc.Image,
+		Entrypoint: svc.Command,
+		Cmd:        svc.Args,
+	}
+	for k, v := range envs {
+		cfg.Env = append(cfg.Env, k+"="+v)
+	}
+	netCfg := network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			s.network: {Aliases: []string{svc.Name}},
+		},
+	}
+	hostCfg := container.HostConfig{NetworkMode: container.NetworkMode(s.network)}
+
+	t, err := s.c.ContainerCreate(ctx, &cfg, &hostCfg, &netCfg, nil, "")
+	if err != nil {
+		return
+	}
+	if err = s.c.ContainerStart(ctx, t.ID, container.StartOptions{}); err != nil {
+		_ = s.c.ContainerRemove(context.Background(), t.ID, container.RemoveOptions{Force: true})
+		return
+	}
+	if verbose {
+		fmt.Fprintf(errOut, "Started service %v (%v) as %.12s\n", svc.Name, svc.Image, t.ID)
+	}
+	return t.ID, nil
+}
+
+// stop and remove the services and their network.
+func (s *services) stop(ctx context.Context) error {
+	var errs []error
+	for _, id := range s.ids {
+		if err := s.c.ContainerRemove(ctx, id, container.RemoveOptions{Force: true}); err != nil {
+			errs = append(errs, fmt.Errorf("error removing service container %v: %v", id, err))
+		}
+	}
+	if s.network != "" {
+		if err := s.c.NetworkRemove(ctx, s.network); err != nil {
+			errs = append(errs, fmt.Errorf("error removing network %v: %v", s.network, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withServiceEnvs returns the function with the addresses of its services
+// injected as envs.  Envs of the function take precedence.
+func withServiceEnvs(f fn.Function) fn.Function {
+	envs := fn.Envs{}
+	for _, svc := range f.Run.Services {
+		envs = append(envs, svc.AddressEnvs()...)
+	}
+	f.Run.Envs = append(envs, f.Run.Envs...)
+	return f
+}
+
+// pullMissing pulls the image unless it is present.
+func pullMissing(ctx context.Context, c client.APIClient, ref string, errOut io.Writer) error {
+	_, err := c.ImageInspect(ctx, ref)
+	if err == nil {
+		return nil
+	}
+	if !cerrdefs.IsNotFound(err) {
+		return err
+	}
+	fmt.Fprintf(errOut, "Pulling %v\n", ref)
+	rc, err := c.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	_, err = io.Copy(io.Discard, rc)
+	return err
+}
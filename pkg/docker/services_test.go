@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// fakeServicesClient implements the parts of the Docker API used by
+// services, recording the calls made.
+type fakeServicesClient struct {
+	client.APIClient
+	calls   []string
+	created []*network.NetworkingConfig
+	images  []string // present images
+}
+
+func (c *fakeServicesClient) NetworkCreate(_ context.Context, name string, _ network.CreateOptions) (network.CreateResponse, error) {
+	c.calls = append(c.calls, "network create")
+	return network.CreateResponse{ID: "net"}, nil
+}
+
+func (c *fakeServicesClient) NetworkRemove(_ context.Context, id string) error {
+	c.calls = append(c.calls, "network remove "+id)
+	return nil
+}
+
+func (c *fakeServicesClient) ImageInspect(_ context.Context, ref string, _ ...client.ImageInspectOption) (image.InspectResponse, error) {
+	if slices.Contains(c.images, ref) {
+		return image.InspectResponse{}, nil
+	}
+	return image.InspectResponse{}, cerrdefs.ErrNotFound
+}
+
+func (c *fakeServicesClient) ImagePull(_ context.Context, ref string, _ image.PullOptions) (io.ReadCloser, error) {
+	c.calls = append(c.calls, "pull "+ref)
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (c *fakeServicesClient) ContainerCreate(_ context.Context, cfg *container.Config, _ *container.HostConfig, netCfg *network.NetworkingConfig, _ *v1.Platform, _ string) (container.CreateResponse, error) {
+	c.calls = append(c.calls, "create "+cfg.Image)
+	c.created = append(c.created, netCfg)
+	return container.CreateResponse{ID: cfg.Image + "-id"}, nil
+}
+
+func (c *fakeServicesClient) Close() error {
+	c.calls = append(c.calls, "close")
+	return nil
+}
+
+func (c *fakeServicesClient) ContainerStart(_ context.Context, id string, _ container.StartOptions) error {
+	c.calls = append(c.calls, "start "+id)
+	return nil
+}
+
+func (c *fakeServicesClient) ContainerRemove(_ context.Context, id string, _ container.RemoveOptions) error {
+	c.calls = append(c.calls, "remove "+id)
+	return nil
+}
+
+// TestServices ensures services are started on a shared network, reachable
+// by name, and removed together with the network.
+func TestServices(t *testing.T) {
+	f := fn.Function{Name: "f", Run: fn.RunSpec{Services: []fn.Service{
+		{Name: "redis", Image: "redis:7", Port: 6379},
+		{Name: "db", Image: "postgres:16", Port: 5432},
+	}}}
+	c := &fakeServicesClient{images: []string{"redis:7"}}
+
+	s, err := startServices(context.Background(), c, f, io.Discard, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"network create",
+		"create redis:7", "start redis:7-id",
+		"pull postgres:16", "create postgres:16", "start postgres:16-id",
+		"remove redis:7-id", "remove postgres:16-id", "network remove net",
+	}
+	if !slices.Equal(c.calls, want) {
+		t.Fatalf("unexpected calls\nwant %v\ngot  %v", want, c.calls)
+	}
+	if aliases := c.created[1].EndpointsConfig["net"].Aliases; !slices.Equal(aliases, []string{"db"}) {
+		t.Fatalf("expected the service to be reachable by name, got aliases %v", aliases)
+	}
+}
+
+// TestRunner_Services ensures the services started with a runner's first run
+// are kept across runs, such that restarts retain their data, until the
+// runner is closed.
+func TestRunner_Services(t *testing.T) {
+	f := fn.Function{Name: "f", Run: fn.RunSpec{Services: []fn.Service{
+		{Name: "redis", Image: "redis:7", Port: 6379},
+	}}}
+	c := &fakeServicesClient{images: []string{"redis:7"}}
+	newClient := func() (client.APIClient, error) { return c, nil }
+
+	n := NewRunner(false, io.Discard, io.Discard)
+	first, err := n.startServices(context.Background(), newClient, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := n.startServices(context.Background(), newClient, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatal("expected the services to be reused by later runs")
+	}
+	if err = n.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"network create", "create redis:7", "start redis:7-id",
+		"remove redis:7-id", "network remove net", "close",
+	}
+	if !slices.Equal(c.calls, want) {
+		t.Fatalf("unexpected calls\nwant %v\ngot  %v", want, c.calls)
+	}
+}
+
+// TestWithServiceEnvs ensures the addresses of services are injected, with
+// the function's own envs taking precedence.
+func TestWithServiceEnvs(t *testing.T) {
+	name, value := "REDIS_HOST", "cache.example.com"
+	f := fn.Function{Run: fn.RunSpec{
+		Services: []fn.Service{{Name: "redis", Image: "redis:7", Port: 6379}},
+		Envs:     fn.Envs{{Name: &name, Value: &value}},
+	}}
+	envs, err := fn.Interpolate(withServiceEnvs(f).Run.Envs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envs["REDIS_HOST"] != "cache.example.com" || envs["REDIS_ADDRESS"] != "redis:6379" {
+		t.Fatalf("unexpected envs %v", envs)
+	}
+}
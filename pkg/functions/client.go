@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -17,6 +18,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/authn"
 	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v2"
 	"knative.dev/func/pkg/utils"
@@ -63,12 +65,15 @@ type Platform struct {
 type Client struct {
 	repositoriesPath  string            // path to repositories
 	repositoriesURI   string            // repo URI (overrides repositories path)
+	keychain          authn.Keychain    // credentials for OCI template repositories
 	verbose           bool              // print verbose logs
+	invokeOutput      io.Writer         // verbose invocation output
 	scaffolder        Scaffolder        // Scaffolds a function to have main
 	builder           Builder           // Builds a runnable image source
 	pusher            Pusher            // Pushes function image to a remote
 	deployer          Deployer          // Deploys or Updates a function
 	runner            Runner            // Runs the function locally
+	tester            Tester            // Runs the function's test suite
 	removers          []Remover         // Removes remote services
 	listers           []Lister          // Lists remote services
 	describers        []Describer       // Describes function instances
@@ -82,6 +87,7 @@ type Client struct {
 	pipelinesProvider PipelinesProvider // CI/CD pipelines management
 	mcpServer         MCPServer         // MCP Server
 	startTimeout      time.Duration     // default start timeout for all runs
+	runDebug          bool              // run functions under a debugger
 }
 
 // Scaffolder wraps a function with a service scaffolding (entrypoint)
@@ -134,6 +140,15 @@ type Runner interface {
 	Run(context.Context, Function, string, time.Duration) (*Job, error)
 }
 
+// Tester runs the test suite of a function.
+type Tester interface {
+	// Test the function, passing the given additional arguments to the
+	// runtime's test tool and setting the given environment variables
+	// (in the form NAME=VALUE).  Test failures are reported via the returned
+	// TestResult; an error indicates the tests could not be run at all.
+	Test(ctx context.Context, f Function, args, env []string) (TestResult, error)
+}
+
 // Remover of deployed services.
 type Remover interface {
 	// Remove the function from remote.
@@ -237,11 +252,16 @@ func New(options ...Option) *Client {
 		mcpServer:         &noopMCPServer{},
 		transport:         http.DefaultTransport,
 		startTimeout:      DefaultStartTimeout,
+		keychain:          authn.DefaultKeychain,
+		invokeOutput:      os.Stdout,
 	}
 	c.runner = newDefaultRunner(c, os.Stdout, os.Stderr)
 	for _, o := range options {
 		o(c)
 	}
+	if c.tester == nil {
+		c.tester = NewTester(c.verbose, os.Stdout, os.Stderr)
+	}
 
 	// Initialize sub-managers using now-fully-initialized client.
 	c.repositories = newRepositories(c)
@@ -280,6 +300,14 @@ func WithVerbose(v bool) Option {
 	}
 }
 
+// WithInvokeOutput sets the writer of verbose invocation output, by default
+// stdout.
+func WithInvokeOutput(w io.Writer) Option {
+	return func(c *Client) {
+		c.invokeOutput = w
+	}
+}
+
 // WithScaffolder provides the implementation of a scaffolder based on builder
 func WithScaffolder(s Scaffolder) Option {
 	return func(c *Client) {
@@ -315,6 +343,13 @@ func WithRunner(r Runner) Option {
 	}
 }
 
+// WithTester provides the concrete implementation of a tester.
+func WithTester(t Tester) Option {
+	return func(c *Client) {
+		c.tester = t
+	}
+}
+
 // WithRemovers provides the concrete implementation of a remover.
 func WithRemovers(r ...Remover) Option {
 	return func(c *Client) {
@@ -354,6 +389,15 @@ func WithRepositoriesPath(path string) Option {
 	}
 }
 
+// WithRepositoriesKeychain sets the keychain from which credentials are
+// resolved when pulling template repositories distributed as OCI artifacts
+// (oci://).  By default, the docker config of the current user is consulted.
+func WithRepositoriesKeychain(kc authn.Keychain) Option {
+	return func(c *Client) {
+		c.keychain = kc
+	}
+}
+
 // WithRepository sets a specific URL to a Git repository from which to pull
 // templates.  This setting's existence precldes the use of either the inbuilt
 // templates or any repositories from the extensible repositories path.
@@ -428,6 +472,15 @@ func WithStartTimeout(t time.Duration) Option {
 	}
 }
 
+// WithRunDebug runs functions started by the default (host) runner under
+// the debugger of their runtime, listening on the Job's DebugPort: Delve for
+// Go and debugpy for Python.
+func WithRunDebug(debug bool) Option {
+	return func(c *Client) {
+		c.runDebug = debug
+	}
+}
+
 // ACCESSORS
 // ---------
 
@@ -1036,6 +1089,78 @@ func (c *Client) Run(ctx context.Context, f Function, options ...RunOption) (job
 	return job, nil
 }
 
+type TestOptions struct {
+	Integration bool
+	Address     string
+	Args        []string
+}
+
+type TestOption func(c *TestOptions)
+
+// TestWithIntegration instructs Test to first run the function (see Run),
+// exposing its address to the tests in the TestAddressEnv environment
+// variable, and to stop the function when the tests complete.
+func TestWithIntegration(integration bool) TestOption {
+	return func(c *TestOptions) {
+		c.Integration = integration
+	}
+}
+
+// TestWithAddress sets the address on which the function is run when
+// testing in integration mode.
+func TestWithAddress(address string) TestOption {
+	return func(c *TestOptions) {
+		c.Address = address
+	}
+}
+
+// TestWithArgs provides additional arguments to pass to the runtime's test
+// tool.
+func TestWithArgs(args []string) TestOption {
+	return func(c *TestOptions) {
+		c.Args = args
+	}
+}
+
+// Test the function by running its language-native test suite in its root.
+// When testing in integration mode, the function is run for the duration of
+// the tests, with its address available in the TestAddressEnv environment
+// variable.
+func (c *Client) Test(ctx context.Context, f Function, options ...TestOption) (result TestResult, err error) {
+	oo := TestOptions{}
+	for _, o := range options {
+		o(&oo)
+	}
+
+	if !f.Initialized() {
+		return result, NewErrNotInitialized(f.Root)
+	}
+
+	var (
+		env     []string
+		address string
+	)
+	if oo.Integration {
+		var job *Job
+		if job, err = c.Run(ctx, f, RunWithAddress(oo.Address)); err != nil {
+			return
+		}
+		defer func() {
+			if stopErr := job.Stop(); stopErr != nil {
+				fmt.Fprintf(os.Stderr, "warning: unable to stop function. %v\n", stopErr)
+			}
+		}()
+		address = fmt.Sprintf("http://%s", net.JoinHostPort(job.Host, job.Port))
+		env = append(env, TestAddressEnv+"="+address)
+	}
+
+	if result, err = c.tester.Test(ctx, f, oo.Args, env); err != nil {
+		return
+	}
+	result.Address = address
+	return
+}
+
 // Describe a function.  Name/Namespace takes precedence if provided.  If no
 // name/namespace is provided, the function passed is described based off of
 // its name and currently deployed namespace.
@@ -1205,12 +1330,20 @@ func (c *Client) Remove(ctx context.Context, name, namespace string, f Function,
 // their metadata.  For example HTTP vs CloudEvent
 func (c *Client) Invoke(ctx context.Context, root string, target string, m InvokeMessage) (metadata map[string][]string, body string, err error) {
 
+	resp, err := c.InvokeWithResponse(ctx, root, target, m)
+	return resp.Headers, resp.Body, err
+}
+
+// InvokeWithResponse invokes the function as does Invoke, returning the full
+// response including its status code.  For HTTP responses with an error
+// status code, both the response and an error are returned.
+func (c *Client) InvokeWithResponse(ctx context.Context, root string, target string, m InvokeMessage) (resp InvokeResponse, err error) {
 	f, err := NewFunction(root)
 	if err != nil {
 		return
 	}
 	// See invoke.go for implementation details
-	return invoke(ctx, c, f, target, m, c.verbose)
+	return invoke(ctx, c, f, target, m, c.invokeLog())
 }
 
 // Push the image for the named service to the configured registry
@@ -1359,7 +1492,7 @@ func Fingerprint(root string) (hash, log string, err error) {
 			return nil
 		}
 		// Always ignore .func, .git (TODO: .funcignore)
-		if info.IsDir() && (info.Name() == RunDataDir || info.Name() == ".git") {
+		if info.IsDir() && ignoredSourceDir(info.Name()) {
 			return filepath.SkipDir
 		}
 		fmt.Fprintf(h, "%v:%v:", path, info.ModTime().UnixNano())   // Write to the Hasher
@@ -1369,6 +1502,12 @@ func Fingerprint(root string) (hash, log string, err error) {
 	return fmt.Sprintf("%x", h.Sum(nil)), l.String(), err
 }
 
+// ignoredSourceDir returns true for directories which are not part of the
+// function's source, and thus neither fingerprinted nor watched.
+func ignoredSourceDir(name string) bool {
+	return name == RunDataDir || name == ".git"
+}
+
 // assertEmptyRoot ensures that the directory is empty enough to be used for
 // initializing a new function.
 func assertEmptyRoot(path string) (err error) {
@@ -2,12 +2,14 @@ package functions_test
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
@@ -19,6 +21,7 @@ import (
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
 	"knative.dev/func/pkg/builders"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/mock"
@@ -678,6 +681,73 @@ func TestClient_Run(t *testing.T) {
 	}
 }
 
+// TestClient_Test ensures that testing a function invokes the tester in the
+// function's root without running the function.
+func TestClient_Test(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	runner := mock.NewRunner()
+	tester := mock.NewTester()
+	client := fn.New(fn.WithRunner(runner), fn.WithTester(tester))
+
+	f, err := client.Init(fn.Function{Root: root, Runtime: TestRuntime})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.Test(t.Context(), f, fn.TestWithArgs([]string{"-v"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tester.TestInvoked {
+		t.Fatal("test did not invoke the tester")
+	}
+	if tester.RootRequested != root {
+		t.Fatalf("expected path '%v', got '%v'", root, tester.RootRequested)
+	}
+	if !reflect.DeepEqual(tester.ArgsRequested, []string{"-v"}) {
+		t.Fatalf("expected args [-v], got %v", tester.ArgsRequested)
+	}
+	if runner.RunInvoked {
+		t.Fatal("function should not be run outside of integration mode")
+	}
+	if !result.Passed || result.Address != "" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+// TestClient_Test_Integration ensures that testing in integration mode runs
+// the function and exposes its address to the tests.
+func TestClient_Test_Integration(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	runner := mock.NewRunner()
+	tester := mock.NewTester()
+	client := fn.New(fn.WithRunner(runner), fn.WithTester(tester))
+
+	f, err := client.Init(fn.Function{Root: root, Runtime: TestRuntime})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.Test(t.Context(), f, fn.TestWithIntegration(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !runner.RunInvoked {
+		t.Fatal("integration test did not run the function")
+	}
+	expected := fn.TestAddressEnv + "=http://127.0.0.1:8080"
+	if len(tester.EnvRequested) != 1 || tester.EnvRequested[0] != expected {
+		t.Fatalf("expected env %q, got %v", expected, tester.EnvRequested)
+	}
+	if result.Address != "http://127.0.0.1:8080" {
+		t.Fatalf("unexpected address %q", result.Address)
+	}
+}
+
 // TestClient_Runner ensures that the default internal runner correctly executes
 // a scaffolded function.
 func TestClient_Runner(t *testing.T) {
@@ -1824,6 +1894,196 @@ func TestClient_Invoke_HTTP(t *testing.T) {
 	}
 }
 
+// TestClient_InvokeLoad ensures that a load test repeatedly invokes the
+// function at an explicit target URL, reporting status codes and errors.
+func TestClient_InvokeLoad(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	var invocations int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&invocations, 1)%2 == 0 {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = res.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	client := fn.New()
+	if _, err := client.Init(fn.Function{Root: root, Runtime: TestRuntime}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := client.InvokeLoad(t.Context(), root, server.URL, fn.NewInvokeMessage(),
+		fn.LoadOptions{RPS: 100, Duration: 200 * time.Millisecond, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Requests == 0 || report.Requests != int(atomic.LoadInt32(&invocations)) {
+		t.Fatalf("expected %v requests reported, got %v", invocations, report.Requests)
+	}
+	if report.StatusCodes[200]+report.StatusCodes[503] != report.Requests {
+		t.Fatalf("unexpected status codes %v", report.StatusCodes)
+	}
+	if report.Errors != report.StatusCodes[503] {
+		t.Fatalf("expected %v errors, got %v", report.StatusCodes[503], report.Errors)
+	}
+	if report.Target != server.URL || report.Format != "http" {
+		t.Fatalf("unexpected target %q (%v)", report.Target, report.Format)
+	}
+}
+
+// TestClient_InvokeLoad_InvalidRate ensures that a load test is not started
+// with a rate whose interval between requests can not be represented.
+func TestClient_InvokeLoad_InvalidRate(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	client := fn.New()
+	if _, err := client.Init(fn.Function{Root: root, Runtime: TestRuntime}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rps := range []int{-1, int(time.Second) + 1} {
+		_, err := client.InvokeLoad(t.Context(), root, "http://localhost:1", fn.NewInvokeMessage(),
+			fn.LoadOptions{RPS: rps, Duration: 100 * time.Millisecond})
+		if err == nil {
+			t.Fatalf("expected an error for %v requests per second", rps)
+		}
+	}
+}
+
+// TestClient_Invoke_CloudEventModes ensures that CloudEvents are sent in the
+// requested content mode with their optional and extension attributes.
+func TestClient_Invoke_CloudEventModes(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	var received []cloudevents.Event
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var err error
+		if cehttp.IsHTTPBatch(req.Header) {
+			received, err = cehttp.NewEventsFromHTTPRequest(req)
+		} else if req.Header.Get("Content-Type") == cloudevents.ApplicationCloudEventsJSON {
+			var e *cloudevents.Event
+			if e, err = cehttp.NewEventFromHTTPRequest(req); err == nil {
+				received = []cloudevents.Event{*e}
+			}
+		} else {
+			err = fmt.Errorf("unexpected content type %q", req.Header.Get("Content-Type"))
+		}
+		if err != nil {
+			t.Error(err)
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		res.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	client := fn.New()
+	if _, err := client.Init(fn.Function{Root: root, Runtime: TestRuntime}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := fn.NewInvokeMessage()
+	m.Format = "cloudevent"
+	m.Mode = fn.CloudEventModeStructured
+	m.Subject = "orders/42"
+	m.DataSchema = "https://example.com/order.json"
+	m.Extensions = map[string]string{"partitionkey": "42"}
+	resp, err := client.InvokeWithResponse(t.Context(), root, server.URL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusAccepted || len(received) != 1 {
+		t.Fatalf("expected one structured event accepted, got %v (%v events)", resp.StatusCode, len(received))
+	}
+	e := received[0]
+	if e.ID() != m.ID || e.Subject() != m.Subject || e.DataSchema() != m.DataSchema {
+		t.Fatalf("unexpected event attributes %v", e)
+	}
+	if e.Extensions()["partitionkey"] != "42" {
+		t.Fatalf("expected extension partitionkey, got %v", e.Extensions())
+	}
+
+	// A batch with an event per element of the JSON array data
+	m.Mode = fn.CloudEventModeBatch
+	m.Data = []byte(`[{"n":1},{"n":2}]`)
+	if _, err = client.InvokeWithResponse(t.Context(), root, server.URL, m); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("expected a batch of 2 events, got %v", len(received))
+	}
+	for i, e := range received {
+		if e.ID() != fmt.Sprintf("%v-%d", m.ID, i+1) {
+			t.Fatalf("unexpected ID of event %d: %v", i, e.ID())
+		}
+		if string(e.Data()) != fmt.Sprintf(`{"n":%d}`, i+1) {
+			t.Fatalf("unexpected data of event %d: %s", i, e.Data())
+		}
+	}
+
+	// Verbose output lists each event of the batch on the given writer
+	out := &bytes.Buffer{}
+	verbose := fn.New(fn.WithVerbose(true), fn.WithInvokeOutput(out))
+	if _, err = verbose.InvokeWithResponse(t.Context(), root, server.URL, m); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 2 {
+		if !strings.Contains(out.String(), fmt.Sprintf("Event %d of 2\n", i+1)) {
+			t.Fatalf("expected event %d of the batch in the verbose output, got\n%s", i+1, out)
+		}
+	}
+
+	// A batch of no events is not sent
+	received = nil
+	m.Data = []byte(`[]`)
+	if _, err = client.InvokeWithResponse(t.Context(), root, server.URL, m); err == nil {
+		t.Fatal("expected an error sending an empty batch")
+	}
+	if received != nil {
+		t.Fatalf("expected no request for an empty batch, got %v events", len(received))
+	}
+
+	// Modes other than binary require the cloudevent format
+	m.Format = "http"
+	if _, err = client.InvokeWithResponse(t.Context(), root, server.URL, m); err == nil {
+		t.Fatal("expected an error sending a batch in http format")
+	}
+}
+
+// TestClient_Invoke_InvalidResponseEvent ensures that an invalid event
+// returned by a function is reported as an error.
+func TestClient_Invoke_InvalidResponseEvent(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		// A binary mode response event which lacks the required ID
+		res.Header().Set("Ce-Specversion", "1.0")
+		res.Header().Set("Ce-Source", "/test")
+		res.Header().Set("Ce-Type", "test")
+		res.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	client := fn.New()
+	if _, err := client.Init(fn.Function{Root: root, Runtime: TestRuntime}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := fn.NewInvokeMessage()
+	m.Format = "cloudevent"
+	_, err := client.InvokeWithResponse(t.Context(), root, server.URL, m)
+	if err == nil || !strings.Contains(err.Error(), "invalid response event") {
+		t.Fatalf("expected an invalid response event error, got %v", err)
+	}
+}
+
 // TestClient_Invoke_CloudEvent ensures that the client will attempt to invoke a
 // default CloudEvent function.  This also uses the HTTP protocol but asserts
 // the invoker is sending the invocation message as a CloudEvent rather than
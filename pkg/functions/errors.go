@@ -10,6 +10,7 @@ import (
 var (
 	ErrEnvironmentNotFound       = errors.New("environment not found")
 	ErrFunctionNotFound          = errors.New("function not found")
+	ErrLockNotFound              = errors.New("function has no lock file")
 	ErrMismatchedName            = errors.New("name passed the function source")
 	ErrNameRequired              = errors.New("name required")
 	ErrNamespaceRequired         = errors.New("namespace required")
@@ -22,6 +23,9 @@ var (
 	ErrRuntimeRequired           = errors.New("language runtime required")
 	ErrTemplateMissingRepository = errors.New("template name missing repository prefix")
 	ErrTemplateNotFound          = errors.New("template not found")
+	ErrTemplateNotVersioned      = errors.New("template not from a git repository or OCI artifact")
+	ErrTemplateParameterRequired = errors.New("template parameter required")
+	ErrUnknownTemplateParameter  = errors.New("unknown template parameter")
 	ErrTemplatesNotFound         = errors.New("templates path (runtimes) not found")
 	ErrContextCanceled           = errors.New("the operation was canceled")
 
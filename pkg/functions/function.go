@@ -77,6 +77,10 @@ type Function struct {
 	// Template for the function.
 	Template string `yaml:"-"`
 
+	// TemplateParameters are the values of the parameters declared by the
+	// template, rendered into the function's source when initialized.
+	TemplateParameters map[string]string `yaml:"-"`
+
 	// Registry at which to store interstitial containers, in the form
 	// [registry]/[user].
 	Registry string `yaml:"registry,omitempty"`
@@ -146,6 +150,10 @@ type BuildSpec struct {
 	// build (pack, s2i, etc)
 	Builder string `yaml:"builder,omitempty" jsonschema:"enum=pack,enum=s2i"`
 
+	// RemoteBuilder is the system which builds the function on the cluster
+	// when deployed remotely (tekton or shipwright).  Defaults to tekton.
+	RemoteBuilder string `yaml:"remoteBuilder,omitempty" jsonschema:"enum=tekton,enum=shipwright"`
+
 	// Build Env variables to be set
 	BuildEnvs Envs `yaml:"buildEnvs,omitempty"`
 
@@ -157,6 +165,10 @@ type BuildSpec struct {
 	// on-cluster during when built remotely.
 	RemoteStorageClass string `yaml:"remoteStorageClass,omitempty"`
 
+	// CacheImage is an image in which the pack builder stores its build cache
+	// when built remotely, in place of the function's cache volume.
+	CacheImage string `yaml:"cacheImage,omitempty"`
+
 	// Image stores last built image name NOT in func.yaml, but instead
 	// in .func/built-image
 	Image string `yaml:"-"`
@@ -188,6 +200,10 @@ type RunSpec struct {
 	// with containerized docker runner and deployed Knative service integration
 	// in development.
 	StartTimeout time.Duration `yaml:"startTimeout,omitempty"`
+
+	// Services are auxiliary containers, such as databases, started by the
+	// docker runner alongside the function on a shared network.
+	Services []Service `yaml:"services,omitempty"`
 }
 
 // DeploySpec
@@ -222,6 +238,16 @@ type DeploySpec struct {
 	Deployer string `yaml:"deployer,omitempty" jsonschema:"enum=knative,enum=raw,enum=keda"`
 
 	Subscriptions []KnativeSubscription `yaml:"subscriptions,omitempty"`
+
+	// SecurityContext overrides the default hardening profile of the
+	// function's pod and container.
+	SecurityContext *SecurityContext `yaml:"securityContext,omitempty"`
+
+	// InitContainers run to completion, in order, before the function starts.
+	InitContainers []Container `yaml:"initContainers,omitempty"`
+
+	// Sidecars are containers run alongside the function in its pod.
+	Sidecars []Container `yaml:"sidecars,omitempty"`
 }
 
 // HealthEndpoints specify the liveness and readiness endpoints for a Runtime
@@ -347,9 +373,13 @@ func (f Function) Validate() error {
 		validateVolumes(f.Run.Volumes),
 		ValidateBuildEnvs(f.Build.BuildEnvs),
 		ValidateEnvs(f.Run.Envs),
+		validateServices(f.Run.Services),
 		validateOptions(f.Deploy.Options),
+		validateSecurityContext(f.Deploy.SecurityContext),
+		validateContainers(f.Deploy),
 		ValidateLabels(f.Deploy.Labels),
 		validateGit(f.Build.Git),
+		validateRemoteBuilder(f.Build.RemoteBuilder),
 	}
 
 	var b strings.Builder
@@ -372,6 +402,20 @@ func (f Function) Validate() error {
 	return errors.New(b.String())
 }
 
+// Remote builders which run builds on the cluster (build.remoteBuilder)
+const (
+	RemoteBuilderTekton     = "tekton"
+	RemoteBuilderShipwright = "shipwright"
+)
+
+func validateRemoteBuilder(remoteBuilder string) (errors []string) {
+	switch remoteBuilder {
+	case "", RemoteBuilderTekton, RemoteBuilderShipwright:
+		return nil
+	}
+	return []string{fmt.Sprintf("build.remoteBuilder %q is not supported, supported remote builders are %q and %q", remoteBuilder, RemoteBuilderTekton, RemoteBuilderShipwright)}
+}
+
 var envPattern = regexp.MustCompile(`^{{\s*(\w+)\s*:(\w+)\s*}}$`)
 
 // Interpolate Env slice
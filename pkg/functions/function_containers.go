@@ -0,0 +1,111 @@
+package functions
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Container is an additional container of the function's pod. Init
+// containers run to completion before the function starts, for example to
+// run migrations or fetch configuration. Sidecars run alongside the
+// function, for example proxies or log shippers.
+//
+// Additional containers are only rendered by the deployers, `func run`
+// runs the function container alone.
+type Container struct {
+	// Name of the container, unique within the function's pod.
+	Name string `yaml:"name"`
+	// Image of the container.
+	Image string `yaml:"image"`
+	// Command overrides the entrypoint of the image.
+	Command []string `yaml:"command,omitempty"`
+	// Args are the arguments passed to the entrypoint.
+	Args []string `yaml:"args,omitempty"`
+	// Envs of the container, in the same format as the function's envs.
+	Envs Envs `yaml:"envs,omitempty"`
+	// Volumes mounted into the container, in the same format as the
+	// function's volumes.
+	Volumes []Volume `yaml:"volumes,omitempty"`
+	// Resources requested by and limited for the container.
+	Resources *ContainerResources `yaml:"resources,omitempty"`
+	// SecurityContext overrides the default hardening profile of the
+	// container. The pod level fsGroup is taken from the function.
+	SecurityContext *SecurityContext `yaml:"securityContext,omitempty"`
+}
+
+// ContainerResources of an additional container.
+type ContainerResources struct {
+	Requests *ResourcesRequestsOptions `yaml:"requests,omitempty"`
+	Limits   *ResourcesRequestsOptions `yaml:"limits,omitempty"`
+}
+
+// functionContainerName is the name of the container running the function
+// itself, which additional containers may not use.
+const functionContainerName = "user-container"
+
+// validateContainers checks that the function's init containers and sidecars
+// are correctly set.
+// Returns array of error messages, empty if no errors are found
+func validateContainers(deploy DeploySpec) (errors []string) {
+	names := map[string]bool{functionContainerName: true}
+
+	check := func(field string, cc []Container) {
+		for i, c := range cc {
+			entry := fmt.Sprintf("%s entry #%d (%s)", field, i, c.Name)
+			if c.Name == "" {
+				errors = append(errors, fmt.Sprintf("%s entry #%d is missing name field", field, i))
+			} else if msgs := validation.IsDNS1123Label(c.Name); len(msgs) > 0 {
+				errors = append(errors, fmt.Sprintf("%s has invalid name: %s", entry, msgs[0]))
+			} else if names[c.Name] {
+				errors = append(errors, fmt.Sprintf("%s uses a name which is already taken", entry))
+			}
+			names[c.Name] = true
+
+			if c.Image == "" {
+				errors = append(errors, fmt.Sprintf("%s is missing image field", entry))
+			}
+
+			for _, e := range ValidateEnvs(c.Envs) {
+				errors = append(errors, fmt.Sprintf("%s: %s", entry, e))
+			}
+			for _, e := range validateVolumes(c.Volumes) {
+				errors = append(errors, fmt.Sprintf("%s: %s", entry, e))
+			}
+			for _, e := range validateSecurityContext(c.SecurityContext) {
+				errors = append(errors, fmt.Sprintf("%s: %s", entry, e))
+			}
+
+			if c.Resources != nil {
+				for _, e := range validateQuantities("resources.requests", c.Resources.Requests) {
+					errors = append(errors, fmt.Sprintf("%s %s", entry, e))
+				}
+				for _, e := range validateQuantities("resources.limits", c.Resources.Limits) {
+					errors = append(errors, fmt.Sprintf("%s %s", entry, e))
+				}
+			}
+		}
+	}
+	check("initContainers", deploy.InitContainers)
+	check("sidecars", deploy.Sidecars)
+
+	return
+}
+
+func validateQuantities(field string, r *ResourcesRequestsOptions) (errors []string) {
+	if r == nil {
+		return
+	}
+	if r.CPU != nil {
+		if _, err := resource.ParseQuantity(*r.CPU); err != nil {
+			errors = append(errors, fmt.Sprintf("has invalid value for \"%s.cpu\": %s", field, *r.CPU))
+		}
+	}
+	if r.Memory != nil {
+		if _, err := resource.ParseQuantity(*r.Memory); err != nil {
+			errors = append(errors, fmt.Sprintf("has invalid value for \"%s.memory\": %s", field, *r.Memory))
+		}
+	}
+	return
+}
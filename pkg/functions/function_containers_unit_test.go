@@ -0,0 +1,75 @@
+package functions
+
+import (
+	"testing"
+
+	"knative.dev/pkg/ptr"
+)
+
+func Test_validateContainers(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		deploy DeploySpec
+		errs   int
+	}{
+		{
+			"correct containers",
+			DeploySpec{
+				InitContainers: []Container{{Name: "migrate", Image: "example.com/migrate", Args: []string{"up"}}},
+				Sidecars: []Container{{
+					Name:      "proxy",
+					Image:     "example.com/proxy",
+					Envs:      Envs{{Name: ptr.String("PORT"), Value: ptr.String("9090")}},
+					Volumes:   []Volume{{ConfigMap: ptr.String("proxy-config"), Path: ptr.String("/etc/proxy")}},
+					Resources: &ContainerResources{Limits: &ResourcesRequestsOptions{CPU: ptr.String("100m"), Memory: ptr.String("64Mi")}},
+				}},
+			},
+			0,
+		},
+		{
+			"missing name and image",
+			DeploySpec{
+				Sidecars: []Container{{}},
+			},
+			2,
+		},
+		{
+			"invalid name",
+			DeploySpec{
+				Sidecars: []Container{{Name: "Log_Shipper", Image: "example.com/shipper"}},
+			},
+			1,
+		},
+		{
+			"duplicate names",
+			DeploySpec{
+				InitContainers: []Container{{Name: "setup", Image: "example.com/setup"}},
+				Sidecars:       []Container{{Name: "setup", Image: "example.com/proxy"}, {Name: "user-container", Image: "example.com/proxy"}},
+			},
+			2,
+		},
+		{
+			"invalid envs, volumes and resources",
+			DeploySpec{
+				Sidecars: []Container{{
+					Name:      "proxy",
+					Image:     "example.com/proxy",
+					Envs:      Envs{{Name: ptr.String("1INVALID"), Value: ptr.String("x")}},
+					Volumes:   []Volume{{Secret: ptr.String("creds")}},
+					Resources: &ContainerResources{Requests: &ResourcesRequestsOptions{CPU: ptr.String("lots")}},
+				}},
+			},
+			3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateContainers(tt.deploy); len(got) != tt.errs {
+				t.Errorf("validateContainers() = %v\n got %d errors but want %d", got, len(got), tt.errs)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,96 @@
+package functions
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// SecurityContext overrides the hardening profile applied to the function's
+// pod and container. Fields which are not set keep the defaults: non-root,
+// no privilege escalation, all capabilities dropped and the RuntimeDefault
+// seccomp profile.
+type SecurityContext struct {
+	// RunAsUser is the UID the function's container runs as.
+	RunAsUser *int64 `yaml:"runAsUser,omitempty" jsonschema_extras:"minimum=0"`
+	// RunAsGroup is the GID the function's container runs as.
+	RunAsGroup *int64 `yaml:"runAsGroup,omitempty" jsonschema_extras:"minimum=0"`
+	// FSGroup is the group owning the volumes mounted into the pod.
+	FSGroup *int64 `yaml:"fsGroup,omitempty" jsonschema_extras:"minimum=0"`
+	// RunAsNonRoot requires the container to run as a non-root user.
+	RunAsNonRoot *bool `yaml:"runAsNonRoot,omitempty"`
+	// ReadOnlyRootFilesystem mounts the container's root filesystem read-only.
+	ReadOnlyRootFilesystem *bool `yaml:"readOnlyRootFilesystem,omitempty"`
+	// AllowPrivilegeEscalation allows a process to gain more privileges than
+	// its parent.
+	AllowPrivilegeEscalation *bool `yaml:"allowPrivilegeEscalation,omitempty"`
+	// SeccompProfile is the seccomp profile of the container.
+	SeccompProfile *SeccompProfile `yaml:"seccompProfile,omitempty"`
+	// Capabilities to add to or drop from the container.
+	Capabilities *Capabilities `yaml:"capabilities,omitempty"`
+}
+
+// SeccompProfile of a container.
+type SeccompProfile struct {
+	Type string `yaml:"type" jsonschema:"enum=RuntimeDefault,enum=Localhost,enum=Unconfined"`
+	// LocalhostProfile is the path of the profile on the node, relative to
+	// the kubelet's seccomp profile location. Required for type Localhost.
+	LocalhostProfile string `yaml:"localhostProfile,omitempty"`
+}
+
+// Capabilities are the POSIX capabilities added to or dropped from a
+// container, such as "NET_BIND_SERVICE" or "ALL".
+type Capabilities struct {
+	Add  []string `yaml:"add,omitempty"`
+	Drop []string `yaml:"drop,omitempty"`
+}
+
+var capabilityPattern = regexp.MustCompile(`^[A-Z][A-Z_]*$`)
+
+// validateSecurityContext checks that the input SecurityContext is correctly set.
+// Returns array of error messages, empty if no errors are found
+func validateSecurityContext(sc *SecurityContext) (errors []string) {
+	if sc == nil {
+		return
+	}
+
+	ids := []struct {
+		field string
+		value *int64
+	}{{"runAsUser", sc.RunAsUser}, {"runAsGroup", sc.RunAsGroup}, {"fsGroup", sc.FSGroup}}
+	for _, id := range ids {
+		if id.value != nil && *id.value < 0 {
+			errors = append(errors, fmt.Sprintf("securityContext field %q has invalid value set: %d, the value must not be negative", id.field, *id.value))
+		}
+	}
+
+	// runAsNonRoot defaults to true, so running as root must be explicit
+	if sc.RunAsUser != nil && *sc.RunAsUser == 0 && (sc.RunAsNonRoot == nil || *sc.RunAsNonRoot) {
+		errors = append(errors, "securityContext field \"runAsUser\" may only be 0 when \"runAsNonRoot\" is set to false")
+	}
+
+	if sc.SeccompProfile != nil {
+		switch sc.SeccompProfile.Type {
+		case "RuntimeDefault", "Unconfined":
+			if sc.SeccompProfile.LocalhostProfile != "" {
+				errors = append(errors, "securityContext field \"seccompProfile.localhostProfile\" may only be set for type \"Localhost\"")
+			}
+		case "Localhost":
+			if sc.SeccompProfile.LocalhostProfile == "" {
+				errors = append(errors, "securityContext field \"seccompProfile.localhostProfile\" is required for type \"Localhost\"")
+			}
+		default:
+			errors = append(errors, fmt.Sprintf("securityContext field \"seccompProfile.type\" has invalid value set: %q, allowed is only \"RuntimeDefault\", \"Localhost\" or \"Unconfined\"",
+				sc.SeccompProfile.Type))
+		}
+	}
+
+	if sc.Capabilities != nil {
+		for _, c := range append(append([]string{}, sc.Capabilities.Add...), sc.Capabilities.Drop...) {
+			if !capabilityPattern.MatchString(c) {
+				errors = append(errors, fmt.Sprintf("securityContext field \"capabilities\" has invalid capability %q, capabilities are upper case names such as \"NET_BIND_SERVICE\"", c))
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,95 @@
+package functions
+
+import (
+	"testing"
+
+	"knative.dev/pkg/ptr"
+)
+
+func Test_validateSecurityContext(t *testing.T) {
+
+	tests := []struct {
+		name string
+		sc   *SecurityContext
+		errs int
+	}{
+		{
+			"not set",
+			nil,
+			0,
+		},
+		{
+			"correct hardening profile",
+			&SecurityContext{
+				RunAsUser:              ptr.Int64(1001),
+				RunAsGroup:             ptr.Int64(0),
+				FSGroup:                ptr.Int64(1002),
+				ReadOnlyRootFilesystem: ptr.Bool(true),
+				SeccompProfile:         &SeccompProfile{Type: "Localhost", LocalhostProfile: "profiles/func.json"},
+				Capabilities:           &Capabilities{Add: []string{"NET_BIND_SERVICE"}, Drop: []string{"ALL"}},
+			},
+			0,
+		},
+		{
+			"negative ids",
+			&SecurityContext{
+				RunAsUser:  ptr.Int64(-1),
+				RunAsGroup: ptr.Int64(-1),
+				FSGroup:    ptr.Int64(-1),
+			},
+			3,
+		},
+		{
+			"root without disabling runAsNonRoot",
+			&SecurityContext{
+				RunAsUser: ptr.Int64(0),
+			},
+			1,
+		},
+		{
+			"root with runAsNonRoot disabled",
+			&SecurityContext{
+				RunAsUser:    ptr.Int64(0),
+				RunAsNonRoot: ptr.Bool(false),
+			},
+			0,
+		},
+		{
+			"incorrect 'seccompProfile.type'",
+			&SecurityContext{
+				SeccompProfile: &SeccompProfile{Type: "Strict"},
+			},
+			1,
+		},
+		{
+			"Localhost seccomp profile without a profile",
+			&SecurityContext{
+				SeccompProfile: &SeccompProfile{Type: "Localhost"},
+			},
+			1,
+		},
+		{
+			"profile set for RuntimeDefault seccomp profile",
+			&SecurityContext{
+				SeccompProfile: &SeccompProfile{Type: "RuntimeDefault", LocalhostProfile: "profiles/func.json"},
+			},
+			1,
+		},
+		{
+			"incorrect capabilities",
+			&SecurityContext{
+				Capabilities: &Capabilities{Add: []string{"net_raw"}, Drop: []string{""}},
+			},
+			2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateSecurityContext(tt.sc); len(got) != tt.errs {
+				t.Errorf("validateSecurityContext() = %v\n got %d errors but want %d", got, len(got), tt.errs)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,90 @@
+package functions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Service is an auxiliary container started by `func run` alongside the
+// function, such as a database or message broker standing in for a cloud
+// service during development.
+//
+// Services are started on a network shared with the function, on which they
+// are reachable by name, before the function is started, and are removed
+// once the run ends.  They are kept while the function is restarted, such as
+// when watching its source, such that their data is retained.  Their
+// addresses are injected into the function as envs prefixed by the service's
+// name in upper case, for example REDIS_HOST, REDIS_PORT and REDIS_ADDRESS
+// for a service "redis" on port 6379.
+type Service struct {
+	// Name of the service, its host name on the network shared with the
+	// function.
+	Name string `yaml:"name"`
+	// Image of the service.
+	Image string `yaml:"image"`
+	// Port the service listens on.
+	Port int `yaml:"port,omitempty"`
+	// Command overrides the entrypoint of the image.
+	Command []string `yaml:"command,omitempty"`
+	// Args are the arguments passed to the entrypoint.
+	Args []string `yaml:"args,omitempty"`
+	// Envs of the service, set directly from a value or from a local
+	// environment value.
+	Envs Envs `yaml:"envs,omitempty"`
+}
+
+// EnvPrefix of the envs holding the service's address.
+func (s Service) EnvPrefix() string {
+	return strings.ToUpper(strings.ReplaceAll(s.Name, "-", "_"))
+}
+
+// AddressEnvs returns the envs holding the address of the service on the
+// network shared with the function. Services without a port only have a
+// host.
+func (s Service) AddressEnvs() Envs {
+	prefix := s.EnvPrefix()
+	envs := Envs{newEnv(prefix+"_HOST", s.Name)}
+	if s.Port != 0 {
+		port := strconv.Itoa(s.Port)
+		envs = append(envs,
+			newEnv(prefix+"_PORT", port),
+			newEnv(prefix+"_ADDRESS", s.Name+":"+port))
+	}
+	return envs
+}
+
+func newEnv(name, value string) Env {
+	return Env{Name: &name, Value: &value}
+}
+
+// validateServices checks that the services of the function are correctly
+// set.
+// Returns array of error messages, empty if no errors are found
+func validateServices(services []Service) (errors []string) {
+	names := map[string]bool{}
+	for i, s := range services {
+		entry := fmt.Sprintf("run.services entry #%d (%s)", i, s.Name)
+		if s.Name == "" {
+			errors = append(errors, fmt.Sprintf("run.services entry #%d is missing name field", i))
+		} else if msgs := validation.IsDNS1123Label(s.Name); len(msgs) > 0 {
+			errors = append(errors, fmt.Sprintf("%s has invalid name: %s", entry, msgs[0]))
+		} else if names[s.Name] {
+			errors = append(errors, fmt.Sprintf("%s uses a name which is already taken", entry))
+		}
+		names[s.Name] = true
+
+		if s.Image == "" {
+			errors = append(errors, fmt.Sprintf("%s is missing image field", entry))
+		}
+		if s.Port < 0 || s.Port > 65535 {
+			errors = append(errors, fmt.Sprintf("%s has invalid port %d, must be between 1 and 65535", entry, s.Port))
+		}
+		for _, e := range ValidateEnvs(s.Envs) {
+			errors = append(errors, fmt.Sprintf("%s: %s", entry, e))
+		}
+	}
+	return
+}
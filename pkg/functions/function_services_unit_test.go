@@ -0,0 +1,77 @@
+package functions
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_validateServices(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		services []Service
+		errs     int
+	}{
+		{
+			"not set",
+			nil,
+			0,
+		},
+		{
+			"correct services",
+			[]Service{
+				{Name: "redis", Image: "redis:7", Port: 6379},
+				{Name: "db", Image: "postgres:16", Port: 5432, Envs: Envs{newEnv("POSTGRES_PASSWORD", "dev")}},
+				{Name: "mock", Image: "example.com/mock"},
+			},
+			0,
+		},
+		{
+			"missing name and image",
+			[]Service{{}},
+			2,
+		},
+		{
+			"invalid and duplicate names",
+			[]Service{
+				{Name: "Redis", Image: "redis:7"},
+				{Name: "db", Image: "postgres:16"},
+				{Name: "db", Image: "postgres:16"},
+			},
+			2,
+		},
+		{
+			"invalid port",
+			[]Service{{Name: "redis", Image: "redis:7", Port: 70000}},
+			1,
+		},
+		{
+			"invalid env",
+			[]Service{{Name: "redis", Image: "redis:7", Envs: Envs{newEnv("1NVALID", "x")}}},
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateServices(tt.services); len(got) != tt.errs {
+				t.Errorf("validateServices() = %v\n got %d errors but want %d", got, len(got), tt.errs)
+			}
+		})
+	}
+
+}
+
+func TestService_AddressEnvs(t *testing.T) {
+	got := Service{Name: "kafka-broker", Image: "kafka", Port: 9092}.AddressEnvs().Slice()
+	want := []string{"KAFKA_BROKER_HOST=kafka-broker", "KAFKA_BROKER_PORT=9092", "KAFKA_BROKER_ADDRESS=kafka-broker:9092"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected envs (-want, +got): %v", diff)
+	}
+
+	got = Service{Name: "mock", Image: "example.com/mock"}.AddressEnvs().Slice()
+	if diff := cmp.Diff([]string{"MOCK_HOST=mock"}, got); diff != "" {
+		t.Fatalf("unexpected envs of a service without port (-want, +got): %v", diff)
+	}
+}
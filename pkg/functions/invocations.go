@@ -0,0 +1,334 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// InvocationsFile is the file within a function's root in which its
+// collection of named invocations is stored.
+const InvocationsFile = "invocations.yaml"
+
+// ErrInvocationNotFound is returned when a named invocation does not exist
+// in the function's collection.
+var ErrInvocationNotFound = errors.New("invocation not found")
+
+// Invocations is a function's collection of named invocations, which can be
+// replayed against a running instance (see Client.Replay) to exercise the
+// function or as a smoke test after deployment.
+type Invocations struct {
+	Invocations []Invocation `yaml:"invocations"`
+}
+
+// Invocation is a named, recorded invocation of a function, along with the
+// assertions its response is expected to satisfy.
+type Invocation struct {
+	// Name of the invocation, unique within the collection.
+	Name string `yaml:"name"`
+	// Format of the message; 'http' or 'cloudevent'.  Defaults to the
+	// function's invoke format.
+	Format string `yaml:"format,omitempty"`
+	// RequestType (HTTP method) of the request.  Defaults to POST.
+	RequestType string `yaml:"requestType,omitempty"`
+	// Headers are additional HTTP headers sent with the request.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Path appended to the route of the function.
+	Path string `yaml:"path,omitempty"`
+	// Query parameters of the request.
+	Query map[string]string `yaml:"query,omitempty"`
+	// ID, Source and Type are CloudEvent attributes of the message.
+	ID     string `yaml:"id,omitempty"`
+	Source string `yaml:"source,omitempty"`
+	Type   string `yaml:"type,omitempty"`
+	// Subject and DataSchema are optional CloudEvent attributes of the message.
+	Subject    string `yaml:"subject,omitempty"`
+	DataSchema string `yaml:"dataSchema,omitempty"`
+	// Extensions are CloudEvent extension attributes of the message.
+	Extensions map[string]string `yaml:"extensions,omitempty"`
+	// Mode is the CloudEvent content mode; 'binary', 'structured' or 'batch'.
+	Mode string `yaml:"mode,omitempty"`
+	// ContentType of the data.
+	ContentType string `yaml:"contentType,omitempty"`
+	// Data is the body of the message.
+	Data string `yaml:"data,omitempty"`
+	// File, relative to the function's root, from which to read the body of
+	// the message.  Takes precedence over Data.
+	File string `yaml:"file,omitempty"`
+	// Expect are the assertions on the response.
+	Expect InvocationExpectation `yaml:"expect,omitempty"`
+}
+
+// InvocationExpectation are the assertions which a response must satisfy
+// for an invocation to pass.  With no assertions, an invocation passes if
+// the message was delivered and the response status is not an error.
+type InvocationExpectation struct {
+	// Status code of the response.
+	Status int `yaml:"status,omitempty"`
+	// Body is the exact expected body of the response.
+	Body string `yaml:"body,omitempty"`
+	// Contains are substrings which must all occur in the response body.
+	Contains []string `yaml:"contains,omitempty"`
+}
+
+// InvocationResult is the outcome of replaying a named invocation.
+type InvocationResult struct {
+	Name     string        `json:"name" yaml:"name"`
+	Passed   bool          `json:"passed" yaml:"passed"`
+	Status   int           `json:"status,omitempty" yaml:"status,omitempty"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
+	Body     string        `json:"body,omitempty" yaml:"body,omitempty"`
+	// Failures are the descriptions of each unmet expectation.
+	Failures []string `json:"failures,omitempty" yaml:"failures,omitempty"`
+}
+
+// LoadInvocations reads the collection of invocations of the function at
+// root.  A function with no collection yields an empty one.
+func LoadInvocations(root string) (ii Invocations, err error) {
+	bb, err := os.ReadFile(filepath.Join(root, InvocationsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return ii, nil
+	} else if err != nil {
+		return
+	}
+	if err = yaml.Unmarshal(bb, &ii); err != nil {
+		return ii, fmt.Errorf("unable to read %v. %w", InvocationsFile, err)
+	}
+	return ii, ii.Validate()
+}
+
+// Write the collection to the function at root.
+func (ii Invocations) Write(root string) error {
+	if err := ii.Validate(); err != nil {
+		return err
+	}
+	bb, err := yaml.Marshal(ii)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(root, InvocationsFile), bb, 0644)
+}
+
+// Validate that each invocation is named, uniquely.
+func (ii Invocations) Validate() error {
+	seen := map[string]bool{}
+	for i, inv := range ii.Invocations {
+		if inv.Name == "" {
+			return fmt.Errorf("invocation %d in %v has no name", i+1, InvocationsFile)
+		}
+		if seen[inv.Name] {
+			return fmt.Errorf("invocation %q is defined more than once in %v", inv.Name, InvocationsFile)
+		}
+		seen[inv.Name] = true
+	}
+	return nil
+}
+
+// Get the named invocation.
+func (ii Invocations) Get(name string) (Invocation, error) {
+	for _, inv := range ii.Invocations {
+		if inv.Name == name {
+			return inv, nil
+		}
+	}
+	return Invocation{}, fmt.Errorf("%w: %q", ErrInvocationNotFound, name)
+}
+
+// Set the invocation, replacing any existing invocation of the same name.
+func (ii *Invocations) Set(inv Invocation) {
+	for i := range ii.Invocations {
+		if ii.Invocations[i].Name == inv.Name {
+			ii.Invocations[i] = inv
+			return
+		}
+	}
+	ii.Invocations = append(ii.Invocations, inv)
+}
+
+// NewInvocation records the given message as a named invocation.
+func NewInvocation(name string, m InvokeMessage) Invocation {
+	inv := Invocation{
+		Name:        name,
+		Format:      m.Format,
+		RequestType: m.RequestType,
+		ID:          m.ID,
+		Source:      m.Source,
+		Type:        m.Type,
+		ContentType: m.ContentType,
+		Data:        string(m.Data),
+		Path:        m.Path,
+		Extensions:  m.Extensions,
+		Subject:     m.Subject,
+		DataSchema:  m.DataSchema,
+	}
+	if m.Mode != CloudEventModeBinary && m.Format != "http" {
+		inv.Mode = m.Mode // omitted when the default, or when not a CloudEvent
+	}
+	if len(m.Headers) > 0 {
+		inv.Headers = make(map[string]string, len(m.Headers))
+		for k, vv := range m.Headers {
+			inv.Headers[k] = strings.Join(vv, ",")
+		}
+	}
+	if len(m.Query) > 0 {
+		inv.Query = make(map[string]string, len(m.Query))
+		for k := range m.Query {
+			inv.Query[k] = m.Query.Get(k)
+		}
+	}
+	return inv
+}
+
+// readInvocationFile reads the named file relative to root.  Files outside of
+// root, including by way of symlinks, are not read.
+func readInvocationFile(root, name string) ([]byte, error) {
+	if filepath.IsAbs(name) {
+		return nil, fmt.Errorf("file %q must be relative to the function root", name)
+	}
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+	p, err := filepath.EvalSymlinks(filepath.Join(root, name))
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("file %q is outside the function root", name)
+	}
+	return os.ReadFile(p)
+}
+
+// Message returns the invocation as a message, with defaults applied for
+// any value not defined.  Files are read relative to the given root, and
+// may not be outside of it.
+func (inv Invocation) Message(root string) (InvokeMessage, error) {
+	m := NewInvokeMessage()
+	m.ID = inv.ID // empty generates a unique ID per request
+	m.Format = inv.Format
+	if inv.RequestType != "" {
+		m.RequestType = strings.ToUpper(inv.RequestType)
+	}
+	if inv.Source != "" {
+		m.Source = inv.Source
+	}
+	if inv.Type != "" {
+		m.Type = inv.Type
+	}
+	if inv.ContentType != "" {
+		m.ContentType = inv.ContentType
+	}
+	if inv.Data != "" {
+		m.Data = []byte(inv.Data)
+	}
+	if inv.File != "" {
+		data, err := readInvocationFile(root, inv.File)
+		if err != nil {
+			return m, fmt.Errorf("invocation %q: %w", inv.Name, err)
+		}
+		m.Data = data
+	}
+	if len(inv.Headers) > 0 {
+		m.Headers = http.Header{}
+		for k, v := range inv.Headers {
+			m.Headers.Set(k, v)
+		}
+	}
+	m.Path = inv.Path
+	if len(inv.Query) > 0 {
+		m.Query = url.Values{}
+		for k, v := range inv.Query {
+			m.Query.Set(k, v)
+		}
+	}
+	m.Extensions = inv.Extensions
+	m.Subject = inv.Subject
+	m.DataSchema = inv.DataSchema
+	m.Mode = inv.Mode
+	return m, nil
+}
+
+// check the response against the expectations of the invocation, returning
+// a description of each which is not met.
+func (e InvocationExpectation) check(status int, body string, err error) (failures []string) {
+	switch {
+	case err != nil && status == 0: // not delivered
+		failures = append(failures, err.Error())
+	case e.Status != 0 && status != e.Status:
+		failures = append(failures, fmt.Sprintf("expected status %d, got %d", e.Status, status))
+	case e.Status == 0 && err != nil: // error response not expected
+		failures = append(failures, err.Error())
+	}
+	if e.Body != "" && strings.TrimSpace(body) != strings.TrimSpace(e.Body) {
+		failures = append(failures, fmt.Sprintf("expected body %q, got %q", e.Body, body))
+	}
+	for _, s := range e.Contains {
+		if !strings.Contains(body, s) {
+			failures = append(failures, fmt.Sprintf("expected body to contain %q", s))
+		}
+	}
+	return
+}
+
+// Replay the named invocations from the collection of the function at root
+// against the target instance (see Invoke for target semantics).  If no
+// names are provided, all invocations in the collection are replayed in
+// order.  Unmet expectations are reported in the results rather than as an
+// error.
+func (c *Client) Replay(ctx context.Context, root string, target string, names ...string) (results []InvocationResult, err error) {
+	f, err := NewFunction(root)
+	if err != nil {
+		return
+	}
+	ii, err := LoadInvocations(root)
+	if err != nil {
+		return
+	}
+
+	selected := ii.Invocations
+	if len(names) > 0 {
+		selected = make([]Invocation, 0, len(names))
+		for _, name := range names {
+			inv, err := ii.Get(name)
+			if err != nil {
+				return nil, err
+			}
+			selected = append(selected, inv)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no invocations defined in %v", InvocationsFile)
+	}
+
+	for _, inv := range selected {
+		m, err := inv.Message(root)
+		if err != nil {
+			return results, err
+		}
+		route, format, m, err := prepareInvocation(ctx, c, f, target, m, c.invokeLog())
+		if err != nil {
+			return results, err
+		}
+		start := time.Now()
+		resp, sendErr := send(ctx, route, format, m, c.transport, c.invokeLog())
+		result := InvocationResult{
+			Name:     inv.Name,
+			Status:   resp.StatusCode,
+			Duration: time.Since(start),
+			Body:     resp.Body,
+			Failures: inv.Expect.check(resp.StatusCode, resp.Body, sendErr),
+		}
+		result.Passed = len(result.Failures) == 0
+		results = append(results, result)
+	}
+	return
+}
@@ -0,0 +1,218 @@
+package functions_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestInvocations_RoundTrip ensures that invocations are persisted to and
+// read from the function's invocations file.
+func TestInvocations_RoundTrip(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	ii, err := fn.LoadInvocations(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ii.Invocations) != 0 {
+		t.Fatalf("expected an empty collection, got %v", ii.Invocations)
+	}
+
+	m := fn.NewInvokeMessage()
+	m.Headers = http.Header{"X-Tenant": {"acme"}}
+	ii.Set(fn.NewInvocation("hello", m))
+	ii.Set(fn.Invocation{Name: "other"})
+	ii.Set(fn.Invocation{Name: "hello", Data: "replaced"})
+	if err = ii.Write(root); err != nil {
+		t.Fatal(err)
+	}
+
+	if ii, err = fn.LoadInvocations(root); err != nil {
+		t.Fatal(err)
+	}
+	if len(ii.Invocations) != 2 {
+		t.Fatalf("expected 2 invocations, got %v", len(ii.Invocations))
+	}
+	inv, err := ii.Get("hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Data != "replaced" {
+		t.Fatalf("expected invocation to be replaced, got %+v", inv)
+	}
+	if _, err = ii.Get("missing"); !errors.Is(err, fn.ErrInvocationNotFound) {
+		t.Fatalf("expected ErrInvocationNotFound, got %v", err)
+	}
+}
+
+// TestNewInvocation_Mode ensures that the CloudEvent content mode is only
+// recorded when it is not the default and the message may be a CloudEvent.
+func TestNewInvocation_Mode(t *testing.T) {
+	tests := []struct {
+		format, mode, expected string
+	}{
+		{"", fn.CloudEventModeBinary, ""},
+		{"http", fn.CloudEventModeStructured, ""},
+		{"cloudevent", fn.CloudEventModeBinary, ""},
+		{"cloudevent", fn.CloudEventModeStructured, fn.CloudEventModeStructured},
+		{"", fn.CloudEventModeBatch, fn.CloudEventModeBatch},
+	}
+	for _, test := range tests {
+		m := fn.NewInvokeMessage()
+		m.Format, m.Mode = test.format, test.mode
+		if inv := fn.NewInvocation("test", m); inv.Mode != test.expected {
+			t.Errorf("format %q with mode %q: expected mode %q, got %q", test.format, test.mode, test.expected, inv.Mode)
+		}
+	}
+}
+
+// TestInvocations_Validate ensures duplicate or unnamed invocations are
+// rejected.
+func TestInvocations_Validate(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	contents := "invocations:\n- name: a\n- name: a\n"
+	if err := os.WriteFile(filepath.Join(root, fn.InvocationsFile), []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fn.LoadInvocations(root); err == nil {
+		t.Fatal("expected an error for duplicate invocation names")
+	}
+	if err := (fn.Invocations{Invocations: []fn.Invocation{{}}}).Validate(); err == nil {
+		t.Fatal("expected an error for an unnamed invocation")
+	}
+}
+
+// TestInvocation_Message ensures an invocation yields a message with
+// defaults applied and data read from a file relative to the root.
+func TestInvocation_Message(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+	if err := os.WriteFile(filepath.Join(root, "payload.json"), []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inv := fn.Invocation{
+		Name:        "file",
+		RequestType: "get",
+		File:        "payload.json",
+		Headers:     map[string]string{"x-tenant": "acme"},
+	}
+	m, err := inv.Message(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.RequestType != "GET" {
+		t.Fatalf("expected request type GET, got %q", m.RequestType)
+	}
+	if string(m.Data) != `{"a":1}` {
+		t.Fatalf("expected data from file, got %q", m.Data)
+	}
+	if m.Source != fn.DefaultInvokeSource || m.ContentType != fn.DefaultInvokeContentType {
+		t.Fatalf("expected defaults to be applied, got %+v", m)
+	}
+	if m.Headers.Get("X-Tenant") != "acme" {
+		t.Fatalf("expected header to be set, got %v", m.Headers)
+	}
+}
+
+// TestInvocation_MessageFileOutsideRoot ensures that files outside of the
+// function root are not read as the data of an invocation.
+func TestInvocation_MessageFileOutsideRoot(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+	outside := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(outside, []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(root, outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{rel, outside, "link"} {
+		if _, err := (fn.Invocation{Name: "escape", File: file}).Message(root); err == nil {
+			t.Fatalf("expected file %q to be rejected", file)
+		}
+	}
+}
+
+// TestClient_Replay ensures that named invocations are replayed against the
+// target and their expectations asserted.
+func TestClient_Replay(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Tenant") != "acme" {
+			res.WriteHeader(http.StatusForbidden)
+			return
+		}
+		b, _ := io.ReadAll(req.Body)
+		_, _ = res.Write(b)
+	}))
+	t.Cleanup(server.Close)
+
+	client := fn.New()
+	if _, err := client.Init(fn.Function{Root: root, Runtime: TestRuntime}); err != nil {
+		t.Fatal(err)
+	}
+	ii := fn.Invocations{Invocations: []fn.Invocation{
+		{
+			Name:    "echo",
+			Data:    "hello",
+			Headers: map[string]string{"X-Tenant": "acme"},
+			Expect:  fn.InvocationExpectation{Status: 200, Body: "hello"},
+		},
+		{
+			Name:   "forbidden",
+			Expect: fn.InvocationExpectation{Status: 403},
+		},
+		{
+			Name:   "failing",
+			Data:   "hello",
+			Expect: fn.InvocationExpectation{Contains: []string{"hello"}},
+		},
+	}}
+	if err := ii.Write(root); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := client.Replay(t.Context(), root, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %v", len(results))
+	}
+	if !results[0].Passed || !results[1].Passed {
+		t.Fatalf("expected first two invocations to pass, got %+v", results[:2])
+	}
+	if results[2].Passed || len(results[2].Failures) != 2 {
+		t.Fatalf("expected last invocation to fail twice, got %+v", results[2])
+	}
+
+	// Replaying by name runs only the named invocations
+	if results, err = client.Replay(t.Context(), root, server.URL, "forbidden"); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Name != "forbidden" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if _, err = client.Replay(t.Context(), root, server.URL, "missing"); !errors.Is(err, fn.ErrInvocationNotFound) {
+		t.Fatalf("expected ErrInvocationNotFound, got %v", err)
+	}
+}
@@ -3,11 +3,14 @@ package functions
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
@@ -25,6 +28,24 @@ const (
 	DefaultInvokeFormat      = "http"
 )
 
+// CloudEvent content modes in which an invocation message can be sent when
+// using the 'cloudevent' format.
+const (
+	// CloudEventModeBinary sends the event's attributes as HTTP headers and
+	// its data as the request body.  This is the default.
+	CloudEventModeBinary = "binary"
+	// CloudEventModeStructured sends the entire event, attributes and data,
+	// as an application/cloudevents+json request body.
+	CloudEventModeStructured = "structured"
+	// CloudEventModeBatch sends one or more events as an
+	// application/cloudevents-batch+json request body.  Data which is a JSON
+	// array is sent as a batch of events, one per element.
+	CloudEventModeBatch = "batch"
+)
+
+// CloudEventModes are the supported CloudEvent content modes.
+var CloudEventModes = []string{CloudEventModeBinary, CloudEventModeStructured, CloudEventModeBatch}
+
 // InvokeMesage is the message used by the convenience method Invoke to provide
 // a simple way to trigger the execution of a function during development.
 type InvokeMessage struct {
@@ -33,8 +54,26 @@ type InvokeMessage struct {
 	Type        string
 	ContentType string
 	Data        []byte
-	RequestType string // HTTP Request GET/POST (defaults to POST)
-	Format      string // optional override for function-defined message format
+	RequestType string            // HTTP request method (defaults to POST)
+	Format      string            // optional override for function-defined message format
+	Headers     http.Header       // optional additional HTTP headers
+	Path        string            // optional path appended to the route
+	Query       url.Values        // optional query parameters
+	Extensions  map[string]string // optional CloudEvent extension attributes
+	Subject     string            // optional CloudEvent subject
+	DataSchema  string            // optional CloudEvent dataschema URI
+	Mode        string            // CloudEvent content mode (defaults to binary)
+}
+
+// InvokeResponse is the response of a function to an invocation.
+type InvokeResponse struct {
+	// StatusCode of the HTTP response.  Zero if no response was received.
+	StatusCode int `json:"statusCode" yaml:"statusCode"`
+	// Headers of the HTTP response.  For CloudEvents, the attributes of the
+	// response event are contained in the body.
+	Headers map[string][]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Body is a stringified version of the response payload.
+	Body string `json:"body" yaml:"body"`
 }
 
 // NewInvokeMessage creates a new InvokeMessage with fields populated
@@ -51,12 +90,34 @@ func NewInvokeMessage() InvokeMessage {
 }
 
 // invoke the function instance in the target environment with the
-// invocation message.  Returned is metadata (such as HTTP headers or
-// CloudEvent fields) and a stringified version of the payload.
-func invoke(ctx context.Context, c *Client, f Function, target string, m InvokeMessage, verbose bool) (metadata map[string][]string, body string, err error) {
+// invocation message.  Returned is the response, which includes metadata
+// (such as HTTP headers or CloudEvent fields) and a stringified version of
+// the payload.
+// invokeLog returns the writer of verbose invocation output, nil unless
+// verbose.
+func (c *Client) invokeLog() io.Writer {
+	if !c.verbose {
+		return nil
+	}
+	return c.invokeOutput
+}
+
+func invoke(ctx context.Context, c *Client, f Function, target string, m InvokeMessage, log io.Writer) (resp InvokeResponse, err error) {
+	route, format, m, err := prepareInvocation(ctx, c, f, target, m, log)
+	if err != nil {
+		return
+	}
+
+	return send(ctx, route, format, m, c.transport, log)
+}
+
+// prepareInvocation resolves the route of the target instance and the
+// message format to use when invoking the function, returning the message
+// with its defaults applied.
+func prepareInvocation(ctx context.Context, c *Client, f Function, target string, m InvokeMessage, log io.Writer) (route, format string, _ InvokeMessage, err error) {
 	// Get the first available route from 'local', 'remote', a named environment
 	// or treat target
-	route, err := invocationRoute(ctx, c, f, target) // choose instance to invoke
+	route, err = invocationRoute(ctx, c, f, target) // choose instance to invoke
 	if err != nil {
 		return
 	}
@@ -66,15 +127,16 @@ func invoke(ctx context.Context, c *Client, f Function, target string, m InvokeM
 	// function to use the new format if none is defined already (backwards
 	// compatibility fix) or b) always update the function, even if it was already
 	// set. Once decided, codify in a test.
-	format := DefaultInvokeFormat
+	format = DefaultInvokeFormat
 
-	// RequestType is expected GET or POST
+	// RequestType is the HTTP method, POST by default
 	if m.RequestType == "" {
 		m.RequestType = DefaultInvokeRequestType
 	}
+	m.RequestType = strings.ToUpper(m.RequestType)
 
-	if verbose {
-		fmt.Printf("Invoking '%v' function at %v\n", f.Invoke, route)
+	if log != nil {
+		fmt.Fprintf(log, "Invoking '%v' function at %v\n", f.Invoke, route)
 	}
 
 	if f.Invoke != "" {
@@ -84,27 +146,92 @@ func invoke(ctx context.Context, c *Client, f Function, target string, m InvokeM
 	if m.Format != "" {
 		// Use the override specified on the message if provided
 		format = m.Format
-		if verbose {
-			fmt.Printf("Invoking '%v' function using '%v' format\n", f.Invoke, m.Format)
+		if log != nil {
+			fmt.Fprintf(log, "Invoking '%v' function using '%v' format\n", f.Invoke, m.Format)
 		}
 	}
 
-	if m.RequestType != "POST" && m.RequestType != "GET" {
-		err = fmt.Errorf("http request type '%v' not supported, expected GET or POST", m.RequestType)
+	if !validMethod.MatchString(m.RequestType) {
+		err = fmt.Errorf("http request type '%v' is not a valid HTTP method", m.RequestType)
 		return
 	}
 
+	// Mode is the CloudEvent content mode, binary by default
+	if m.Mode == "" {
+		m.Mode = CloudEventModeBinary
+	}
+	switch m.Mode {
+	case CloudEventModeBinary:
+	case CloudEventModeStructured, CloudEventModeBatch:
+		if format != "cloudevent" {
+			err = fmt.Errorf("cloudevent mode '%v' requires the 'cloudevent' format", m.Mode)
+			return
+		}
+		if m.RequestType == "GET" {
+			err = fmt.Errorf("cloudevent mode '%v' requires a request type which has a body", m.Mode)
+			return
+		}
+	default:
+		err = fmt.Errorf("cloudevent mode '%v' not supported. Supported modes are %v", m.Mode, strings.Join(CloudEventModes, ", "))
+		return
+	}
+
+	route, err = invocationURL(route, m.Path, m.Query)
+	return route, format, m, err
+}
+
+// validMethod matches HTTP methods (RFC 9110 tokens, restricted to letters).
+var validMethod = regexp.MustCompile("^[A-Z]+$")
+
+// invocationURL returns the route with the given path appended and the
+// given query parameters added.
+func invocationURL(route, path string, query url.Values) (string, error) {
+	if path == "" && len(query) == 0 {
+		return route, nil
+	}
+	u, err := url.Parse(route)
+	if err != nil {
+		return "", fmt.Errorf("invalid route '%v'. %w", route, err)
+	}
+	if path != "" {
+		p, err := url.Parse(path)
+		if err != nil {
+			return "", fmt.Errorf("invalid path '%v'. %w", path, err)
+		}
+		u = u.JoinPath(p.Path)
+		if p.RawQuery != "" {
+			u.RawQuery = p.RawQuery
+		}
+	}
+	if len(query) > 0 {
+		q := u.Query()
+		for k, vv := range query {
+			for _, v := range vv {
+				q.Add(k, v)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), nil
+}
+
+// send a single message to the route in the given format ('http' or
+// 'cloudevent').  The response's status code is zero if no response was
+// received.
+func send(ctx context.Context, route, format string, m InvokeMessage, t http.RoundTripper, log io.Writer) (resp InvokeResponse, err error) {
 	switch format {
 	case "http":
-		return sendHttp(ctx, route, m, c.transport, verbose)
+		return sendHttp(ctx, route, m, t, log)
 	case "cloudevent":
-		switch m.RequestType {
-		case "POST":
-			body, err = sendEvent(ctx, route, m, c.transport, verbose)
-		case "GET":
+		switch {
+		case m.Mode == CloudEventModeBatch:
+			return sendBatch(ctx, route, m, t, log)
+		case m.RequestType == "GET":
 			// Construct a special CloudEvents GET request.
 			// This will be used most likely only for very special cases
-			body, err = sendGetEvent(ctx, route, m, c.transport, verbose)
+			return sendGetEvent(ctx, route, m, t, log)
+		default:
+			return sendEvent(ctx, route, m, t, log)
 		}
 	default:
 		err = fmt.Errorf("format '%v' not supported", format)
@@ -162,33 +289,44 @@ func invocationRoute(ctx context.Context, c *Client, f Function, target string)
 	}
 }
 
-// sendEvent to the route populated with data in the invoke message.
-func sendEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, verbose bool) (resp string, err error) {
-	event := cloudevents.NewEvent()
-	event.SetID(m.ID)
-	event.SetSource(m.Source)
-	event.SetType(m.Type)
+// sendEvent to the route populated with data in the invoke message, using
+// the message's request type as the HTTP method.
+func sendEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, log io.Writer) (resp InvokeResponse, err error) {
+	event, err := newEvent(m)
+	if err != nil {
+		return
+	}
 	err = event.SetData(m.ContentType, (m.Data))
 	if err != nil {
-		return "", fmt.Errorf("cannot set data: %w", err)
+		return resp, fmt.Errorf("cannot set data: %w", err)
 	}
 	c, err := cloudevents.NewClientHTTP(
 		cloudevents.WithTarget(route),
-		cloudevents.WithRoundTripper(t))
+		cloudevents.WithRoundTripper(t),
+		cehttp.WithMethod(m.RequestType))
 	if err != nil {
 		return
 	}
 
-	if verbose {
-		fmt.Printf("Sending event\n%v", event)
+	if log != nil {
+		fmt.Fprintf(log, "Sending event\n%v", event)
 		// note event's stringification already includes a trailing linebreak.
 	}
 
-	evt, result := c.Request(cloudevents.ContextWithTarget(ctx, route), event)
+	ctx = cloudevents.ContextWithTarget(ctx, route)
+	if m.Mode == CloudEventModeStructured {
+		ctx = cloudevents.WithEncodingStructured(ctx)
+	}
+	if len(m.Headers) > 0 {
+		ctx = cehttp.WithCustomHeader(ctx, m.Headers)
+	}
+	evt, result := c.Request(ctx, event)
+	resp.StatusCode = eventResultStatus(result)
 	if cloudevents.IsUndelivered(result) {
 		err = fmt.Errorf("unable to invoke: %v", result)
 	} else if evt != nil { // Check for nil in case no event is returned
-		resp = evt.String()
+		resp.Body = evt.String()
+		err = validateResponseEvent(*evt)
 	}
 
 	return
@@ -204,7 +342,7 @@ func sendEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundT
 // Since this is not the case for GET request, we need to specify custom protocol
 // and use a slightly different client resulting in a slightly different
 // function all together.
-func sendGetEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, verbose bool) (resp string, err error) {
+func sendGetEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, log io.Writer) (resp InvokeResponse, err error) {
 	if m.ID == "" {
 		// we're using a different Client function, we need to create an ID.
 		// ce.NewClientHTTP() sets ID if not present, ce.NewClient() doesn't
@@ -212,15 +350,15 @@ func sendGetEvent(ctx context.Context, route string, m InvokeMessage, t http.Rou
 	}
 
 	// construct the event
-	event := cloudevents.NewEvent()
-	event.SetID(m.ID)
-	event.SetSource(m.Source)
-	event.SetType(m.Type)
+	event, err := newEvent(m)
+	if err != nil {
+		return
+	}
 	event.SetDataContentType(m.ContentType)
 
-	if verbose {
-		fmt.Println("Constructing a GET request CloudEvent:")
-		fmt.Printf("Event: %+v\n", event)
+	if log != nil {
+		fmt.Fprintln(log, "Constructing a GET request CloudEvent:")
+		fmt.Fprintf(log, "Event: %+v\n", event)
 	}
 	// create http protocol with GET method
 	protocol, err := cehttp.New(cehttp.WithRoundTripper(t), cehttp.WithMethod("GET"))
@@ -233,27 +371,185 @@ func sendGetEvent(ctx context.Context, route string, m InvokeMessage, t http.Rou
 		return
 	}
 
-	if verbose {
-		fmt.Printf("Sending event\n%v", event)
+	if log != nil {
+		fmt.Fprintf(log, "Sending event\n%v", event)
 	}
 
-	evt, result := c.Request(cloudevents.ContextWithTarget(ctx, route), event)
+	ctx = cloudevents.ContextWithTarget(ctx, route)
+	if len(m.Headers) > 0 {
+		ctx = cehttp.WithCustomHeader(ctx, m.Headers)
+	}
+	evt, result := c.Request(ctx, event)
+	resp.StatusCode = eventResultStatus(result)
 	if cloudevents.IsUndelivered(result) {
 		err = fmt.Errorf("unable to invoke: %v", result)
 	} else if evt != nil { // Check for nil in case no event is returned
-		resp = evt.String()
+		resp.Body = evt.String()
+		err = validateResponseEvent(*evt)
 	}
 	return
 }
 
-// sendPost to the route populated with data in the invoke message.
-func sendHttp(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, verbose bool) (map[string][]string, string, error) {
+// sendBatch sends the invoke message as a batch of events in a single
+// application/cloudevents-batch+json request.  Data which is a JSON array
+// is sent as one event per element, otherwise the batch contains a single
+// event.  Each event's ID is derived from the message's ID.
+func sendBatch(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, log io.Writer) (resp InvokeResponse, err error) {
+	if m.ID == "" {
+		m.ID = uuid.NewString()
+	}
+	data := [][]byte{m.Data}
+	var elements []json.RawMessage
+	if strings.Contains(m.ContentType, "json") && json.Unmarshal(m.Data, &elements) == nil {
+		if len(elements) == 0 {
+			return resp, errors.New("cannot send an empty batch of events")
+		}
+		data = make([][]byte, len(elements))
+		for i, e := range elements {
+			data[i] = e
+		}
+	}
+
+	events := make([]cloudevents.Event, len(data))
+	for i, d := range data {
+		m := m
+		if len(data) > 1 {
+			m.ID = fmt.Sprintf("%v-%d", m.ID, i+1)
+		}
+		if events[i], err = newEvent(m); err != nil {
+			return
+		}
+		if err = events[i].SetData(m.ContentType, d); err != nil {
+			return resp, fmt.Errorf("cannot set data: %w", err)
+		}
+	}
+
+	req, err := cehttp.NewHTTPRequestFromEvents(ctx, route, events)
+	if err != nil {
+		return
+	}
+	req.Method = m.RequestType
+	for k, vv := range m.Headers {
+		for _, v := range vv {
+			req.Header.Add(k, v)
+		}
+	}
+
+	if log != nil {
+		fmt.Fprintf(log, "Sending batch of %d events\n", len(events))
+		for i, e := range events {
+			// note event's stringification already includes a trailing linebreak.
+			fmt.Fprintf(log, "Event %d of %d\n%v", i+1, len(events), e)
+		}
+	}
+
 	client := http.Client{
 		Transport: t,
 		Timeout:   time.Minute,
 	}
+	res, err := client.Do(req)
+	if err != nil {
+		return resp, fmt.Errorf("unable to invoke: %w", err)
+	}
+	defer res.Body.Close()
+
+	resp.StatusCode = res.StatusCode
+	resp.Headers = res.Header
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return
+	}
+	resp.Body = string(b)
+	if res.StatusCode > 299 {
+		return resp, fmt.Errorf("invalid status code: %v", res.StatusCode)
+	}
+
+	// A response with a body is expected to be an event (or batch of events)
+	if len(b) == 0 {
+		return
+	}
+	res.Body = io.NopCloser(bytes.NewReader(b))
+	var replies []cloudevents.Event
+	if cehttp.IsHTTPBatch(res.Header) {
+		replies, err = cehttp.NewEventsFromHTTPResponse(res)
+	} else {
+		var reply *cloudevents.Event
+		if reply, err = cehttp.NewEventFromHTTPResponse(res); err == nil {
+			replies = []cloudevents.Event{*reply}
+		}
+	}
+	if err != nil {
+		return resp, fmt.Errorf("response is not a cloudevent. %w", err)
+	}
+	var body strings.Builder
+	for _, reply := range replies {
+		body.WriteString(reply.String())
+		if err = validateResponseEvent(reply); err != nil {
+			break
+		}
+	}
+	resp.Body = body.String()
+	return
+}
 
-	if verbose {
+// newEvent returns an event with the attributes of the invoke message.
+func newEvent(m InvokeMessage) (cloudevents.Event, error) {
+	event := cloudevents.NewEvent()
+	event.SetID(m.ID)
+	event.SetSource(m.Source)
+	event.SetType(m.Type)
+	if m.Subject != "" {
+		event.SetSubject(m.Subject)
+	}
+	if m.DataSchema != "" {
+		if _, err := url.Parse(m.DataSchema); err != nil {
+			return event, fmt.Errorf("invalid dataschema '%v'. %w", m.DataSchema, err)
+		}
+		event.SetDataSchema(m.DataSchema)
+	}
+	if err := setExtensions(&event, m.Extensions); err != nil {
+		return event, err
+	}
+	return event, nil
+}
+
+// setExtensions sets the given extension attributes on the event.
+func setExtensions(event *cloudevents.Event, extensions map[string]string) error {
+	for k, v := range extensions {
+		if err := event.Context.SetExtension(k, v); err != nil {
+			return fmt.Errorf("invalid extension attribute '%v'. %w", k, err)
+		}
+	}
+	return nil
+}
+
+// validateResponseEvent returns an error if an event received in response
+// to an invocation is not a valid CloudEvent.
+func validateResponseEvent(e cloudevents.Event) error {
+	if err := e.Validate(); err != nil {
+		return fmt.Errorf("invalid response event. %w", err)
+	}
+	return nil
+}
+
+// eventResultStatus returns the HTTP status code of a CloudEvents request
+// result, or zero if the result carries none (for example when undelivered).
+func eventResultStatus(result error) int {
+	var httpResult *cehttp.Result
+	if cloudevents.ResultAs(result, &httpResult) {
+		return httpResult.StatusCode
+	}
+	return 0
+}
+
+// sendHttp to the route populated with data in the invoke message.
+func sendHttp(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, log io.Writer) (resp InvokeResponse, err error) {
+	client := http.Client{
+		Transport: t,
+		Timeout:   time.Minute,
+	}
+
+	if log != nil {
 		values := url.Values{
 			"ID":          {m.ID},
 			"Source":      {m.Source},
@@ -261,28 +557,36 @@ func sendHttp(ctx context.Context, route string, m InvokeMessage, t http.RoundTr
 			"ContentType": {m.ContentType},
 			"Data":        {string(m.Data)},
 		}
-		fmt.Println("Sending values")
+		fmt.Fprintln(log, "Sending values")
 		for k, v := range values {
-			fmt.Printf("  %v: %v\n", k, v[0]) // NOTE len==1 value slices assumed
+			fmt.Fprintf(log, "  %v: %v\n", k, v[0]) // NOTE len==1 value slices assumed
 		}
 	}
 
 	req, err := http.NewRequestWithContext(ctx, m.RequestType, route, bytes.NewReader(m.Data))
 	if err != nil {
-		return nil, "", fmt.Errorf("failure to create request: %w", err)
+		return resp, fmt.Errorf("failure to create request: %w", err)
 	}
 
-	req.Header.Add("Content-Type", m.ContentType)
+	for k, vv := range m.Headers {
+		for _, v := range vv {
+			req.Header.Add(k, v)
+		}
+	}
+	if req.Header.Get("Content-Type") == "" { // unless provided as a header
+		req.Header.Set("Content-Type", m.ContentType)
+	}
 
-	resp, err := client.Do(req)
+	res, err := client.Do(req)
 	if err != nil {
-		return nil, "", err
+		return
 	}
+	defer res.Body.Close()
 
-	defer resp.Body.Close()
-	if resp.StatusCode > 299 {
-		return nil, "", fmt.Errorf("failure invoking '%v' (HTTP %v)", route, resp.StatusCode)
+	b, err := io.ReadAll(res.Body)
+	resp = InvokeResponse{StatusCode: res.StatusCode, Headers: res.Header, Body: string(b)}
+	if res.StatusCode > 299 {
+		return resp, fmt.Errorf("failure invoking '%v' (HTTP %v)", route, res.StatusCode)
 	}
-	b, err := io.ReadAll(resp.Body)
-	return resp.Header, string(b), err
+	return
 }
@@ -0,0 +1,220 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLoadDuration is the duration of a load test when none is given.
+	DefaultLoadDuration = 10 * time.Second
+
+	// DefaultLoadConcurrency is the number of concurrent workers sending
+	// requests during a load test when none is given.
+	DefaultLoadConcurrency = 10
+)
+
+// LoadOptions configure a load test (see Client.InvokeLoad).
+type LoadOptions struct {
+	// RPS is the target number of requests per second across all workers.
+	// Zero sends requests as fast as the workers allow.
+	RPS int
+	// Duration for which requests are sent.
+	Duration time.Duration
+	// Concurrency is the number of workers sending requests concurrently,
+	// and therefore the maximum number of requests in flight.
+	Concurrency int
+}
+
+// LoadReport summarizes the results of a load test.
+type LoadReport struct {
+	Target      string        `json:"target" yaml:"target"`
+	Format      string        `json:"format" yaml:"format"`
+	Duration    time.Duration `json:"duration" yaml:"duration"`
+	Requests    int           `json:"requests" yaml:"requests"`
+	Errors      int           `json:"errors" yaml:"errors"`
+	ErrorRate   float64       `json:"errorRate" yaml:"errorRate"`
+	RPS         float64       `json:"rps" yaml:"rps"`
+	Latency     LoadLatency   `json:"latency" yaml:"latency"`
+	StatusCodes map[int]int   `json:"statusCodes" yaml:"statusCodes"`
+	// ErrorMessages is a histogram of the errors encountered, keyed by
+	// message.  Responses with a status code but no other error are counted
+	// in StatusCodes only.
+	ErrorMessages map[string]int `json:"errorMessages,omitempty" yaml:"errorMessages,omitempty"`
+}
+
+// LoadLatency percentiles of the requests sent during a load test.
+type LoadLatency struct {
+	Min  time.Duration `json:"min" yaml:"min"`
+	Mean time.Duration `json:"mean" yaml:"mean"`
+	P50  time.Duration `json:"p50" yaml:"p50"`
+	P90  time.Duration `json:"p90" yaml:"p90"`
+	P95  time.Duration `json:"p95" yaml:"p95"`
+	P99  time.Duration `json:"p99" yaml:"p99"`
+	Max  time.Duration `json:"max" yaml:"max"`
+}
+
+// loadSample is the outcome of a single request of a load test.
+type loadSample struct {
+	latency time.Duration
+	status  int
+	err     error
+}
+
+// InvokeLoad sends the invocation message repeatedly to the target instance
+// of the function at root for the duration given in the load options,
+// reporting latency percentiles, error rates and a histogram of response
+// status codes.  The target is resolved as with Invoke, and requests are
+// sent using the client's transport.
+func (c *Client) InvokeLoad(ctx context.Context, root string, target string, m InvokeMessage, o LoadOptions) (report LoadReport, err error) {
+	if o.RPS < 0 {
+		return report, errors.New("requests per second may not be negative")
+	}
+	if o.RPS > int(time.Second) {
+		return report, fmt.Errorf("requests per second may not exceed %v", int(time.Second))
+	}
+	if o.Duration == 0 {
+		o.Duration = DefaultLoadDuration
+	}
+	if o.Duration < 0 {
+		return report, errors.New("load test duration must be positive")
+	}
+	if o.Concurrency == 0 {
+		o.Concurrency = DefaultLoadConcurrency
+	}
+	if o.Concurrency < 0 {
+		return report, errors.New("load test concurrency must be positive")
+	}
+
+	f, err := NewFunction(root)
+	if err != nil {
+		return
+	}
+	route, format, m, err := prepareInvocation(ctx, c, f, target, m, c.invokeLog())
+	if err != nil {
+		return
+	}
+	if log := c.invokeLog(); log != nil {
+		fmt.Fprintf(log, "Load testing %v (%v) for %v with concurrency %v and rate %v/s\n",
+			route, format, o.Duration, o.Concurrency, o.RPS)
+	}
+
+	// Requests are issued until the load duration elapses.  Requests in
+	// flight at that time are allowed to complete.
+	loadCtx, cancel := context.WithTimeout(ctx, o.Duration)
+	defer cancel()
+
+	// Each value received on ticks permits a worker to send one request.
+	ticks := make(chan struct{})
+	go func() {
+		defer close(ticks)
+		var limiter <-chan time.Time
+		if o.RPS > 0 {
+			ticker := time.NewTicker(time.Second / time.Duration(o.RPS))
+			defer ticker.Stop()
+			limiter = ticker.C
+		}
+		for {
+			if limiter != nil {
+				select {
+				case <-limiter:
+				case <-loadCtx.Done():
+					return
+				}
+			}
+			select {
+			case ticks <- struct{}{}:
+			case <-loadCtx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		samples []loadSample
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		start   = time.Now()
+	)
+	for i := 0; i < o.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range ticks {
+				began := time.Now()
+				// Requests use the parent context such that the load duration
+				// elapsing does not cancel requests in flight.
+				resp, err := send(ctx, route, format, m, c.transport, nil)
+				s := loadSample{latency: time.Since(began), status: resp.StatusCode, err: err}
+				mu.Lock()
+				samples = append(samples, s)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report = newLoadReport(samples, time.Since(start))
+	report.Target = route
+	report.Format = format
+	return
+}
+
+// newLoadReport summarizes the given samples of a load test which ran for
+// the given duration.
+func newLoadReport(samples []loadSample, d time.Duration) LoadReport {
+	r := LoadReport{
+		Duration:      d,
+		Requests:      len(samples),
+		StatusCodes:   map[int]int{},
+		ErrorMessages: map[string]int{},
+	}
+	if len(samples) == 0 {
+		return r
+	}
+
+	latencies := make([]time.Duration, len(samples))
+	var total time.Duration
+	for i, s := range samples {
+		latencies[i] = s.latency
+		total += s.latency
+		if s.status != 0 {
+			r.StatusCodes[s.status]++
+		}
+		if s.err != nil || s.status > 299 {
+			r.Errors++
+		}
+		if s.err != nil && s.status < 300 {
+			r.ErrorMessages[s.err.Error()]++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	r.ErrorRate = float64(r.Errors) / float64(r.Requests)
+	if d > 0 {
+		r.RPS = float64(r.Requests) / d.Seconds()
+	}
+	r.Latency = LoadLatency{
+		Min:  latencies[0],
+		Mean: total / time.Duration(len(latencies)),
+		P50:  percentile(latencies, 50),
+		P90:  percentile(latencies, 90),
+		P95:  percentile(latencies, 95),
+		P99:  percentile(latencies, 99),
+		Max:  latencies[len(latencies)-1],
+	}
+	return r
+}
+
+// percentile returns the nearest-rank percentile p of the sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
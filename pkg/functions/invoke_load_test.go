@@ -0,0 +1,58 @@
+package functions
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestNewLoadReport ensures that load test samples are summarized into
+// latency percentiles, error rates and status code histograms.
+func TestNewLoadReport(t *testing.T) {
+	var samples []loadSample
+	for i := 1; i <= 100; i++ {
+		s := loadSample{latency: time.Duration(i) * time.Millisecond, status: 200}
+		if i%10 == 0 {
+			s.status = 503
+			s.err = errors.New("failure invoking (HTTP 503)")
+		}
+		samples = append(samples, s)
+	}
+	samples = append(samples, loadSample{latency: time.Second, err: errors.New("connection refused")})
+
+	r := newLoadReport(samples, 2*time.Second)
+
+	if r.Requests != 101 {
+		t.Fatalf("expected 101 requests, got %v", r.Requests)
+	}
+	if r.Errors != 11 {
+		t.Fatalf("expected 11 errors, got %v", r.Errors)
+	}
+	if r.StatusCodes[200] != 90 || r.StatusCodes[503] != 10 || len(r.StatusCodes) != 2 {
+		t.Fatalf("unexpected status codes %v", r.StatusCodes)
+	}
+	if r.ErrorMessages["connection refused"] != 1 || len(r.ErrorMessages) != 1 {
+		t.Fatalf("unexpected error messages %v", r.ErrorMessages)
+	}
+	if r.RPS != 50.5 {
+		t.Fatalf("expected 50.5 rps, got %v", r.RPS)
+	}
+	if r.Latency.Min != time.Millisecond || r.Latency.Max != time.Second {
+		t.Fatalf("unexpected min/max latency %v/%v", r.Latency.Min, r.Latency.Max)
+	}
+	if r.Latency.P50 != 51*time.Millisecond {
+		t.Fatalf("expected p50 of 51ms, got %v", r.Latency.P50)
+	}
+	if r.Latency.P99 != 100*time.Millisecond {
+		t.Fatalf("expected p99 of 100ms, got %v", r.Latency.P99)
+	}
+}
+
+// TestNewLoadReport_Empty ensures a load test which sent no requests yields
+// an empty report.
+func TestNewLoadReport_Empty(t *testing.T) {
+	r := newLoadReport(nil, time.Second)
+	if r.Requests != 0 || r.ErrorRate != 0 || r.Latency != (LoadLatency{}) {
+		t.Fatalf("unexpected report %+v", r)
+	}
+}
@@ -0,0 +1,36 @@
+package functions
+
+import (
+	"net/url"
+	"testing"
+)
+
+// TestInvocationURL ensures that request paths and query parameters are
+// applied relative to the function's route.
+func TestInvocationURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		route string
+		path  string
+		query url.Values
+		want  string
+	}{
+		{"route only", "http://localhost:8080", "", nil, "http://localhost:8080"},
+		{"path", "http://localhost:8080", "/orders/42", nil, "http://localhost:8080/orders/42"},
+		{"path without slash", "http://localhost:8080/", "orders", nil, "http://localhost:8080/orders"},
+		{"route with path", "https://f.example.com/api", "/orders", nil, "https://f.example.com/api/orders"},
+		{"query", "http://localhost:8080", "", url.Values{"a": {"1"}}, "http://localhost:8080?a=1"},
+		{"path with query", "http://localhost:8080", "/orders?a=1", url.Values{"b": {"2"}}, "http://localhost:8080/orders?a=1&b=2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := invocationURL(tt.route, tt.path, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
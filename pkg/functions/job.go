@@ -19,9 +19,12 @@ type Job struct {
 	Function Function
 	Host     string
 	Port     string
-	Errors   chan error
-	onStop   func() error
-	verbose  bool
+	// DebugPort on Host a debugger listens on, when the function was run
+	// with debugging enabled.
+	DebugPort string
+	Errors    chan error
+	onStop    func() error
+	verbose   bool
 }
 
 // Create a new Job which represents a running function task by providing
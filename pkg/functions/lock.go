@@ -0,0 +1,99 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LockFile records, alongside the function's func.yaml, the exact version of
+// the template from which the function was created.
+const LockFile = "func.lock"
+
+// Lock is the serialized form of a function's LockFile.
+type Lock struct {
+	// Template from which the function was created.
+	Template TemplateLock `yaml:"template"`
+}
+
+// TemplateLock identifies the version of a template.
+type TemplateLock struct {
+	// Repository is the name of the repository by which the template was
+	// referenced when the function was created.
+	Repository string `yaml:"repository"`
+
+	// URL of the git repository or OCI artifact of the template.  Empty for
+	// the embedded default repository.
+	URL string `yaml:"url,omitempty"`
+
+	// Ref is the branch, tag or commit to which the repository was pinned, or
+	// for OCI artifacts the tag or digest.
+	Ref string `yaml:"ref,omitempty"`
+
+	// Commit of the repository, or digest of the OCI artifact, from which the
+	// template was written.
+	Commit string `yaml:"commit,omitempty"`
+
+	// Runtime of the template.
+	Runtime string `yaml:"runtime"`
+
+	// Name of the template.
+	Name string `yaml:"name"`
+
+	// Path of the template within the repository.
+	Path string `yaml:"path,omitempty"`
+
+	// Parameters with which the template was rendered.
+	Parameters map[string]string `yaml:"parameters,omitempty"`
+}
+
+// Versioned returns true if the template can be retrieved at its locked
+// version, i.e. it was created from a git repository or OCI artifact.
+func (l TemplateLock) Versioned() bool {
+	return l.URL != "" && l.Commit != ""
+}
+
+// newTemplateLock returns the lock of a template of the given repository
+// rendered with the given parameters.
+func newTemplateLock(r Repository, t Template, params map[string]string) TemplateLock {
+	l := TemplateLock{
+		Repository: t.Repository(),
+		URL:        r.remote,
+		Ref:        r.ref,
+		Commit:     r.commit,
+		Runtime:    t.Runtime(),
+		Name:       t.Name(),
+		Parameters: params,
+	}
+	if r.remote != "" {
+		l.Path = r.templatePath(t.Runtime(), t.Name())
+	}
+	return l
+}
+
+// ReadLock reads the lock file of the function rooted at the given path.
+// ErrLockNotFound is returned if the function has none.
+func ReadLock(root string) (l Lock, err error) {
+	bb, err := os.ReadFile(filepath.Join(root, LockFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return l, ErrLockNotFound
+	} else if err != nil {
+		return
+	}
+	if err = yaml.Unmarshal(bb, &l); err != nil {
+		err = fmt.Errorf("cannot parse %v: %w", LockFile, err)
+	}
+	return
+}
+
+// Write the lock file to the function rooted at the given path.
+func (l Lock) Write(root string) error {
+	bb, err := yaml.Marshal(&l)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(root, LockFile), bb, 0644)
+}
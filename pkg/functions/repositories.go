@@ -1,11 +1,22 @@
 package functions
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
 
 const (
@@ -32,6 +43,11 @@ type Repositories struct {
 	// outputs.
 	remote string
 
+	// Keychain and transport with which repositories distributed as OCI
+	// artifacts are pulled.
+	keychain  authn.Keychain
+	transport http.RoundTripper
+
 	// backreference to the client enabling this repositories manager to
 	// have full API access.
 	client *Client
@@ -42,9 +58,21 @@ type Repositories struct {
 // full client API during implementations.
 func newRepositories(client *Client) *Repositories {
 	return &Repositories{
-		client: client,
-		path:   client.repositoriesPath,
-		remote: client.repositoriesURI,
+		client:    client,
+		path:      client.repositoriesPath,
+		remote:    client.repositoriesURI,
+		keychain:  client.keychain,
+		transport: client.transport,
+	}
+}
+
+// pullOptions with which repositories distributed as OCI artifacts are
+// pulled.
+func (r *Repositories) pullOptions(ctx context.Context) []remote.Option {
+	return []remote.Option{
+		remote.WithAuthFromKeychain(r.keychain),
+		remote.WithTransport(r.transport),
+		remote.WithContext(ctx),
 	}
 }
 
@@ -81,7 +109,7 @@ func (r *Repositories) All() (repos []Repository, err error) {
 	// Create a new repository from the remote URI, and set its name to
 	// the default so that it is treated as the default in place of the embedded.
 	if r.remote != "" {
-		if repo, err = NewRepository(DefaultRepositoryName, r.remote); err != nil {
+		if repo, err = newRepository(DefaultRepositoryName, r.remote, r.pullOptions(context.Background())...); err != nil {
 			return
 		}
 		repos = []Repository{repo}
@@ -160,14 +188,14 @@ func (r *Repositories) Get(name string) (repo Repository, err error) {
 // Add a repository of the given name from the URI.  Name, if not provided,
 // defaults to the repo name (sans optional .git suffix). Returns the final
 // name as added.
-func (r *Repositories) Add(name, uri string) (string, error) {
+func (r *Repositories) Add(ctx context.Context, name, uri string) (string, error) {
 	if r.path == "" {
 		return "", fmt.Errorf("repository %v(%v) not added. "+
 			"No repositories path provided", name, uri)
 	}
 
 	// Create a repo (in-memory FS) from the URI
-	repo, err := NewRepository(name, uri)
+	repo, err := newRepository(name, uri, r.pullOptions(ctx)...)
 	if err != nil {
 		return "", fmt.Errorf("failed to create new repository: %w", err)
 	}
@@ -187,6 +215,101 @@ func (r *Repositories) Add(name, uri string) (string, error) {
 	return repo.Name, nil
 }
 
+// Update an installed repository.  If a ref (branch, tag or commit; for OCI
+// artifacts a tag or digest) is provided, the repository is pinned to it.
+// Otherwise repositories pinned to a branch or to a tag which is not a semver
+// are updated to its latest commit or digest, and those pinned to a semver
+// tag to the highest released version.  Repositories pinned to a commit or
+// digest remain unchanged.  Returned is the repository as updated.
+func (r *Repositories) Update(ctx context.Context, name, ref string) (repo Repository, err error) {
+	if r.path == "" {
+		return repo, fmt.Errorf("repository %v not updated. "+
+			"No repositories path provided", name)
+	}
+	if name == "" {
+		return repo, errors.New("name is required")
+	}
+	dest := filepath.Join(r.path, name)
+	if _, err = os.Stat(dest); os.IsNotExist(err) {
+		return repo, ErrRepositoryNotFound
+	}
+	remote, current, _ := gitVersion(dest)
+	if remote == "" {
+		remote, current, _ = ociVersion(dest)
+	}
+	if remote == "" {
+		return repo, fmt.Errorf("repository '%v' has no remote from which to update", name)
+	}
+	if ref == "" {
+		if isOCIURI(remote) {
+			ref, err = latestOCIRef(remote, current, r.pullOptions(ctx)...)
+		} else {
+			ref, err = latestRef(remote, current)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	if repo, err = newRepository(name, pinnedURI(remote, ref), r.pullOptions(ctx)...); err != nil {
+		return repo, fmt.Errorf("failed to update repository: %w", err)
+	}
+
+	// Write the new version aside the installed one, which is replaced only
+	// once the new version was written in full.  Hidden, such that it is not
+	// loaded as a repository in the meantime.
+	tmp, err := os.MkdirTemp(r.path, "."+name+"-")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(tmp)
+	if err = repo.Write(filepath.Join(tmp, name)); err != nil {
+		return repo, fmt.Errorf("failed to write repository: %w", err)
+	}
+	if err = os.RemoveAll(dest); err != nil {
+		return
+	}
+	err = os.Rename(filepath.Join(tmp, name), dest)
+	return
+}
+
+// latestRef returns the ref to which a repository pinned to the given ref is
+// updated: the highest released version if pinned to a semver tag, otherwise
+// the ref itself.
+func latestRef(url, ref string) (string, error) {
+	current, err := semver.NewVersion(ref)
+	if err != nil {
+		return ref, nil // a branch or commit
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+	})
+	refs, err := remote.List(&git.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list refs of %v: %w", url, err)
+	}
+	if !slices.ContainsFunc(refs, func(r *plumbing.Reference) bool {
+		return r.Name() == plumbing.NewTagReferenceName(ref)
+	}) {
+		return ref, nil // a branch or commit named like a version
+	}
+
+	latest := ref
+	for _, r := range refs {
+		if !r.Name().IsTag() {
+			continue
+		}
+		v, err := semver.NewVersion(r.Name().Short())
+		if err != nil || v.Prerelease() != "" || !v.GreaterThan(current) {
+			continue
+		}
+		current, latest = v, r.Name().Short()
+	}
+	return latest, nil
+}
+
 // Rename a repository
 func (r *Repositories) Rename(from, to string) error {
 	if r.path == "" {
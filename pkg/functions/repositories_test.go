@@ -1,6 +1,7 @@
 package functions_test
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -85,7 +86,7 @@ func TestRepositories_All(t *testing.T) {
 	}
 
 	// Add one
-	_, err = client.Repositories().Add("", uri)
+	_, err = client.Repositories().Add(t.Context(), "", uri)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -117,7 +118,7 @@ func TestRepositories_Add(t *testing.T) {
 
 	// Add the repository, explicitly specifying a name.  See other tests for
 	// defaulting from repository names and manifest-defined name.
-	if _, err := client.Repositories().Add("example", uri); err != nil {
+	if _, err := client.Repositories().Add(t.Context(), "example", uri); err != nil {
 		t.Fatal(err)
 	}
 
@@ -152,7 +153,7 @@ func TestRepositories_AddDeafultName(t *testing.T) {
 
 	client := fn.New(fn.WithRepositoriesPath(root))
 
-	name, err := client.Repositories().Add("", uri)
+	name, err := client.Repositories().Add(t.Context(), "", uri)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -188,7 +189,7 @@ func TestRepositories_AddWithManifest(t *testing.T) {
 
 	client := fn.New(fn.WithRepositoriesPath(root))
 
-	name, err := client.Repositories().Add("", uri)
+	name, err := client.Repositories().Add(t.Context(), "", uri)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -224,10 +225,10 @@ func TestRepositories_AddExistingErrors(t *testing.T) {
 
 	// Add twice.
 	name := "example"
-	if _, err := client.Repositories().Add(name, uri); err != nil {
+	if _, err := client.Repositories().Add(t.Context(), name, uri); err != nil {
 		t.Fatal(err)
 	}
-	if _, err := client.Repositories().Add(name, uri); err == nil {
+	if _, err := client.Repositories().Add(t.Context(), name, uri); err == nil {
 		t.Fatalf("did not receive expected error adding an existing repository")
 	}
 
@@ -258,7 +259,7 @@ func TestRepositories_Rename(t *testing.T) {
 	client := fn.New(fn.WithRepositoriesPath(root))
 
 	// Add and Rename
-	if _, err := client.Repositories().Add("foo", uri); err != nil {
+	if _, err := client.Repositories().Add(t.Context(), "foo", uri); err != nil {
 		t.Fatal(err)
 	}
 	if err := client.Repositories().Rename("foo", "bar"); err != nil {
@@ -294,7 +295,7 @@ func TestRepositories_Remove(t *testing.T) {
 
 	// Add and Remove
 	name := "example"
-	if _, err := client.Repositories().Add(name, uri); err != nil {
+	if _, err := client.Repositories().Add(t.Context(), name, uri); err != nil {
 		t.Fatal(err)
 	}
 	if err := client.Repositories().Remove(name); err != nil {
@@ -327,7 +328,7 @@ func TestRepositories_URL(t *testing.T) {
 	client := fn.New(fn.WithRepositoriesPath(root))
 
 	// Add the test repo
-	_, err := client.Repositories().Add("newrepo", uri)
+	_, err := client.Repositories().Add(t.Context(), "newrepo", uri)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -391,3 +392,98 @@ func skipIfNoGit(t *testing.T) {
 		t.Skip("No 'git' found in path. Skipping test.")
 	}
 }
+
+// TestRepositories_AddPinned ensures that a repository can be added pinned
+// to a tag or a commit, which is then reflected by its ref, commit and URL.
+// Tags named like commits are resolved as tags.
+func TestRepositories_AddPinned(t *testing.T) {
+	skipIfNoGit(t) // see docs
+	uri, commits := ServeVersionedRepo(t)
+	root, rm := Mktemp(t)
+	defer rm()
+
+	client := fn.New(fn.WithRepositoriesPath(root))
+
+	tests := []struct {
+		name   string
+		ref    string
+		commit string
+	}{
+		{name: "latest", ref: "main", commit: commits[2]},
+		{name: "tag", ref: "v1.0.0", commit: commits[0]},
+		{name: "commit", ref: commits[1][:7], commit: commits[1]},
+		{name: "hex-tag", ref: "c0ffee1", commit: commits[0]},
+	}
+	for _, test := range tests {
+		if _, err := client.Repositories().Add(t.Context(), test.name, uri+"#"+test.ref); err != nil {
+			t.Fatal(err)
+		}
+		r, err := client.Repositories().Get(test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Ref() != test.ref {
+			t.Errorf("%v: expected ref %q, got %q", test.name, test.ref, r.Ref())
+		}
+		if r.Commit() != test.commit {
+			t.Errorf("%v: expected commit %q, got %q", test.name, test.commit, r.Commit())
+		}
+		if r.URL() != uri+"#"+test.ref {
+			t.Errorf("%v: expected URL %q, got %q", test.name, uri+"#"+test.ref, r.URL())
+		}
+	}
+}
+
+// TestRepositories_Update ensures that updating a repository moves it to the
+// latest version of what it is pinned to, or re-pins it to a given ref.
+func TestRepositories_Update(t *testing.T) {
+	skipIfNoGit(t) // see docs
+	uri, commits := ServeVersionedRepo(t)
+	root, rm := Mktemp(t)
+	defer rm()
+
+	client := fn.New(fn.WithRepositoriesPath(root))
+
+	update := func(name, ref, wantRef, wantCommit string) {
+		t.Helper()
+		if _, err := client.Repositories().Update(t.Context(), name, ref); err != nil {
+			t.Fatal(err)
+		}
+		r, err := client.Repositories().Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Ref() != wantRef || r.Commit() != wantCommit {
+			t.Fatalf("expected %v at %v@%v, got %v@%v", name, wantRef, wantCommit, r.Ref(), r.Commit())
+		}
+	}
+
+	// A semver tag is updated to the highest released version
+	if _, err := client.Repositories().Add(t.Context(), "tag", uri+"#v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	update("tag", "", "v1.1.0", commits[1])
+
+	// A commit remains unchanged
+	if _, err := client.Repositories().Add(t.Context(), "commit", uri+"#"+commits[0]); err != nil {
+		t.Fatal(err)
+	}
+	update("commit", "", commits[0], commits[0])
+
+	// An explicit ref re-pins, after which the branch is followed
+	update("commit", "main", "main", commits[2])
+	update("commit", "", "main", commits[2])
+
+	// No temporary files are left behind
+	ff, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ff) != 2 {
+		t.Fatalf("expected only the two repositories in %v, got %d entries", root, len(ff))
+	}
+
+	if _, err = client.Repositories().Update(t.Context(), "missing", ""); !errors.Is(err, fn.ErrRepositoryNotFound) {
+		t.Fatalf("expected ErrRepositoryNotFound, got %v", err)
+	}
+}
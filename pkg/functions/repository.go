@@ -7,12 +7,15 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"gopkg.in/yaml.v2"
 
 	"knative.dev/func/pkg/filesystem"
@@ -43,8 +46,11 @@ type Repository struct {
 
 	repoConfig // values defined via a manifest.yaml at root level.
 
-	fs  filesystem.Filesystem
-	uri string // populated on initial add
+	fs     filesystem.Filesystem
+	uri    string // populated on initial add
+	remote string // git URL or OCI artifact from which the repository was loaded, if any
+	ref    string // branch, tag or commit (git); tag or digest (OCI) to which pinned
+	commit string // commit or digest from which the repository's files were loaded
 }
 
 // Runtime contains templates
@@ -68,6 +74,9 @@ type repoConfig struct {
 	// repository manifest.yaml can define some default values for func.yaml
 	runtimeConfig `yaml:",inline"`
 
+	// SchemaVersion of the manifest.yaml.
+	SchemaVersion string `yaml:"schema_version,omitempty"`
+
 	// Name is either directory name on FS or last part of git URL or
 	// arbitrary value defined by the Template author or as indicated by the
 	// repository author via manifest.yaml.
@@ -110,6 +119,10 @@ type templateConfig struct {
 	// Invoke defines invocation hints for a functions which is created
 	// from this template prior to being materially modified.
 	Invoke string `yaml:"invoke,omitempty"`
+
+	// Parameters asked of the user creating a function from the template,
+	// rendered into the names and contents of its files.
+	Parameters []TemplateParameter `yaml:"parameters,omitempty"`
 }
 
 // NewRepository creates a repository instance from any of: a path on disk, a
@@ -120,6 +133,12 @@ type templateConfig struct {
 // uri (optional), the path either locally or remote from which to load
 // the repository files.  If not provided, the internal default is assumed.
 func NewRepository(name, uri string) (repo Repository, err error) {
+	return newRepository(name, uri, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+}
+
+// newRepository as NewRepository, pulling repositories distributed as OCI
+// artifacts with the given options.
+func newRepository(name, uri string, opts ...remote.Option) (repo Repository, err error) {
 	repo = Repository{uri: uri}
 
 	repo.Name, err = repositoryName(name, uri)
@@ -127,7 +146,7 @@ func NewRepository(name, uri string) (repo Repository, err error) {
 		return
 	}
 
-	repo.fs, err = filesystemFromURI(uri)
+	repo.fs, repo.remote, repo.ref, repo.commit, err = filesystemFromURI(uri, opts...)
 	if err != nil {
 		return Repository{}, fmt.Errorf("failed to get repository from URI (%q): %w", uri, err)
 	}
@@ -149,25 +168,59 @@ func (r Repository) FS() filesystem.Filesystem {
 // filesystemFromURI returns a filesystem from the data located at the
 // given URI.  If URI is not provided, indicates the embedded repo should
 // be loaded.  URI can be a remote git repository (http:// https:// etc.),
-// or a local file path (file://) which can be a git repo or a plain directory.
-func filesystemFromURI(uri string) (f filesystem.Filesystem, err error) {
+// a local file path (file://) which can be a git repo or a plain directory,
+// or an OCI artifact (oci://) pulled with the given options.
+// For git repositories, also returned are the remote URL, the ref to which
+// the repository is pinned and the commit from which the files were loaded.
+// For OCI artifacts, these are the artifact's repository, tag and digest.
+func filesystemFromURI(uri string, opts ...remote.Option) (f filesystem.Filesystem, remote, ref, commit string, err error) {
 	// If not provided, indicates embedded.
 	if uri == "" {
-		return EmbeddedTemplatesFS, nil
+		return EmbeddedTemplatesFS, "", "", "", nil
+	}
+
+	if isOCIURI(uri) {
+		return filesystemFromOCI(uri, opts...)
 	}
 
 	if isNonBareGitRepo(uri) {
-		return filesystemFromPath(uri)
+		remote, ref, commit = gitVersion(filepath.FromSlash(uri[7:]))
+		f, err = filesystemFromPath(uri)
+		return
+	}
+
+	// Repositories installed from OCI artifacts are plain directories
+	// recording the artifact from which they were installed.
+	if strings.HasPrefix(uri, "file://") {
+		if remote, ref, commit = ociVersion(filepath.FromSlash(uri[7:])); remote != "" {
+			f, err = filesystemFromPath(uri)
+			return
+		}
 	}
 
 	// Attempt to get a filesystem from the uri as a remote repo.
-	f, err = FilesystemFromRepo(uri)
-	if f != nil || err != nil {
-		return // found a filesystem and/or an error
+	clone, err := cloneInMemory(uri)
+	if err != nil {
+		return
+	}
+	if clone != nil {
+		wt, err := clone.Worktree()
+		if err != nil {
+			return nil, "", "", "", err
+		}
+		remote, ref = splitRef(uri)
+		if head, err := clone.Head(); err == nil {
+			commit = head.Hash().String()
+			if ref == "" {
+				ref = head.Name().Short()
+			}
+		}
+		return filesystem.NewBillyFilesystem(wt.Filesystem), remote, ref, commit, nil
 	}
 
 	// Attempt to get a filesystem from the uri as a file path.
-	return filesystemFromPath(uri)
+	f, err = filesystemFromPath(uri)
+	return
 }
 
 func isNonBareGitRepo(uri string) bool {
@@ -189,27 +242,115 @@ func isNonBareGitRepo(uri string) bool {
 // FilesystemFromRepo attempts to fetch a filesystem from a git repository
 // indicated by the given URI.  Returns nil if there is not a repo at the URI.
 func FilesystemFromRepo(uri string) (filesystem.Filesystem, error) {
-	clone, err := git.Clone(
-		memory.NewStorage(),
-		memfs.New(),
-		getGitCloneOptions(uri),
-	)
+	clone, err := cloneInMemory(uri)
+	if clone == nil || err != nil {
+		return nil, err
+	}
+	wt, err := clone.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	return filesystem.NewBillyFilesystem(wt.Filesystem), nil
+}
+
+// cloneInMemory clones the git repository indicated by the given URI into
+// memory.  Returns nil if there is not a repo at the URI.
+func cloneInMemory(uri string) (*git.Repository, error) {
+	clone, err := cloneAt(uri, func(o *git.CloneOptions) (*git.Repository, error) {
+		return git.Clone(memory.NewStorage(), memfs.New(), o)
+	})
 	if err != nil {
 		if isRepoNotFoundError(err) {
 			return nil, nil
 		}
 		if isBranchNotFoundError(err) {
-			return nil, fmt.Errorf("failed to clone repository: branch not found for uri %s", uri)
+			return nil, fmt.Errorf("failed to clone repository: branch or tag not found for uri %s", uri)
 		}
 		return nil, fmt.Errorf("failed to clone repository: %w", err)
 	}
-	wt, err := clone.Worktree()
+	return clone, nil
+}
+
+// cloneAt clones the repository at the URI, of the form url[#ref], where the
+// optional ref is a branch, a tag or a commit, using the given clone function.
+// Branches and tags are cloned shallowly.  Commits require a full clone, and
+// are only cloned if no branch or tag is named like the ref, such that tags
+// like 20240101 are not mistaken for commits.
+func cloneAt(uri string, clone func(*git.CloneOptions) (*git.Repository, error)) (*git.Repository, error) {
+	url, ref := splitRef(uri)
+	opts := &git.CloneOptions{URL: url, Depth: 1, Tags: git.NoTags,
+		RecurseSubmodules: git.NoRecurseSubmodules}
+	if ref == "" {
+		return clone(opts)
+	}
+
+	opts.ReferenceName = plumbing.NewBranchReferenceName(ref)
+	r, err := clone(opts)
+	if isBranchNotFoundError(err) {
+		opts.ReferenceName = plumbing.NewTagReferenceName(ref)
+		r, err = clone(opts)
+	}
+	if !isRefNotFoundError(err) || !isCommitRef(ref) {
+		return r, err
+	}
+
+	opts.ReferenceName, opts.Depth = "", 0
+	if r, err = clone(opts); err != nil {
+		return nil, err
+	}
+	hash, err := r.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("commit %v not found in %v: %w", ref, url, err)
+	}
+	wt, err := r.Worktree()
 	if err != nil {
 		return nil, err
 	}
-	return filesystem.NewBillyFilesystem(wt.Filesystem), nil
+	return r, wt.Checkout(&git.CheckoutOptions{Hash: *hash, Force: true})
 }
 
+// splitRef splits a repository URI of the form url[#ref] into its url and
+// optional ref.
+func splitRef(uri string) (url, ref string) {
+	url, ref, _ = strings.Cut(uri, "#")
+	return
+}
+
+// isCommitRef returns true if the ref may be an abbreviated or full commit
+// hash.  Branches and tags may be named alike.
+func isCommitRef(ref string) bool {
+	return commitRefRegexp.MatchString(ref)
+}
+
+var commitRefRegexp = regexp.MustCompile("^[0-9a-f]{7,40}$")
+
+// gitVersion returns the remote URL, pinned ref and current commit of a git
+// repository on disk.  Best effort; values which can not be determined are
+// returned empty.
+func gitVersion(path string) (remote, ref, commit string) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return
+	}
+	if c, err := repo.Config(); err == nil {
+		if origin, ok := c.Remotes["origin"]; ok && len(origin.URLs) > 0 {
+			remote = origin.URLs[0]
+		}
+		ref = c.Raw.Section(gitConfigSection).Option("ref")
+	}
+	if head, err := repo.Head(); err == nil {
+		commit = head.Hash().String()
+		if ref == "" {
+			ref = head.Name().Short()
+		}
+	}
+	return
+}
+
+// gitConfigSection of a repository's .git/config in which func records the
+// ref to which a repository on disk is pinned.
+const gitConfigSection = "func"
+
 // isRepoNotFoundError returns true if the error is a
 // "repository not found" error.
 func isRepoNotFoundError(err error) bool {
@@ -224,6 +365,12 @@ func isBranchNotFoundError(err error) bool {
 	return (err != nil && err.Error() == "reference not found")
 }
 
+// isRefNotFoundError returns true if the error is that of cloning a branch or
+// tag which does not exist.
+func isRefNotFoundError(err error) bool {
+	return isBranchNotFoundError(err) || errors.Is(err, git.NoMatchingRefSpecError{})
+}
+
 // filesystemFromPath attempts to return a filesystem from a URI as a file:// path
 func filesystemFromPath(uri string) (f filesystem.Filesystem, err error) {
 	parsed, err := url.Parse(uri)
@@ -344,6 +491,9 @@ func repositoryName(name, uri string) (string, error) {
 		return name, nil
 	}
 	// URI-derived is second precedence
+	if isOCIURI(uri) {
+		return ociRepositoryName(uri)
+	}
 	if uri != "" {
 		parsed, err := url.Parse(uri)
 		if err != nil {
@@ -438,22 +588,6 @@ func checkDir(fs filesystem.Filesystem, path string) error {
 	return err
 }
 
-func getGitCloneOptions(uri string) *git.CloneOptions {
-	branch := ""
-	splitUri := strings.Split(uri, "#")
-	if len(splitUri) > 1 {
-		uri = splitUri[0]
-		branch = splitUri[1]
-	}
-
-	opt := &git.CloneOptions{URL: uri, Depth: 1, Tags: git.NoTags,
-		RecurseSubmodules: git.NoRecurseSubmodules}
-	if branch != "" {
-		opt.ReferenceName = plumbing.NewBranchReferenceName(branch)
-	}
-	return opt
-}
-
 // Template from repo for given runtime.
 func (r *Repository) Template(runtimeName, name string) (t Template, err error) {
 	runtime, err := r.Runtime(runtimeName)
@@ -468,6 +602,11 @@ func (r *Repository) Template(runtimeName, name string) (t Template, err error)
 	return nil, ErrTemplateNotFound
 }
 
+// templatePath returns the path of a template within the repository.
+func (r *Repository) templatePath(runtimeName, name string) string {
+	return path.Join(r.TemplatesPath, runtimeName, name)
+}
+
 // Templates returns the set of all templates for a given runtime.
 // If runtime not found, an empty list is returned.
 func (r *Repository) Templates(runtimeName string) ([]Template, error) {
@@ -498,6 +637,15 @@ func (r *Repository) Write(dest string) (err error) {
 		return errors.New("the write operation is not supported on this repo")
 	}
 
+	// Repositories pulled from OCI artifacts are written as plain directories
+	// recording the artifact's digest.
+	if isOCIURI(r.uri) {
+		if err = filesystem.CopyFromFS(".", dest, r.fs); err != nil {
+			return
+		}
+		return r.writeOCISource(dest)
+	}
+
 	fs := r.fs // The FS to copy
 
 	// NOTE
@@ -519,10 +667,18 @@ func (r *Repository) Write(dest string) (err error) {
 		if tempDir, err = os.MkdirTemp("", "func"); err != nil {
 			return
 		}
-		if clone, err = git.PlainClone(tempDir, false, // not bare
-			getGitCloneOptions(r.uri)); err != nil {
+		if clone, err = cloneAt(r.uri, func(o *git.CloneOptions) (*git.Repository, error) {
+			r, err := git.PlainClone(tempDir, false, o) // not bare
+			if err != nil {
+				_ = os.RemoveAll(tempDir) // such that a subsequent attempt may clone
+			}
+			return r, err
+		}); err != nil {
 			return fmt.Errorf("failed to plain clone repository: %w", err)
 		}
+		if err = pinRef(clone, r.ref); err != nil {
+			return fmt.Errorf("failed to pin repository: %w", err)
+		}
 		if wt, err = clone.Worktree(); err != nil {
 			return fmt.Errorf("failed to get worktree: %w", err)
 		}
@@ -531,40 +687,39 @@ func (r *Repository) Write(dest string) (err error) {
 	return filesystem.CopyFromFS(".", dest, fs)
 }
 
-// URL attempts to read the remote git origin URL of the repository.  Best
-// effort; returns empty string if the repository is not a git repo or the repo
-// has been mutated beyond recognition on disk (ex: removing the origin remote)
+// URL returns the remote git URL of the repository including the ref to
+// which it is pinned as a fragment, or for OCI artifacts the reference of the
+// artifact.  Best effort; returns empty string if the repository is not a
+// git repo or OCI artifact, or the repo has been mutated beyond recognition on
+// disk (ex: removing the origin remote)
 func (r *Repository) URL() string {
-	uri := r.uri
+	return pinnedURI(r.remote, r.ref)
+}
 
-	// The default builtin repository is indicated by an empty URI.
-	// It has no remote URL, and without this check the current working directory
-	// would be checked.
-	if uri == "" {
-		return ""
-	}
+// Ref returns the branch, tag or commit to which the repository is pinned, or
+// for OCI artifacts the tag or digest.  Empty for repositories which are
+// neither, such as the embedded default.
+func (r *Repository) Ref() string {
+	return r.ref
+}
 
-	// git.PlainOpen does not seem to
-	if strings.HasPrefix(uri, "file://") {
-		uri = filepath.FromSlash(r.uri[7:])
-	}
+// Commit returns the commit from which the repository's templates were
+// loaded, or for OCI artifacts the digest of the artifact.  Empty for
+// repositories which are neither.
+func (r *Repository) Commit() string {
+	return r.commit
+}
 
-	repo, err := git.PlainOpen(uri)
-	if err != nil {
-		return "" // not a git repository
+// pinRef records the ref to which a repository on disk is pinned in its git
+// config, such that it is retained by subsequent updates.
+func pinRef(repo *git.Repository, ref string) error {
+	if ref == "" {
+		return nil
 	}
-
 	c, err := repo.Config()
 	if err != nil {
-		return "" // Has no .git/config or other error.
-	}
-
-	ref, _ := repo.Head()
-	if _, ok := c.Remotes["origin"]; ok {
-		urls := c.Remotes["origin"].URLs
-		if len(urls) > 0 {
-			return urls[0] + "#" + ref.Name().Short()
-		}
+		return err
 	}
-	return ""
+	c.Raw.Section(gitConfigSection).SetOption("ref", ref)
+	return repo.SetConfig(c)
 }
@@ -0,0 +1,253 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/filesystem"
+	"knative.dev/func/pkg/scaffolding"
+)
+
+// invokeFormats which a template's manifest.yaml can declare.
+var invokeFormats = []string{"http", "cloudevent"}
+
+// LintIssue is a problem found in a template repository.
+type LintIssue struct {
+	// Path within the repository to which the issue pertains, slash
+	// separated.  Empty for the repository as a whole.
+	Path string
+
+	// Message describing the issue.
+	Message string
+}
+
+func (i LintIssue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+// LintOption configures the linting of a template repository.
+type LintOption func(*linter)
+
+// LintWithBuilder verifies that the templates of runtimes for which
+// supported returns true build with the given builder.
+func LintWithBuilder(b Builder, supported func(runtime string) bool) LintOption {
+	return func(l *linter) {
+		l.builder = b
+		l.buildable = supported
+	}
+}
+
+// LintWithProgress writes the name of each template to w as it is verified.
+func LintWithProgress(w io.Writer) LintOption {
+	return func(l *linter) {
+		l.progress = w
+	}
+}
+
+type linter struct {
+	builder   Builder
+	buildable func(runtime string) bool
+	progress  io.Writer
+	issues    []LintIssue
+}
+
+func (l *linter) issue(path, format string, a ...any) {
+	l.issues = append(l.issues, LintIssue{Path: path, Message: fmt.Sprintf(format, a...)})
+}
+
+// LintRepository checks the template repository at the given local path,
+// returning the issues found.  Checked are the manifests at the repository,
+// runtime and template levels and the layout of the runtimes and templates
+// within the repository's templates path.  Once these are valid, a function
+// is created from each template and, for runtimes with scaffolding, its
+// signature detected.  Templates are also built if a builder is provided.
+//
+// Errors are returned only if the repository could not be linted.
+func LintRepository(ctx context.Context, root string, options ...LintOption) ([]LintIssue, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("cannot lint repository: %w", err)
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("cannot lint repository: %v is not a directory", root)
+	}
+	l := &linter{buildable: func(string) bool { return false }}
+	for _, o := range options {
+		o(l)
+	}
+
+	fsys := filesystem.NewOsFilesystem(root)
+	if l.lintLayout(fsys); len(l.issues) > 0 {
+		return l.issues, nil // creating functions would repeat the issues
+	}
+
+	uri := "file://" + filepath.ToSlash(root)
+	repo, err := NewRepository(DefaultRepositoryName, uri)
+	if err != nil {
+		l.issue("", "cannot load repository: %v", err)
+		return l.issues, nil
+	}
+	for _, runtime := range repo.Runtimes {
+		for _, t := range runtime.Templates {
+			if err = l.lintTemplate(ctx, uri, t, repo.templatePath(t.Runtime(), t.Name())); err != nil {
+				return l.issues, err
+			}
+		}
+	}
+	return l.issues, nil
+}
+
+// lintLayout checks the manifests and the runtime and template directories.
+func (l *linter) lintLayout(fsys filesystem.Filesystem) {
+	var repoCfg repoConfig
+	l.lintManifest(fsys, manifestFile, &repoCfg, &repoCfg.templateConfig)
+	if repoCfg.TemplatesPath == "" {
+		repoCfg.TemplatesPath = "."
+	}
+	if err := checkDir(fsys, repoCfg.TemplatesPath); err != nil {
+		l.issue(manifestFile, "templates path %q is invalid: %v", repoCfg.TemplatesPath, err)
+		return
+	}
+
+	runtimes, err := fsys.ReadDir(repoCfg.TemplatesPath)
+	if err != nil {
+		l.issue(repoCfg.TemplatesPath, "cannot read templates path: %v", err)
+		return
+	}
+	var found bool
+	for _, rt := range runtimes {
+		if !rt.IsDir() || strings.HasPrefix(rt.Name(), ".") || rt.Name() == "certs" {
+			continue // see runtimes()
+		}
+		found = true
+		runtimePath := path.Join(repoCfg.TemplatesPath, rt.Name())
+		var runtimeCfg runtimeConfig
+		l.lintManifest(fsys, path.Join(runtimePath, manifestFile), &runtimeCfg, &runtimeCfg.templateConfig)
+
+		templates, err := fsys.ReadDir(runtimePath)
+		if err != nil {
+			l.issue(runtimePath, "cannot read runtime: %v", err)
+			continue
+		}
+		var n int
+		for _, t := range templates {
+			if !t.IsDir() || strings.HasPrefix(t.Name(), ".") || t.Name() == "scaffolding" {
+				continue // see templates()
+			}
+			n++
+			var tplCfg templateConfig
+			l.lintManifest(fsys, path.Join(runtimePath, t.Name(), manifestFile), &tplCfg, &tplCfg)
+		}
+		if n == 0 {
+			l.issue(runtimePath, "runtime has no templates")
+		}
+	}
+	if !found {
+		l.issue(repoCfg.TemplatesPath, "repository has no runtimes.  Runtimes are the directories of the templates path")
+	}
+}
+
+// lintManifest decodes the manifest at the given path, if it exists, into
+// cfg, reporting unknown fields and invalid values of the template config
+// tpl embedded in cfg.
+func (l *linter) lintManifest(fsys filesystem.Filesystem, name string, cfg any, tpl *templateConfig) {
+	f, err := fsys.Open(name)
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		l.issue(name, "cannot open manifest: %v", err)
+		return
+	}
+	defer f.Close()
+	bb, err := io.ReadAll(f)
+	if err != nil {
+		l.issue(name, "cannot read manifest: %v", err)
+		return
+	}
+	if err = yaml.UnmarshalStrict(bb, cfg); err != nil {
+		l.issue(name, "invalid manifest: %v", err)
+		return
+	}
+	if tpl.Invoke != "" && !slices.Contains(invokeFormats, tpl.Invoke) {
+		l.issue(name, "invoke %q is invalid.  Valid values are %v", tpl.Invoke, strings.Join(invokeFormats, ", "))
+	}
+	for _, e := range validateParameters(tpl.Parameters) {
+		l.issue(name, "%v", e)
+	}
+}
+
+// lintTemplate creates a function from the template, detects its signature
+// and builds it.
+func (l *linter) lintTemplate(ctx context.Context, uri string, t Template, path string) error {
+	if l.progress != nil {
+		fmt.Fprintf(l.progress, "Checking %v/%v\n", t.Runtime(), t.Name())
+	}
+	dir, err := os.MkdirTemp("", "func-lint-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, t.Name())
+	client := New(WithRepository(uri))
+	f, err := client.Init(Function{
+		Root:               root,
+		Runtime:            t.Runtime(),
+		Template:           t.Name(),
+		TemplateParameters: sampleParameters(t.Parameters()),
+	})
+	if err != nil {
+		l.issue(path, "cannot create function: %v", err)
+		return nil
+	}
+
+	if f.HasScaffolding() {
+		out := filepath.Join(dir, "scaffolding")
+		if err = scaffolding.Write(out, f.Root, f.Runtime, f.Invoke, EmbeddedTemplatesFS); err != nil {
+			l.issue(path, "cannot scaffold function: %v", err)
+			return nil
+		}
+	}
+
+	if l.builder != nil && l.buildable(f.Runtime) {
+		if err = l.builder.Build(ctx, f, nil); err != nil {
+			l.issue(path, "cannot build function: %v", err)
+		}
+	}
+	return nil
+}
+
+// sampleParameters returns values for the required parameters without a
+// default, such that a function can be created from the template.
+func sampleParameters(params []TemplateParameter) map[string]string {
+	values := map[string]string{}
+	for _, p := range params {
+		if !p.Required || p.Default != "" {
+			continue
+		}
+		switch {
+		case len(p.Options) > 0:
+			values[p.Name] = p.Options[0]
+		case p.Type == ParameterTypeBool:
+			values[p.Name] = "true"
+		case p.Type == ParameterTypeInt:
+			values[p.Name] = "1"
+		default:
+			values[p.Name] = "example"
+		}
+	}
+	return values
+}
@@ -0,0 +1,119 @@
+package functions_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestLintRepository_Default ensures that the embedded templates lint
+// without issues.
+func TestLintRepository_Default(t *testing.T) {
+	issues, err := fn.LintRepository(context.Background(), "../../templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) > 0 {
+		t.Fatalf("unexpected issues: %v", issues)
+	}
+}
+
+// TestLintRepository_Layout ensures that invalid manifests and runtimes
+// without templates are reported.
+func TestLintRepository_Layout(t *testing.T) {
+	root := FromTempDirectory(t)
+	writeFiles(t, root, map[string]string{
+		"manifest.yaml":                "templates: src\n",
+		"src/empty/.keep":              "",
+		"src/custom/manifest.yaml":     "invoke: grpc\n",
+		"src/custom/a/manifest.yaml":   "buildpack: example\n",
+		"src/custom/b/manifest.yaml":   "parameters:\n- name: bad-name\n",
+		"src/custom/c/manifest.yaml":   "invoke: cloudevent\n",
+		"src/custom/scaffolding/x.txt": "",
+	})
+
+	issues, err := fn.LintRepository(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, i := range issues {
+		paths = append(paths, i.Path)
+	}
+	expected := []string{
+		"src/custom/manifest.yaml",
+		"src/custom/a/manifest.yaml",
+		"src/custom/b/manifest.yaml",
+		"src/empty",
+	}
+	if diff := cmp.Diff(expected, paths); diff != "" {
+		t.Fatalf("unexpected issues (-want, +got): %v\n%v", diff, issues)
+	}
+}
+
+// TestLintRepository_Templates ensures that templates from which a function
+// can not be created, scaffolded or built are reported.
+func TestLintRepository_Templates(t *testing.T) {
+	root := FromTempDirectory(t)
+	writeFiles(t, root, map[string]string{
+		"custom/ok/README.md.tmpl":       "{{.greeting}}\n",
+		"custom/ok/manifest.yaml":        "parameters:\n- name: greeting\n  required: true\n",
+		"custom/invalid/handle.txt.tmpl": "{{.undeclared}}\n",
+		"custom/invalid/manifest.yaml":   "parameters:\n- name: declared\n",
+		"go/unscaffoldable/go.mod":       "module function\n",
+		"go/unscaffoldable/handle.go":    "package function\n",
+	})
+
+	builder := mock.NewBuilder()
+	builder.BuildFn = func(f fn.Function) error {
+		if f.Name == "ok" {
+			return errors.New("build failed")
+		}
+		return nil
+	}
+	issues, err := fn.LintRepository(context.Background(), root,
+		fn.LintWithBuilder(builder, func(runtime string) bool { return runtime == "custom" }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ii []string
+	for _, i := range issues {
+		ii = append(ii, i.String())
+	}
+	expected := []string{
+		"custom/invalid: cannot create function",
+		"custom/ok: cannot build function: build failed",
+		"go/unscaffoldable: cannot scaffold function",
+	}
+	if len(ii) != len(expected) {
+		t.Fatalf("expected issues %v, got %v", expected, ii)
+	}
+	for i := range expected {
+		if !strings.HasPrefix(ii[i], expected[i]) {
+			t.Fatalf("expected issue %q, got %q", expected[i], ii[i])
+		}
+	}
+}
+
+// writeFiles of the given contents, by slash separated path, under root.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
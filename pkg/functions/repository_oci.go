@@ -0,0 +1,375 @@
+package functions
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/filesystem"
+)
+
+// ociScheme of the URIs of repositories distributed as OCI artifacts, for
+// example oci://registry.example.com/org/templates:v1.0.0
+const ociScheme = "oci://"
+
+// ociSourceFile records, at the root of a repository installed from an OCI
+// artifact, the artifact from which it was installed.  It is hidden, such
+// that it is not loaded as a runtime.  Repositories installed from git record
+// their version in their .git directory instead.
+const ociSourceFile = ".oci-source.yaml"
+
+// Annotations with which oras marks layers of directories, which are tar
+// archives of the directory named by the title.
+const (
+	ociTitleAnnotation  = "org.opencontainers.image.title"
+	ociUnpackAnnotation = "io.deis.oras.content.unpack"
+)
+
+// ociSource is the serialized form of an ociSourceFile.
+type ociSource struct {
+	URL    string `yaml:"url"`
+	Ref    string `yaml:"ref,omitempty"`
+	Digest string `yaml:"digest"`
+}
+
+func isOCIURI(uri string) bool {
+	return strings.HasPrefix(uri, ociScheme)
+}
+
+// isDigestRef returns true if the ref is the digest of an OCI artifact rather
+// than a tag.
+func isDigestRef(ref string) bool {
+	_, err := v1.NewHash(ref)
+	return err == nil
+}
+
+// pinnedURI returns the URI of the repository at remote pinned to the ref:
+// a branch, tag or commit for git repositories; a tag or digest for OCI.
+func pinnedURI(remote, ref string) string {
+	switch {
+	case ref == "":
+		return remote
+	case !isOCIURI(remote):
+		return remote + "#" + ref
+	case isDigestRef(ref):
+		return remote + "@" + ref
+	default:
+		return remote + ":" + ref
+	}
+}
+
+// ociRepositoryName returns the last element of the repository of the OCI
+// artifact, sans tag or digest.
+func ociRepositoryName(uri string) (string, error) {
+	ref, err := name.ParseReference(strings.TrimPrefix(uri, ociScheme))
+	if err != nil {
+		return "", err
+	}
+	return path.Base(ref.Context().RepositoryStr()), nil
+}
+
+// filesystemFromOCI pulls the OCI artifact at the URI into an in-memory
+// filesystem.  Tags are resolved to the digest of the artifact, from which
+// the files are pulled.  Returned are the artifact's repository as a URI, the
+// tag or digest by which it was referenced and its digest.
+//
+// Each layer of the artifact is extracted at the root of the filesystem, and
+// may be a zip archive, such as written by 'func templates publish', or a
+// tar archive, optionally gzipped.  The layers of directories pushed with
+// oras are extracted from within the directory.
+func filesystemFromOCI(uri string, opts ...remote.Option) (f filesystem.Filesystem, remoteURI, ref, digest string, err error) {
+	files, remoteURI, ref, digest, err := pullOCI(uri, opts...)
+	if err != nil {
+		return
+	}
+	return filesystem.NewBillyFilesystem(files), remoteURI, ref, digest, nil
+}
+
+// pullOCI pulls the OCI artifact at the URI as filesystemFromOCI, into an
+// in-memory billy filesystem.
+func pullOCI(uri string, opts ...remote.Option) (files billy.Filesystem, remoteURI, ref, digest string, err error) {
+	r, err := name.ParseReference(strings.TrimPrefix(uri, ociScheme))
+	if err != nil {
+		return nil, "", "", "", fmt.Errorf("invalid OCI reference: %w", err)
+	}
+	desc, err := remote.Get(r, opts...)
+	if err != nil {
+		return nil, "", "", "", fmt.Errorf("failed to pull %v: %w", r, err)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return
+	}
+
+	mfs := memfs.New()
+	for i, l := range layers {
+		var root string
+		if a := manifest.Layers[i].Annotations; a[ociUnpackAnnotation] == "true" {
+			root = a[ociTitleAnnotation]
+		}
+		if err = extractLayer(mfs, l, root); err != nil {
+			return nil, "", "", "", fmt.Errorf("failed to extract layer %v of %v: %w", i, r, err)
+		}
+	}
+	return mfs, ociScheme + r.Context().Name(), r.Identifier(), desc.Digest.String(), nil
+}
+
+// extractLayer into the filesystem, including only the files beneath root.
+func extractLayer(dst billy.Filesystem, l v1.Layer, root string) error {
+	rc, err := l.Compressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	br := bufio.NewReader(rc)
+	magic, _ := br.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")):
+		bb, err := io.ReadAll(br)
+		if err != nil {
+			return err
+		}
+		zr, err := zip.NewReader(bytes.NewReader(bb), int64(len(bb)))
+		if err != nil {
+			return err
+		}
+		return extractZip(dst, zr, root)
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return extractTar(dst, tar.NewReader(gz), root)
+	default:
+		return extractTar(dst, tar.NewReader(br), root)
+	}
+}
+
+func extractTar(dst billy.Filesystem, tr *tar.Reader, root string) error {
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if errors.Is(err, tar.ErrHeader) {
+			return errors.New("layer is neither a zip nor a tar archive")
+		} else if err != nil {
+			return err
+		}
+		name, ok := extractedName(h.Name, root)
+		if !ok {
+			continue
+		}
+		switch h.Typeflag {
+		case tar.TypeDir:
+			err = dst.MkdirAll(name, 0755)
+		case tar.TypeReg:
+			err = extractFile(dst, name, os.FileMode(h.Mode), tr)
+		case tar.TypeSymlink:
+			err = extractSymlink(dst, name, h.Linkname)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZip(dst billy.Filesystem, zr *zip.Reader, root string) error {
+	for _, zf := range zr.File {
+		name, ok := extractedName(zf.Name, root)
+		if !ok {
+			continue
+		}
+		mode := zf.Mode()
+		if mode.IsDir() {
+			if err := dst.MkdirAll(name, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		if mode&fs.ModeSymlink != 0 {
+			var target []byte
+			if target, err = io.ReadAll(rc); err == nil {
+				err = extractSymlink(dst, name, string(target))
+			}
+		} else if mode.IsRegular() {
+			err = extractFile(dst, name, mode, rc)
+		}
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractedName returns the name at which an archived file is extracted:
+// its path relative to root.  Paths are confined to the filesystem.  False
+// is returned for files not beneath root.
+func extractedName(name, root string) (string, bool) {
+	name = path.Clean("/" + name)
+	if root != "" {
+		root = path.Clean("/" + root)
+		if name != root && !strings.HasPrefix(name, root+"/") {
+			return "", false
+		}
+		name = strings.TrimPrefix(name, root)
+	}
+	name = strings.TrimPrefix(name, "/")
+	return name, name != ""
+}
+
+// extractFile to the filesystem, coercing its mode as for the embedded
+// templates (see generate/templates/main.go).
+func extractFile(dst billy.Filesystem, name string, mode os.FileMode, r io.Reader) error {
+	if err := dst.MkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if mode&0111 != 0 {
+		perm = 0755
+	}
+	f, err := dst.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func extractSymlink(dst billy.Filesystem, name, target string) error {
+	if err := dst.MkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+	return dst.Symlink(target, name)
+}
+
+// ociVersion returns the URI, ref and digest of the artifact from which the
+// repository on disk at path was installed.  Empty if it was not installed
+// from an OCI artifact.
+func ociVersion(path string) (remote, ref, digest string) {
+	bb, err := os.ReadFile(filepath.Join(path, ociSourceFile))
+	if err != nil {
+		return
+	}
+	var s ociSource
+	if err = yaml.Unmarshal(bb, &s); err != nil {
+		return
+	}
+	return s.URL, s.Ref, s.Digest
+}
+
+// writeOCISource records the artifact from which the repository written to
+// dest was pulled.
+func (r *Repository) writeOCISource(dest string) error {
+	bb, err := yaml.Marshal(ociSource{URL: r.remote, Ref: r.ref, Digest: r.commit})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dest, ociSourceFile), bb, 0644)
+}
+
+// latestOCIRef returns the ref to which a repository pinned to the given ref
+// is updated: the highest released version if pinned to a semver tag,
+// otherwise the ref itself.
+func latestOCIRef(uri, ref string, opts ...remote.Option) (string, error) {
+	if isDigestRef(ref) {
+		return ref, nil
+	}
+	current, err := semver.NewVersion(ref)
+	if err != nil {
+		return ref, nil
+	}
+	repo, err := name.NewRepository(strings.TrimPrefix(uri, ociScheme))
+	if err != nil {
+		return "", err
+	}
+	tags, err := remote.List(repo, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags of %v: %w", uri, err)
+	}
+	latest := ref
+	for _, t := range tags {
+		v, err := semver.NewVersion(t)
+		if err != nil || v.Prerelease() != "" || !v.GreaterThan(current) {
+			continue
+		}
+		current, latest = v, t
+	}
+	return latest, nil
+}
+
+// ociTemplateTree returns the tree of the template at the given path as of
+// the given tag or digest of the OCI artifact at uri.  The artifact is
+// committed to an in-memory git repository, such that templates distributed
+// as OCI artifacts are compared like those of git repositories.
+func ociTemplateTree(uri, ref, path string, opts ...remote.Option) (*object.Tree, error) {
+	files, _, _, _, err := pullOCI(pinnedURI(uri, ref), opts...)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.Init(memory.NewStorage(), files)
+	if err != nil {
+		return nil, err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	if err = wt.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return nil, err
+	}
+	hash, err := wt.Commit(ref, &git.CommitOptions{Author: &object.Signature{Name: "func"}, AllowEmptyCommits: true})
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	if tree, err = tree.Tree(path); errors.Is(err, object.ErrDirectoryNotFound) {
+		return nil, fmt.Errorf("template %v not found at %v: %w", path, ref, ErrTemplateNotFound)
+	}
+	return tree, err
+}
@@ -0,0 +1,249 @@
+package functions_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestRepositories_OCI ensures that repositories distributed as OCI
+// artifacts can be added, are pinned by digest, and can be updated, and that
+// the templates of functions created from them can be compared.
+func TestRepositories_OCI(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	repo := "oci://" + strings.TrimPrefix(server.URL, "http://") + "/org/templates"
+
+	// v1.0.0 is a directory pushed with oras: a tar of the directory
+	// 'templates' annotated to be unpacked.
+	v1Digest := pushArtifact(t, repo+":v1.0.0", mutate.Addendum{
+		Layer: static.NewLayer(tarGz(t, map[string]string{
+			"templates/go/hello/handle.go": "// Version 1\n",
+			"README.md":                    "outside the directory\n",
+		}), types.OCILayer),
+		Annotations: map[string]string{
+			"org.opencontainers.image.title": "templates",
+			"io.deis.oras.content.unpack":    "true",
+		},
+	})
+	// v1.1.0 is a zip as written by 'func templates publish'
+	v2Digest := pushArtifact(t, repo+":v1.1.0", mutate.Addendum{
+		Layer: static.NewLayer(zipOf(t, map[string]string{
+			"go/hello/handle.go": "// Version 2\n",
+		}), types.MediaType("application/zip")),
+	})
+
+	root := FromTempDirectory(t)
+	client := fn.New(fn.WithRepositoriesPath(filepath.Join(root, "repositories")))
+
+	expect := func(name, ref, digest, version string) {
+		t.Helper()
+		r, err := client.Repositories().Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Ref() != ref || r.Commit() != digest {
+			t.Fatalf("expected %v at %v@%v, got %v@%v", name, ref, digest, r.Ref(), r.Commit())
+		}
+		bb, err := os.ReadFile(filepath.Join(root, "repositories", name, "go", "hello", "handle.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(bb), version) {
+			t.Fatalf("expected %v of %v, got %q", version, name, bb)
+		}
+	}
+
+	// Added by tag, the repository is pinned to the tag's digest
+	if _, err := client.Repositories().Add(t.Context(), "tagged", repo+":v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	expect("tagged", "v1.0.0", v1Digest, "Version 1")
+	if _, err := os.Stat(filepath.Join(root, "repositories", "tagged", "README.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected files outside the unpacked directory to be omitted, got %v", err)
+	}
+	r, err := client.Repositories().Get("tagged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.URL() != repo+":v1.0.0" {
+		t.Fatalf("expected URL %v, got %v", repo+":v1.0.0", r.URL())
+	}
+
+	// Functions record the digest of the template
+	f, err := client.Init(fn.Function{Root: filepath.Join(root, "f"), Runtime: "go", Template: "tagged/hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err := fn.ReadLock(f.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Template.URL != repo || lock.Template.Ref != "v1.0.0" || lock.Template.Commit != v1Digest {
+		t.Fatalf("unexpected lock %+v", lock.Template)
+	}
+
+	// Added by digest, the name is derived from the repository
+	name, err := client.Repositories().Add(t.Context(), "", repo+"@"+v1Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "templates" {
+		t.Fatalf("expected name templates, got %v", name)
+	}
+	expect("templates", v1Digest, v1Digest, "Version 1")
+
+	// A semver tag is updated to the highest release, a digest remains
+	if _, err = client.Repositories().Update(t.Context(), "tagged", ""); err != nil {
+		t.Fatal(err)
+	}
+	expect("tagged", "v1.1.0", v2Digest, "Version 2")
+
+	// The function's template changed with the update, though not since v1.0.0
+	diff, err := client.Templates().Diff(t.Context(), f.Root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "-// Version 1") || !strings.Contains(diff, "+// Version 2") {
+		t.Fatalf("expected changes from version 1 to 2, got:\n%v", diff)
+	}
+	if diff, err = client.Templates().Diff(t.Context(), f.Root, "v1.0.0"); err != nil || diff != "" {
+		t.Fatalf("expected no changes up to v1.0.0, got %v:\n%v", err, diff)
+	}
+	if _, err = client.Repositories().Update(t.Context(), "templates", ""); err != nil {
+		t.Fatal(err)
+	}
+	expect("templates", v1Digest, v1Digest, "Version 1")
+}
+
+// TestRepositories_OCIPullOptions ensures that repositories distributed as
+// OCI artifacts are pulled using the client's transport, such as one trusting
+// the certificate of a private registry, and within the given context.
+func TestRepositories_OCIPullOptions(t *testing.T) {
+	server := httptest.NewUnstartedServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // expected TLS errors
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	uri := strings.TrimPrefix(server.URL, "https://") + "/org/templates:v1.0.0"
+
+	ref, err := name.ParseReference(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1), mutate.Addendum{
+		Layer: static.NewLayer(zipOf(t, map[string]string{"go/hello/handle.go": "// Version 1\n"}), types.MediaType("application/zip")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Write(ref, img, remote.WithTransport(server.Client().Transport)); err != nil {
+		t.Fatal(err)
+	}
+
+	root := FromTempDirectory(t)
+
+	// The default transport does not trust the registry
+	client := fn.New(fn.WithRepositoriesPath(filepath.Join(root, "repositories")))
+	if _, err = client.Repositories().Add(t.Context(), "untrusted", "oci://"+uri); err == nil {
+		t.Fatal("expected pulling with the default transport to fail")
+	}
+
+	client = fn.New(
+		fn.WithRepositoriesPath(filepath.Join(root, "repositories")),
+		fn.WithTransport(server.Client().Transport))
+	if _, err = client.Repositories().Add(t.Context(), "trusted", "oci://"+uri); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err = client.Repositories().Add(ctx, "canceled", "oci://"+uri); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the pull to be canceled, got %v", err)
+	}
+}
+
+// pushArtifact of the given layers to the registry, returning its digest.
+func pushArtifact(t *testing.T, uri string, layers ...mutate.Addendum) string {
+	t.Helper()
+	ref, err := name.ParseReference(strings.TrimPrefix(uri, "oci://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1), layers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	var digest v1.Hash
+	if digest, err = img.Digest(); err != nil {
+		t.Fatal(err)
+	}
+	return digest.String()
+}
+
+// tarGz of the given files, by slash separated path.
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipOf the given files, by slash separated path.
+func zipOf(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
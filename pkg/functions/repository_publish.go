@@ -0,0 +1,163 @@
+package functions
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"knative.dev/func/pkg/filesystem"
+)
+
+// PublishRepository tags the HEAD commit of the git repository of templates
+// at root and writes to w a zip archive of the repository's files at that
+// commit.  The archive is laid out as the embedded templates, such that it
+// can be read with filesystem.NewZipFS.  An existing tag is reused if it is
+// of the HEAD commit.  Returned is the tagged commit.
+//
+// Changes to the repository's files must be committed, as they would not be
+// published otherwise.  Untracked files, such as previously published
+// archives, are ignored.
+func PublishRepository(root, tag string, w io.Writer) (commit string, err error) {
+	repo, err := git.PlainOpen(root)
+	if err != nil {
+		return "", fmt.Errorf("cannot open git repository at %v: %w", root, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return
+	}
+	for name, s := range status {
+		if s.Worktree != git.Untracked || s.Staging != git.Untracked {
+			return "", fmt.Errorf("repository at %v has uncommitted changes to %v. Commit them before publishing", root, name)
+		}
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return
+	}
+	c, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = writeTreeZip(repo, tree, &buf); err != nil {
+		return "", fmt.Errorf("cannot package repository: %w", err)
+	}
+	if err = checkRepositoryZip(buf.Bytes()); err != nil {
+		return "", fmt.Errorf("packaged repository is invalid: %w", err)
+	}
+	if err = tagRepository(repo, tag, head.Hash()); err != nil {
+		return
+	}
+	if _, err = io.Copy(w, &buf); err != nil {
+		return
+	}
+	return head.Hash().String(), nil
+}
+
+// tagRepository with a lightweight tag of the given commit, unless the tag
+// already exists for it.
+func tagRepository(repo *git.Repository, tag string, commit plumbing.Hash) error {
+	existing, err := repo.Tag(tag)
+	if errors.Is(err, git.ErrTagNotFound) {
+		_, err = repo.CreateTag(tag, commit, nil)
+		return err
+	} else if err != nil {
+		return err
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(existing.Name().String()))
+	if err != nil {
+		return err
+	}
+	if *hash != commit {
+		return fmt.Errorf("tag %v already exists for commit %v", tag, hash.String()[:7])
+	}
+	return nil
+}
+
+// writeTreeZip writes the files of the tree as a zip archive.  As with the
+// archive of the embedded templates, directories are included and modes are
+// coerced for reproducibility (see generate/templates/main.go).
+func writeTreeZip(repo *git.Repository, tree *object.Tree, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		switch entry.Mode {
+		case filemode.Dir:
+			header.Name = name + "/"
+			header.SetMode(fs.ModeDir | 0755)
+		case filemode.Executable:
+			header.SetMode(0755)
+		case filemode.Regular, filemode.Deprecated:
+			header.SetMode(0644)
+		case filemode.Symlink:
+			header.SetMode(fs.ModeSymlink | 0777)
+		default:
+			return fmt.Errorf("unsupported file %v of mode %v", name, entry.Mode)
+		}
+
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if entry.Mode == filemode.Dir {
+			continue
+		}
+		blob, err := repo.BlobObject(entry.Hash)
+		if err != nil {
+			return err
+		}
+		r, err := blob.Reader()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(fw, r) // symlinks are blobs of their target
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// checkRepositoryZip loads the runtimes and templates of a repository
+// packaged as a zip archive.
+func checkRepositoryZip(bb []byte) error {
+	zr, err := zip.NewReader(bytes.NewReader(bb), int64(len(bb)))
+	if err != nil {
+		return err
+	}
+	fsys := filesystem.NewZipFS(zr)
+	cfg, err := loadRepoConfig(fsys, repoConfig{TemplatesPath: "."})
+	if err != nil {
+		return err
+	}
+	_, err = runtimes(fsys, cfg)
+	return err
+}
@@ -0,0 +1,116 @@
+package functions_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-cmp/cmp"
+
+	"knative.dev/func/pkg/filesystem"
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestPublishRepository ensures that a repository is tagged and packaged as
+// a zip archive readable as a templates filesystem.
+func TestPublishRepository(t *testing.T) {
+	root := FromTempDirectory(t)
+	writeFiles(t, root, map[string]string{
+		"manifest.yaml":         "name: example\n",
+		"custom/tpl/README.md":  "example\n",
+		"custom/tpl/run.sh":     "#!/bin/sh\n",
+		"custom/manifest.yaml":  "invoke: http\n",
+		"custom/.hidden/ignore": "",
+	})
+	if err := os.Chmod(filepath.Join(root, "custom/tpl/run.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := commitAll(t, repo)
+
+	var buf bytes.Buffer
+	commit, err := fn.PublishRepository(root, "v1.0.0", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit != head {
+		t.Fatalf("expected commit %v, got %v", head, commit)
+	}
+	if _, err = repo.Tag("v1.0.0"); err != nil {
+		t.Fatalf("expected tag v1.0.0: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := filesystem.NewZipFS(zr)
+	entries, err := fsys.ReadDir("custom/tpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	slices.Sort(names)
+	if diff := cmp.Diff([]string{"README.md", "run.sh"}, names); diff != "" {
+		t.Fatalf("unexpected files (-want, +got): %v", diff)
+	}
+	fi, err := fsys.Stat("custom/tpl/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0755 {
+		t.Fatalf("expected executable run.sh, got mode %v", fi.Mode())
+	}
+
+	// Publishing again with the same tag is allowed
+	if _, err = fn.PublishRepository(root, "v1.0.0", &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Untracked files are ignored, but uncommitted changes are not published
+	writeFiles(t, root, map[string]string{"example-v1.0.0.zip": ""})
+	if _, err = fn.PublishRepository(root, "v1.0.0", &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{"custom/tpl/README.md": "changed\n"})
+	if _, err = fn.PublishRepository(root, "v1.1.0", &bytes.Buffer{}); err == nil {
+		t.Fatal("expected error publishing uncommitted changes")
+	}
+
+	// Tags of other commits are not moved
+	commitAll(t, repo)
+	if _, err = fn.PublishRepository(root, "v1.0.0", &bytes.Buffer{}); err == nil {
+		t.Fatal("expected error publishing with the tag of another commit")
+	}
+}
+
+// commitAll files of the repository's worktree, returning the commit.
+func commitAll(t *testing.T, repo *git.Repository) string {
+	t.Helper()
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = wt.Add("."); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "xyz", Email: "xyz@abc.com", When: time.Now()}
+	hash, err := wt.Commit("commit", &git.CommitOptions{Author: sig, Committer: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
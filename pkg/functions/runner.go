@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -64,6 +65,13 @@ func (r *defaultRunner) Run(ctx context.Context, f Function, address string, sta
 		return
 	}
 
+	// Debugger port, which must differ from the function's
+	if r.client.runDebug {
+		if job.DebugPort, err = chooseDebugPort(host, port, f.Runtime); err != nil {
+			return
+		}
+	}
+
 	// Scaffold the function such that it can be run.
 	if err = r.client.Scaffold(ctx, f, job.Dir()); err != nil {
 		return
@@ -111,6 +119,44 @@ func getRunFunc(ctx context.Context, job *Job) (runFn func() error, err error) {
 	return
 }
 
+// DefaultDebugPort returns the port the debugger of the given runtime
+// listens on by default, or an empty string if debugging is not supported.
+func DefaultDebugPort(runtime string) string {
+	switch runtime {
+	case "go":
+		return "2345" // Delve
+	case "python":
+		return "5678" // debugpy
+	case "node", "typescript":
+		return "9229" // node --inspect
+	case "springboot", "quarkus":
+		return "5005" // JDWP
+	}
+	return ""
+}
+
+// chooseDebugPort on the given interface for the debugger of the runtime,
+// preferring its default port.
+func chooseDebugPort(iface, functionPort, runtime string) (string, error) {
+	preferred := DefaultDebugPort(runtime)
+	if preferred == "" {
+		return "", fmt.Errorf("debugging is not supported for the %q runtime", runtime)
+	}
+	if preferred != functionPort {
+		if l, err := net.Listen("tcp", net.JoinHostPort(iface, preferred)); err == nil {
+			l.Close()
+			return preferred, nil
+		}
+	}
+	l, err := net.Listen("tcp", net.JoinHostPort(iface, "0"))
+	if err != nil {
+		return "", fmt.Errorf("cannot choose debug port: %w", err)
+	}
+	l.Close()
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	return port, err
+}
+
 func runGo(ctx context.Context, job *Job) (err error) {
 	// TODO:  long-term, the correct architecture is to not read env vars
 	// from deep within a package, but rather to expose the setting as a
@@ -145,6 +191,9 @@ func runGo(ctx context.Context, job *Job) (err error) {
 
 	// Build
 	args = []string{"build", "-o", "f.bin"}
+	if job.DebugPort != "" {
+		args = append(args, "-gcflags=all=-N -l") // disable optimizations
+	}
 	if job.verbose {
 		args = append(args, "-v")
 	}
@@ -165,6 +214,18 @@ func runGo(ctx context.Context, job *Job) (err error) {
 		fmt.Printf("cd %v && PORT=%v %v\n", job.Function.Root, job.Port, bin)
 	}
 	cmd = exec.CommandContext(ctx, bin)
+	if job.DebugPort != "" {
+		dlvbin := os.Getenv("FUNC_DLV") // Use if provided
+		if dlvbin == "" {
+			dlvbin = "dlv"
+		}
+		args = []string{"exec", "--headless", "--listen=" + net.JoinHostPort(job.Host, job.DebugPort),
+			"--api-version=2", "--accept-multiclient", "--continue", bin}
+		if job.verbose {
+			fmt.Printf("%v %v\n", dlvbin, strings.Join(args, " "))
+		}
+		cmd = exec.CommandContext(ctx, dlvbin, args...)
+	}
 	cmd.Dir = job.Function.Root
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -233,12 +294,28 @@ func runPython(ctx context.Context, job *Job) (err error) {
 		return
 	}
 
+	// Install the debugger
+	args := []string{"./service/main.py"}
+	if job.DebugPort != "" {
+		if job.verbose {
+			fmt.Printf("./.venv/bin/pip install debugpy\n")
+		}
+		cmd = exec.CommandContext(ctx, "./.venv/bin/pip", "install", "debugpy")
+		cmd.Dir = job.Dir()
+		cmd.Stderr = os.Stderr
+		cmd.Stdout = os.Stdout
+		if err = cmd.Run(); err != nil {
+			return
+		}
+		args = append([]string{"-m", "debugpy", "--listen", net.JoinHostPort(job.Host, job.DebugPort)}, args...)
+	}
+
 	// Run
 	listenAddress := net.JoinHostPort(job.Host, job.Port)
 	if job.verbose {
-		fmt.Printf("PORT=%v LISTEN_ADDRESS=%v ./.venv/bin/python ./service/main.py\n", job.Port, listenAddress)
+		fmt.Printf("PORT=%v LISTEN_ADDRESS=%v ./.venv/bin/python %v\n", job.Port, listenAddress, strings.Join(args, " "))
 	}
-	cmd = exec.CommandContext(ctx, "./.venv/bin/python", "./service/main.py")
+	cmd = exec.CommandContext(ctx, "./.venv/bin/python", args...)
 	// cmd.Dir = job.Function.Root // handled by the middleware
 	cmd.Dir = job.Dir()
 	cmd.Stdout = os.Stdout
@@ -0,0 +1,32 @@
+package functions
+
+import (
+	"net"
+	"testing"
+)
+
+func TestChooseDebugPort(t *testing.T) {
+	// the runtime's default debug port is preferred
+	port, err := chooseDebugPort("127.0.0.1", "8080", "python")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l, err := net.Listen("tcp", "127.0.0.1:5678"); err == nil {
+		l.Close()
+		if port != "5678" {
+			t.Errorf("expected the debugpy default port, got %v", port)
+		}
+	}
+
+	// never the function's port
+	if port, err = chooseDebugPort("127.0.0.1", "2345", "go"); err != nil {
+		t.Fatal(err)
+	}
+	if port == "2345" || port == "" {
+		t.Errorf("expected a port other than the function's, got %q", port)
+	}
+
+	if _, err = chooseDebugPort("127.0.0.1", "8080", "rust"); err == nil {
+		t.Error("expected an error for a runtime without debugger support")
+	}
+}
@@ -2,6 +2,7 @@ package functions
 
 import (
 	"context"
+	"fmt"
 	"path"
 
 	"knative.dev/func/pkg/filesystem"
@@ -23,6 +24,9 @@ type Template interface {
 	// to uniquely reference a template which may share a name
 	// with one in another repository.
 	Fullname() string
+	// Parameters the template asks of the user, whose values are provided
+	// via the function's TemplateParameters.
+	Parameters() []TemplateParameter
 	// Write updates fields of function f and writes project files to path pointed by f.Root.
 	Write(ctx context.Context, f *Function) error
 }
@@ -52,6 +56,10 @@ func (t template) Fullname() string {
 	return t.repository + "/" + t.name
 }
 
+func (t template) Parameters() []TemplateParameter {
+	return t.config.Parameters
+}
+
 // Write the template source files
 // (all source code except manifest.yaml and scaffolding)
 func (t template) Write(ctx context.Context, f *Function) error {
@@ -87,6 +95,21 @@ func (t template) Write(ctx context.Context, f *Function) error {
 		_, f := path.Split(p)
 		return f == manifestFile
 	}
+	fs := filesystem.NewMaskingFS(mask, t.fs)
 
-	return filesystem.CopyFromFS(".", f.Root, filesystem.NewMaskingFS(mask, t.fs)) // copy everything but manifest.yaml
+	if len(t.config.Parameters) == 0 {
+		if len(f.TemplateParameters) > 0 {
+			return fmt.Errorf("template %v declares no parameters: %w", t.Fullname(), ErrUnknownTemplateParameter)
+		}
+		return filesystem.CopyFromFS(".", f.Root, fs) // copy everything but manifest.yaml
+	}
+
+	// Render the parameters into the template, recording the effective
+	// values on the function.
+	data, values, err := resolveParameters(t.config.Parameters, f.TemplateParameters)
+	if err != nil {
+		return err
+	}
+	f.TemplateParameters = values
+	return renderFromFS(".", f.Root, fs, data)
 }
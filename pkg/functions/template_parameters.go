@@ -0,0 +1,292 @@
+package functions
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	gotemplate "text/template"
+
+	"knative.dev/func/pkg/filesystem"
+)
+
+// Template parameter types
+const (
+	ParameterTypeString = "string"
+	ParameterTypeBool   = "bool"
+	ParameterTypeInt    = "int"
+)
+
+// ParameterTypes are the types a template parameter can be declared as.
+var ParameterTypes = []string{ParameterTypeString, ParameterTypeBool, ParameterTypeInt}
+
+// TemplateParameter is a value a template author asks of the user creating a
+// function from the template.  Parameters are declared in the template's
+// manifest.yaml, and their values are rendered into the names of the
+// template's files and the contents of those named with TemplateFileSuffix,
+// for example {{.name}}.
+type TemplateParameter struct {
+	// Name of the parameter, by which it is referenced in the template.
+	Name string `yaml:"name"`
+
+	// Type of the parameter: string (default), bool or int.
+	Type string `yaml:"type,omitempty"`
+
+	// Default value of the parameter.  Parameters without a default are
+	// rendered as their type's zero value unless required.
+	Default string `yaml:"default,omitempty"`
+
+	// Prompt shown when asking for the value interactively.  Defaults to the
+	// name.
+	Prompt string `yaml:"prompt,omitempty"`
+
+	// Required parameters must be provided a value unless they have a
+	// default.
+	Required bool `yaml:"required,omitempty"`
+
+	// Pattern is a regular expression a string value must match.
+	Pattern string `yaml:"pattern,omitempty"`
+
+	// Options restricts the value to one of the given.
+	Options []string `yaml:"options,omitempty"`
+}
+
+// Message to show when prompting for the parameter.
+func (p TemplateParameter) Message() string {
+	if p.Prompt != "" {
+		return p.Prompt
+	}
+	return p.Name
+}
+
+// Validate the value against the parameter's type, pattern and options.
+func (p TemplateParameter) Validate(value string) error {
+	_, err := p.parse(value)
+	return err
+}
+
+// parse the value as the parameter's type, validating it.
+func (p TemplateParameter) parse(value string) (v any, err error) {
+	if v, err = p.convert(value); err != nil {
+		return
+	}
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %q has an invalid pattern: %w", p.Name, err)
+		}
+		if !re.MatchString(value) {
+			return nil, fmt.Errorf("parameter %q must match %v, got %q", p.Name, p.Pattern, value)
+		}
+	}
+	if len(p.Options) > 0 && !slices.Contains(p.Options, value) {
+		return nil, fmt.Errorf("parameter %q must be one of %v, got %q", p.Name, strings.Join(p.Options, ", "), value)
+	}
+	return
+}
+
+// convert the value to the parameter's type.
+func (p TemplateParameter) convert(value string) (v any, err error) {
+	switch p.Type {
+	case "", ParameterTypeString:
+		v = value
+	case ParameterTypeBool:
+		if v, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("parameter %q requires a bool, got %q", p.Name, value)
+		}
+	case ParameterTypeInt:
+		if v, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("parameter %q requires an int, got %q", p.Name, value)
+		}
+	default:
+		return nil, fmt.Errorf("parameter %q has unknown type %q", p.Name, p.Type)
+	}
+	return
+}
+
+// zero value of the parameter's type.
+func (p TemplateParameter) zero() any {
+	switch p.Type {
+	case ParameterTypeBool:
+		return false
+	case ParameterTypeInt:
+		return 0
+	default:
+		return ""
+	}
+}
+
+var parameterNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateParameters declared by a template, returning the errors found.
+func validateParameters(params []TemplateParameter) (errs []string) {
+	seen := map[string]bool{}
+	for i, p := range params {
+		if !parameterNameRegexp.MatchString(p.Name) {
+			errs = append(errs, fmt.Sprintf("parameter #%d has invalid name %q. Names must be valid identifiers", i, p.Name))
+			continue
+		}
+		if seen[p.Name] {
+			errs = append(errs, fmt.Sprintf("parameter %q is declared more than once", p.Name))
+		}
+		seen[p.Name] = true
+		if p.Type != "" && !slices.Contains(ParameterTypes, p.Type) {
+			errs = append(errs, fmt.Sprintf("parameter %q has unknown type %q. Types are %v", p.Name, p.Type, strings.Join(ParameterTypes, ", ")))
+			continue
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			errs = append(errs, fmt.Sprintf("parameter %q has an invalid pattern: %v", p.Name, err))
+			continue
+		}
+		for _, o := range p.Options {
+			if _, err := p.convert(o); err != nil {
+				errs = append(errs, fmt.Sprintf("option %q of parameter %q is invalid: %v", o, p.Name, err))
+			}
+		}
+		if p.Default != "" {
+			if err := p.Validate(p.Default); err != nil {
+				errs = append(errs, fmt.Sprintf("default of parameter %q is invalid: %v", p.Name, err))
+			}
+		}
+	}
+	return
+}
+
+// resolveParameters returns the values of the given parameters from those
+// provided, falling back to defaults.  Returned are both the typed values with
+// which templates are rendered and their string forms.
+func resolveParameters(params []TemplateParameter, provided map[string]string) (data map[string]any, values map[string]string, err error) {
+	if errs := validateParameters(params); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("template declares invalid parameters:\n\t%v", strings.Join(errs, "\n\t"))
+	}
+	for name := range provided {
+		if !slices.ContainsFunc(params, func(p TemplateParameter) bool { return p.Name == name }) {
+			return nil, nil, fmt.Errorf("%w: %q", ErrUnknownTemplateParameter, name)
+		}
+	}
+
+	data, values = map[string]any{}, map[string]string{}
+	for _, p := range params {
+		value, ok := provided[p.Name]
+		if !ok {
+			value = p.Default
+		}
+		if value == "" {
+			if p.Required {
+				return nil, nil, fmt.Errorf("%w: %q", ErrTemplateParameterRequired, p.Name)
+			}
+			data[p.Name] = p.zero()
+			continue
+		}
+		if data[p.Name], err = p.parse(value); err != nil {
+			return nil, nil, err
+		}
+		values[p.Name] = value
+	}
+	return
+}
+
+// TemplateFileSuffix marks the files of a template whose contents are
+// rendered with the template's parameters.  It is removed from their names.
+const TemplateFileSuffix = ".tmpl"
+
+// renderFromFS copies files like filesystem.CopyFromFS, rendering the names
+// of files as Go templates with the given data, as well as the contents of
+// those named with TemplateFileSuffix.  Contents of other files are copied
+// as-is.
+func renderFromFS(root, dest string, fsys filesystem.Filesystem, data map[string]any) error {
+	return fs.WalkDir(fsys, root, func(path string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		p, err := filepath.Rel(filepath.FromSlash(root), filepath.FromSlash(path))
+		if err != nil {
+			return err
+		}
+		// Names are rendered per path element.  Those rendering empty are
+		// omitted, such that files can be included conditionally.
+		ee := strings.Split(p, string(filepath.Separator))
+		for i := range ee {
+			if ee[i], err = render(p, ee[i], data); err != nil {
+				return err
+			}
+			if ee[i] == "" {
+				if de.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+		}
+		dest := filepath.Join(append([]string{dest}, ee...)...)
+		renderContents := de.Type().IsRegular() && len(ee[len(ee)-1]) > len(TemplateFileSuffix) &&
+			strings.HasSuffix(dest, TemplateFileSuffix)
+		if renderContents {
+			dest = strings.TrimSuffix(dest, TemplateFileSuffix)
+		}
+
+		switch {
+		case de.IsDir():
+			return os.MkdirAll(dest, 0755) // see filesystem.CopyFromFS
+		case de.Type()&fs.ModeSymlink != 0:
+			target, err := fsys.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, dest)
+		case de.Type().IsRegular():
+			fi, err := de.Info()
+			if err != nil {
+				return err
+			}
+			f, err := fsys.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			bb, err := io.ReadAll(f)
+			if err != nil {
+				return err
+			}
+			if renderContents {
+				s, err := render(path, string(bb), data)
+				if err != nil {
+					return err
+				}
+				bb = []byte(s)
+			}
+			return os.WriteFile(dest, bb, fi.Mode())
+		default:
+			return fmt.Errorf("unsuported file type: %s", de.Type().String())
+		}
+	})
+}
+
+// renderFuncs available to templates in addition to the builtin functions.
+var renderFuncs = gotemplate.FuncMap{
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"replace":   strings.ReplaceAll,
+	"trimSpace": strings.TrimSpace,
+}
+
+// render the text as a Go template with the given data.  References to
+// parameters which were not declared are errors.
+func render(name, text string, data map[string]any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	t, err := gotemplate.New(name).Option("missingkey=error").Funcs(renderFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse template file %v: %w", name, err)
+	}
+	var b strings.Builder
+	if err = t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("cannot render template file %v: %w", name, err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,92 @@
+package functions
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_validateParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []TemplateParameter
+		errs   int
+	}{
+		{
+			"not set",
+			nil,
+			0,
+		},
+		{
+			"correct parameters",
+			[]TemplateParameter{
+				{Name: "module", Default: "example.com/f", Pattern: `^[a-z./]+$`},
+				{Name: "tests", Type: ParameterTypeBool, Default: "true"},
+				{Name: "replicas", Type: ParameterTypeInt, Options: []string{"1", "3"}},
+			},
+			0,
+		},
+		{
+			"invalid and duplicate names",
+			[]TemplateParameter{{Name: "my-param"}, {Name: "a"}, {Name: "a"}},
+			2,
+		},
+		{
+			"unknown type",
+			[]TemplateParameter{{Name: "a", Type: "float"}},
+			1,
+		},
+		{
+			"invalid pattern",
+			[]TemplateParameter{{Name: "a", Pattern: "("}},
+			1,
+		},
+		{
+			"invalid option and default",
+			[]TemplateParameter{{Name: "a", Type: ParameterTypeInt, Options: []string{"one"}, Default: "2"}},
+			2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateParameters(tt.params); len(got) != tt.errs {
+				t.Errorf("validateParameters() = %v\n got %d errors but want %d", got, len(got), tt.errs)
+			}
+		})
+	}
+}
+
+func Test_resolveParameters(t *testing.T) {
+	params := []TemplateParameter{
+		{Name: "module", Required: true},
+		{Name: "tests", Type: ParameterTypeBool, Default: "true"},
+		{Name: "replicas", Type: ParameterTypeInt},
+		{Name: "db", Options: []string{"postgres", "mysql"}, Default: "postgres"},
+	}
+
+	data, values, err := resolveParameters(params, map[string]string{"module": "example.com/f", "db": "mysql"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]any{"module": "example.com/f", "tests": true, "replicas": 0, "db": "mysql"}, data); diff != "" {
+		t.Errorf("unexpected data (-want, +got): %v", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"module": "example.com/f", "tests": "true", "db": "mysql"}, values); diff != "" {
+		t.Errorf("unexpected values (-want, +got): %v", diff)
+	}
+
+	if _, _, err = resolveParameters(params, nil); !errors.Is(err, ErrTemplateParameterRequired) {
+		t.Errorf("expected ErrTemplateParameterRequired, got %v", err)
+	}
+	if _, _, err = resolveParameters(params, map[string]string{"module": "m", "other": "x"}); !errors.Is(err, ErrUnknownTemplateParameter) {
+		t.Errorf("expected ErrUnknownTemplateParameter, got %v", err)
+	}
+	if _, _, err = resolveParameters(params, map[string]string{"module": "m", "db": "sqlite"}); err == nil {
+		t.Error("expected an error for a value not among the options")
+	}
+	if _, _, err = resolveParameters(params, map[string]string{"module": "m", "replicas": "many"}); err == nil {
+		t.Error("expected an error for a value not of the parameter's type")
+	}
+}
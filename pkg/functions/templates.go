@@ -2,8 +2,15 @@ package functions
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+
 	"knative.dev/func/pkg/utils"
 )
 
@@ -98,10 +105,110 @@ func (t *Templates) Write(f *Function) error {
 	}
 
 	// The function's Template
-	template, err := t.Get(f.Runtime, f.Template)
+	repoName, tplName := splitTemplateFullname(f.Template)
+	repo, err := t.client.Repositories().Get(repoName)
+	if err != nil {
+		return err
+	}
+	template, err := repo.Template(f.Runtime, tplName)
 	if err != nil {
 		return err
 	}
 
-	return template.Write(context.TODO(), f)
+	if err = template.Write(context.TODO(), f); err != nil {
+		return err
+	}
+
+	// Record the version of the template from which the function was created
+	return Lock{Template: newTemplateLock(repo, template, f.TemplateParameters)}.Write(f.Root)
+}
+
+// Diff returns, as a patch, the changes made to the template of the function
+// rooted at root since the function was created from it.  Changes are up to
+// the given ref (branch, tag or commit; for OCI artifacts a tag or digest) of
+// the template's repository.  If not provided, the version of the repository
+// currently installed is assumed, or the latest if it is not installed.
+func (t *Templates) Diff(ctx context.Context, root, ref string) (string, error) {
+	lock, err := ReadLock(root)
+	if err != nil {
+		return "", err
+	}
+	l := lock.Template
+	if !l.Versioned() {
+		return "", fmt.Errorf("%w: %v/%v", ErrTemplateNotVersioned, l.Repository, l.Name)
+	}
+
+	if ref == "" {
+		if r, err := t.client.Repositories().Get(l.Repository); err == nil && r.remote == l.URL {
+			ref = r.commit
+		}
+	}
+
+	var from, to *object.Tree
+	if isOCIURI(l.URL) {
+		opts := t.client.Repositories().pullOptions(ctx)
+		if ref == "" {
+			if ref, err = latestOCIRef(l.URL, l.Ref, opts...); err != nil {
+				return "", err
+			}
+		}
+		if from, err = ociTemplateTree(l.URL, l.Commit, l.Path, opts...); err != nil {
+			return "", err
+		}
+		if to, err = ociTemplateTree(l.URL, ref, l.Path, opts...); err != nil {
+			return "", err
+		}
+	} else {
+		repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+			URL:               l.URL,
+			Tags:              git.AllTags,
+			RecurseSubmodules: git.NoRecurseSubmodules,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to clone repository %v: %w", l.URL, err)
+		}
+		if from, err = templateTree(repo, l.Commit, l.Path); err != nil {
+			return "", err
+		}
+		if to, err = templateTree(repo, ref, l.Path); err != nil {
+			return "", err
+		}
+	}
+
+	changes, err := object.DiffTree(from, to)
+	if err != nil {
+		return "", err
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return "", err
+	}
+	return patch.String(), nil
+}
+
+// templateTree returns the tree of the template at the given path as of the
+// given ref of the repository.  An empty ref is the repository's HEAD.
+func templateTree(repo *git.Repository, ref, path string) (*object.Tree, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		// Branches other than the default are only available as remotes.
+		if hash, err = repo.ResolveRevision(plumbing.Revision("origin/" + ref)); err != nil {
+			return nil, fmt.Errorf("ref %v not found: %w", ref, err)
+		}
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	if tree, err = tree.Tree(path); errors.Is(err, object.ErrDirectoryNotFound) {
+		return nil, fmt.Errorf("template %v not found at %v: %w", path, ref, ErrTemplateNotFound)
+	}
+	return tree, err
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -605,3 +606,157 @@ func TestTemplates_InvocationDefault(t *testing.T) {
 		t.Fatalf("expected '%v' invoke format.  Got '%v'", expectedInvoke, f.Invoke)
 	}
 }
+
+// TestTemplates_Diff ensures that a function records the version of the
+// template it was created from, and that changes to the template since can be
+// shown.
+func TestTemplates_Diff(t *testing.T) {
+	skipIfNoGit(t) // see docs
+	uri, commits := ServeVersionedRepo(t)
+	root := FromTempDirectory(t)
+	repos := filepath.Join(root, "repositories")
+
+	client := fn.New(fn.WithRepositoriesPath(repos))
+	if _, err := client.Repositories().Add(t.Context(), "versioned", uri+"#v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	f, err := client.Init(fn.Function{
+		Root:     filepath.Join(root, "f"),
+		Runtime:  "go",
+		Template: "versioned/versioned",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := fn.ReadLock(f.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fn.TemplateLock{
+		Repository: "versioned",
+		URL:        uri,
+		Ref:        "v1.0.0",
+		Commit:     commits[0],
+		Runtime:    "go",
+		Name:       "versioned",
+		Path:       "go/versioned",
+	}
+	if diff := cmp.Diff(expected, lock.Template); diff != "" {
+		t.Fatalf("unexpected lock (-want, +got): %v", diff)
+	}
+
+	// Unchanged as long as the installed repository is at the locked version
+	diff, err := client.Templates().Diff(t.Context(), f.Root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Fatalf("expected no changes, got:\n%v", diff)
+	}
+
+	// Changed once the installed repository was updated
+	if _, err = client.Repositories().Update(t.Context(), "versioned", ""); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err = client.Templates().Diff(t.Context(), f.Root, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "-// Version 1") || !strings.Contains(diff, "+// Version 2") {
+		t.Fatalf("expected changes from version 1 to 2, got:\n%v", diff)
+	}
+
+	// Or up to an explicit ref
+	if diff, err = client.Templates().Diff(t.Context(), f.Root, "main"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "+// Version 3") {
+		t.Fatalf("expected changes from version 1 to 3, got:\n%v", diff)
+	}
+
+	// Functions from the embedded repository are not versioned
+	f, err = client.Init(fn.Function{Root: filepath.Join(root, "embedded"), Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Templates().Diff(t.Context(), f.Root, ""); !errors.Is(err, fn.ErrTemplateNotVersioned) {
+		t.Fatalf("expected ErrTemplateNotVersioned, got %v", err)
+	}
+}
+
+// TestTemplates_Parameters ensures that parameters declared by a template are
+// rendered into the names of its files and the contents of those named
+// *.tmpl, and recorded in the function's lock file.
+func TestTemplates_Parameters(t *testing.T) {
+	root := FromTempDirectory(t)
+	repo := filepath.Join(root, "repository")
+	files := map[string]string{
+		"go/params/manifest.yaml": `parameters:
+  - name: greeting
+    default: Hello
+  - name: module
+    required: true
+    pattern: ^[a-z./]+$
+  - name: docs
+    type: bool
+`,
+		"go/params/handle.go.tmpl":                    "// {{.greeting}} from {{.module}}\n",
+		"go/params/{{.greeting | lower}}.txt.tmpl":    "{{if .docs}}documented{{else}}undocumented{{end}}",
+		"go/params/{{if .docs}}docs{{end}}/README.md": "docs",
+		"go/params/.github/workflows/ci.yaml":         "image: example.com/f:${{ github.sha }}\n",
+		"go/params/chart/deployment.yaml":             "image: {{ .Values.image }}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := fn.New(fn.WithRepository("file://" + filepath.ToSlash(repo)))
+
+	// Required parameters must be provided
+	_, err := client.Init(fn.Function{Root: filepath.Join(root, "a"), Runtime: "go", Template: "params"})
+	if !errors.Is(err, fn.ErrTemplateParameterRequired) {
+		t.Fatalf("expected ErrTemplateParameterRequired, got %v", err)
+	}
+
+	f, err := client.Init(fn.Function{
+		Root:               filepath.Join(root, "b"),
+		Runtime:            "go",
+		Template:           "params",
+		TemplateParameters: map[string]string{"module": "example.com/f"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Files not named *.tmpl are copied as-is, even if containing braces
+	expected := map[string]string{
+		"handle.go":                 "// Hello from example.com/f\n",
+		"hello.txt":                 "undocumented",
+		".github/workflows/ci.yaml": files["go/params/.github/workflows/ci.yaml"],
+		"chart/deployment.yaml":     files["go/params/chart/deployment.yaml"],
+	}
+	for name, content := range expected {
+		bb, err := os.ReadFile(filepath.Join(f.Root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bb) != content {
+			t.Errorf("expected %v to contain %q, got %q", name, content, bb)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(f.Root, "docs")); !os.IsNotExist(err) {
+		t.Errorf("expected the conditional docs directory to be omitted, got %v", err)
+	}
+
+	lock, err := fn.ReadLock(f.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]string{"greeting": "Hello", "module": "example.com/f"}, lock.Template.Parameters); diff != "" {
+		t.Errorf("unexpected locked parameters (-want, +got): %v", diff)
+	}
+}
@@ -0,0 +1,118 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TestAddressEnv is the environment variable which, when running tests in
+// integration mode, contains the address (URL) of the running function.
+const TestAddressEnv = "FUNC_TEST_ADDRESS"
+
+// TestResult is the outcome of running a function's test suite.
+type TestResult struct {
+	// Runtime of the function tested.
+	Runtime string `json:"runtime" yaml:"runtime"`
+	// Command which was executed to run the tests.
+	Command string `json:"command" yaml:"command"`
+	// Passed is true when the test command exited successfully.
+	Passed bool `json:"passed" yaml:"passed"`
+	// ExitCode of the test command.
+	ExitCode int `json:"exitCode" yaml:"exitCode"`
+	// Duration of the test command.
+	Duration time.Duration `json:"duration" yaml:"duration"`
+	// Address of the running function when tested in integration mode.
+	Address string `json:"address,omitempty" yaml:"address,omitempty"`
+}
+
+type defaultTester struct {
+	verbose bool
+	out     io.Writer
+	err     io.Writer
+}
+
+// NewTester returns a Tester which runs the language-native test tool of
+// the function's runtime (go test, pytest, npm test etc.) on the host,
+// writing the tool's output to out and err.
+func NewTester(verbose bool, out, err io.Writer) Tester {
+	return &defaultTester{
+		verbose: verbose,
+		out:     out,
+		err:     err,
+	}
+}
+
+// Test the function by running its runtime's test command in the function's
+// root with the given additional arguments and environment variables.
+// A failing test suite is not an error: it is reported via TestResult.Passed.
+func (t *defaultTester) Test(ctx context.Context, f Function, args, env []string) (result TestResult, err error) {
+	name, cmdArgs, err := testCommand(f)
+	if err != nil {
+		return
+	}
+	cmdArgs = append(cmdArgs, args...)
+
+	result.Runtime = f.Runtime
+	result.Command = strings.TrimSpace(name + " " + strings.Join(cmdArgs, " "))
+
+	if t.verbose {
+		fmt.Fprintf(t.err, "cd %v && %v\n", f.Root, result.Command)
+	}
+
+	cmd := exec.CommandContext(ctx, name, cmdArgs...)
+	cmd.Dir = f.Root
+	cmd.Stdout = t.out
+	cmd.Stderr = t.err
+	cmd.Env = append(os.Environ(), env...)
+
+	start := time.Now()
+	err = cmd.Run()
+	result.Duration = time.Since(start)
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		return result, nil // tests ran, but did not pass
+	}
+	if err != nil {
+		return result, fmt.Errorf("unable to run %q. %w", result.Command, err)
+	}
+	result.Passed = true
+	return
+}
+
+// testCommand returns the executable and arguments which run the test suite
+// of the given function's runtime.
+func testCommand(f Function) (name string, args []string, err error) {
+	switch f.Runtime {
+	case "":
+		err = ErrRuntimeRequired
+	case "go":
+		name = os.Getenv("FUNC_GO") // Use if provided
+		if name == "" {
+			name = "go"
+		}
+		args = []string{"test", "./..."}
+	case "python":
+		name, args = pythonCmd(), []string{"-m", "pytest"}
+	case "node", "typescript":
+		name, args = "npm", []string{"test"}
+	case "quarkus", "springboot":
+		name, args = "mvn", []string{"test"}
+		if _, statErr := os.Stat(filepath.Join(f.Root, "mvnw")); statErr == nil {
+			name = "./mvnw"
+		}
+	case "rust":
+		name, args = "cargo", []string{"test"}
+	default:
+		err = ErrRuntimeNotRecognized{f.Runtime}
+	}
+	return
+}
@@ -0,0 +1,60 @@
+package functions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestTestCommand ensures that each known runtime maps to its language-native
+// test tool, and that unknown or missing runtimes are reported as such.
+func TestTestCommand(t *testing.T) {
+	tests := []struct {
+		Runtime    string
+		Command    string
+		ExpectedIs error
+		ExpectedAs any
+	}{
+		{"", "", ErrRuntimeRequired, nil},
+		{"go", "go", nil, nil},
+		{"python", pythonCmd(), nil, nil},
+		{"node", "npm", nil, nil},
+		{"typescript", "npm", nil, nil},
+		{"quarkus", "mvn", nil, nil},
+		{"springboot", "mvn", nil, nil},
+		{"rust", "cargo", nil, nil},
+		{"other", "", nil, &ErrRuntimeNotRecognized{}},
+	}
+	for _, test := range tests {
+		t.Run(test.Runtime, func(t *testing.T) {
+			t.Setenv("FUNC_GO", "")
+			name, _, err := testCommand(Function{Root: t.TempDir(), Runtime: test.Runtime})
+			if test.ExpectedIs != nil && !errors.Is(err, test.ExpectedIs) {
+				t.Fatalf("expected error %v, got %v", test.ExpectedIs, err)
+			}
+			if test.ExpectedAs != nil && !errors.As(err, test.ExpectedAs) {
+				t.Fatalf("did not receive expected error type for %v runtime.", test.Runtime)
+			}
+			if name != test.Command {
+				t.Fatalf("expected command %q, got %q", test.Command, name)
+			}
+		})
+	}
+}
+
+// TestTestCommand_MavenWrapper ensures that the maven wrapper is preferred
+// when present in the function's root.
+func TestTestCommand_MavenWrapper(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "mvnw"), []byte{}, 0755); err != nil {
+		t.Fatal(err)
+	}
+	name, _, err := testCommand(Function{Root: root, Runtime: "quarkus"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "./mvnw" {
+		t.Fatalf("expected maven wrapper, got %q", name)
+	}
+}
@@ -0,0 +1,118 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDebounce is the time without further changes after which
+// changes to a function's source are reported by Watch.
+const DefaultWatchDebounce = 500 * time.Millisecond
+
+// Watch the source of the function at root for changes, ignoring the same
+// directories as Fingerprint. Changes are debounced: the paths changed,
+// relative to root, are sent once there were no further changes for the
+// given duration. The channel is closed when the context is done.
+func Watch(ctx context.Context, root string, debounce time.Duration) (<-chan []string, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// add the directory and its subdirectories, as fsnotify is not recursive
+	add := func(dir string) error {
+		return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			if path != root && ignoredSourceDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return w.Add(path)
+		})
+	}
+	if err = add(root); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	changes := make(chan []string)
+	go func() {
+		defer close(changes)
+		defer w.Close()
+
+		var (
+			changed = map[string]bool{}
+			timer   = time.NewTimer(debounce)
+			fire    <-chan time.Time
+		)
+		timer.Stop()
+		touch := func(path string) {
+			changed[path] = true
+			timer.Reset(debounce)
+			fire = timer.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				rel, err := filepath.Rel(root, e.Name)
+				if err != nil || ignoredSourcePath(rel) || e.Op == fsnotify.Chmod {
+					continue
+				}
+				if e.Has(fsnotify.Create) {
+					if fi, err := os.Stat(e.Name); err == nil && fi.IsDir() {
+						_ = add(e.Name)
+					}
+				}
+				touch(rel)
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				if errors.Is(err, fsnotify.ErrEventOverflow) {
+					touch(".") // events were lost, consider everything changed
+				}
+			case <-fire:
+				fire = nil
+				paths := make([]string, 0, len(changed))
+				for path := range changed {
+					paths = append(paths, path)
+				}
+				sort.Strings(paths)
+				changed = map[string]bool{}
+				select {
+				case changes <- paths:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// ignoredSourcePath returns true if the path, relative to the function's
+// root, is within a directory which is not part of the function's source.
+func ignoredSourcePath(rel string) bool {
+	for _, name := range strings.Split(filepath.ToSlash(rel), "/") {
+		if ignoredSourceDir(name) {
+			return true
+		}
+	}
+	return false
+}
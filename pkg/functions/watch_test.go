@@ -0,0 +1,55 @@
+package functions_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestWatch ensures changes to the function's source are reported once
+// debounced, and that directories ignored when fingerprinting are ignored.
+func TestWatch(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, fn.RunDataDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := fn.Watch(ctx, root, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// changes to ignored directories are not reported
+	if err = os.WriteFile(filepath.Join(root, fn.RunDataDir, "built-image"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// changes in new subdirectories are, debounced into one notification
+	if err = os.MkdirAll(filepath.Join(root, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond) // let the new directory be watched
+	if err = os.WriteFile(filepath.Join(root, "pkg", "handle.go"), []byte("package pkg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case paths := <-changes:
+		expected := []string{"pkg", filepath.Join("pkg", "handle.go")}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Errorf("expected changes %v, got %v", expected, paths)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for changes")
+	}
+
+	cancel()
+	for range changes {
+	} // closed once the context is done
+}
@@ -0,0 +1,172 @@
+// Package gateway routes requests to the functions running locally by name,
+// such that http://<name>.localhost:<port> reaches the function <name>
+// regardless of the port it was started on.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultAddress on which the gateway listens.
+	DefaultAddress = "127.0.0.1:8090"
+
+	// DefaultRetryInterval at which a gateway whose address is taken, for
+	// example by the gateway of another `func run`, retries to listen.
+	DefaultRetryInterval = 2 * time.Second
+
+	// DefaultRefreshInterval at which the gateway reads the registered
+	// instances anew.
+	DefaultRefreshInterval = time.Second
+)
+
+// Gateway is a reverse proxy to the instances of a Registry.
+//
+// Every `func run` runs a gateway on the same address, of which only one
+// can listen at a time.  The others keep retrying, taking over when the
+// listening one exits, such that routes remain available as long as any
+// function is running.
+type Gateway struct {
+	registry *Registry
+	address  string
+	next     atomic.Uint64 // round robin among instances of a function
+
+	mu        sync.Mutex
+	cached    []Instance // registered instances, as of refreshed
+	refreshed time.Time
+}
+
+// New gateway to the instances of the registry, listening on the address.
+func New(registry *Registry, address string) *Gateway {
+	return &Gateway{registry: registry, address: address}
+}
+
+// Route returns the URL of the function with the given name on the gateway
+// listening on the given address.
+func Route(name, address string) string {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		port = address
+	}
+	return fmt.Sprintf("http://%s.localhost:%s", name, port)
+}
+
+// Run the gateway until the context is done.  While its address is taken,
+// listening is retried.
+func (g *Gateway) Run(ctx context.Context) error {
+	var lc net.ListenConfig
+	for {
+		l, err := lc.Listen(ctx, "tcp", g.address)
+		if err == nil {
+			return g.serve(ctx, l)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(DefaultRetryInterval):
+		}
+	}
+}
+
+func (g *Gateway) serve(ctx context.Context, l net.Listener) error {
+	server := &http.Server{Handler: g, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP proxies the request to an instance of the function named by the
+// request's host, <name>.localhost.  Requests to other hosts are answered
+// with the routes of the running functions.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	instances, err := g.instances()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	name, ok := strings.CutSuffix(host, ".localhost")
+	if !ok {
+		writeRoutes(w, http.StatusOK, instances)
+		return
+	}
+
+	var matching []Instance
+	for _, i := range instances {
+		if i.Name == name {
+			matching = append(matching, i)
+		}
+	}
+	if len(matching) == 0 {
+		writeRoutes(w, http.StatusNotFound, instances)
+		return
+	}
+
+	target := matching[int(g.next.Add(1)%uint64(len(matching)))]
+	proxy := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: target.Address})
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		// Instances which stopped without unregistering, such as killed
+		// processes, are no longer routed to.  Those restarting, such as
+		// when watched, register again once started.
+		if g.registry.prune(target) {
+			g.invalidate()
+		}
+		http.Error(w, fmt.Sprintf("function %v unavailable: %v", name, err), http.StatusBadGateway)
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// instances registered, read anew at most every DefaultRefreshInterval.
+func (g *Gateway) instances() ([]Instance, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cached != nil && time.Since(g.refreshed) < DefaultRefreshInterval {
+		return g.cached, nil
+	}
+	instances, err := g.registry.Instances()
+	if err != nil {
+		return nil, err
+	}
+	g.cached, g.refreshed = instances, time.Now()
+	return instances, nil
+}
+
+// invalidate the cached instances, such that they are read anew.
+func (g *Gateway) invalidate() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cached = nil
+}
+
+func writeRoutes(w http.ResponseWriter, status int, instances []Instance) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	if len(instances) == 0 {
+		fmt.Fprintln(w, "No functions running locally")
+		return
+	}
+	for _, i := range instances {
+		fmt.Fprintf(w, "%s\t%s\n", i.Route, i.Address)
+	}
+}
@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRegistry ensures instances are listed while registered, and that those
+// no longer accepting connections are removed when listing those running.
+func TestRegistry(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "instances")
+	registry := NewRegistry(dir)
+
+	if ii, err := registry.Instances(); err != nil || len(ii) != 0 {
+		t.Fatalf("expected no instances of an empty registry, got %v, %v", ii, err)
+	}
+
+	running := httptest.NewServer(http.NotFoundHandler())
+	defer running.Close()
+	unregister, err := registry.Register(Instance{Name: "b", Address: running.Listener.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stopped := httptest.NewServer(http.NotFoundHandler())
+	if _, err = registry.Register(Instance{Name: "a", Address: stopped.Listener.Addr().String()}); err != nil {
+		t.Fatal(err)
+	}
+	stopped.Close()
+
+	ii, err := registry.Instances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ii) != 2 || ii[0].Name != "a" || ii[1].Name != "b" {
+		t.Fatalf("expected both registered instances, got %v", ii)
+	}
+
+	if ii, err = registry.Running(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ii) != 1 || ii[0].Name != "b" {
+		t.Fatalf("expected the running instance only, got %v", ii)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("expected the stopped instance to be removed, got %d entries", len(entries))
+	}
+
+	if err = unregister(); err != nil {
+		t.Fatal(err)
+	}
+	if ii, _ = registry.Instances(); len(ii) != 0 {
+		t.Fatalf("expected no instances once unregistered, got %v", ii)
+	}
+}
+
+// TestGateway ensures requests are routed by name to the instances of a
+// function, round robin.
+func TestGateway(t *testing.T) {
+	registry := NewRegistry(t.TempDir())
+	for _, body := range []string{"one", "two"} {
+		body := body
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, body+" "+r.URL.Path)
+		}))
+		defer server.Close()
+		address := server.Listener.Addr().String()
+		if _, err := registry.Register(Instance{Name: "f", Address: address, Route: Route("f", DefaultAddress)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gateway := New(registry, DefaultAddress)
+
+	get := func(host, path string) (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "http://"+host+path, nil)
+		rec := httptest.NewRecorder()
+		gateway.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	seen := map[string]bool{}
+	for range 2 {
+		code, body := get("f.localhost:8090", "/hello")
+		if code != http.StatusOK || !strings.HasSuffix(body, " /hello") {
+			t.Fatalf("unexpected response %d %q", code, body)
+		}
+		seen[strings.Fields(body)[0]] = true
+	}
+	if !seen["one"] || !seen["two"] {
+		t.Fatalf("expected both instances to be routed to, got %v", seen)
+	}
+
+	if code, body := get("g.localhost:8090", "/"); code != http.StatusNotFound || !strings.Contains(body, "http://f.localhost:8090") {
+		t.Fatalf("expected not found with the routes, got %d %q", code, body)
+	}
+	if code, body := get("localhost:8090", "/"); code != http.StatusOK || !strings.Contains(body, "http://f.localhost:8090") {
+		t.Fatalf("expected the routes, got %d %q", code, body)
+	}
+}
+
+// TestGateway_Unavailable ensures an instance which stopped without
+// unregistering is answered with a bad gateway and no longer routed to, while
+// an instance registered anew, such as on restart, is.
+func TestGateway_Unavailable(t *testing.T) {
+	registry := NewRegistry(t.TempDir())
+	stopped := httptest.NewServer(http.NotFoundHandler())
+	address := stopped.Listener.Addr().String()
+	if _, err := registry.Register(Instance{Name: "f", Address: address}); err != nil {
+		t.Fatal(err)
+	}
+	stopped.Close()
+	gateway := New(registry, DefaultAddress)
+
+	get := func() (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "http://f.localhost:8090/", nil)
+		rec := httptest.NewRecorder()
+		gateway.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	if code, _ := get(); code != http.StatusBadGateway {
+		t.Fatalf("expected bad gateway, got %d", code)
+	}
+	if ii, _ := registry.Instances(); len(ii) != 0 {
+		t.Fatalf("expected the stopped instance to be removed, got %v", ii)
+	}
+
+	restarted := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "restarted")
+	}))
+	defer restarted.Close()
+	if _, err := registry.Register(Instance{Name: "f", Address: restarted.Listener.Addr().String()}); err != nil {
+		t.Fatal(err)
+	}
+	if code, body := get(); code != http.StatusOK || body != "restarted" {
+		t.Fatalf("expected the restarted instance, got %d %q", code, body)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	if r := Route("f", "127.0.0.1:8090"); r != "http://f.localhost:8090" {
+		t.Fatalf("unexpected route %q", r)
+	}
+}
@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultDialTimeout when checking whether an instance is still running.
+const DefaultDialTimeout = 200 * time.Millisecond
+
+// Instance of a function running locally.
+type Instance struct {
+	Name    string    `json:"name" yaml:"name"`
+	Runtime string    `json:"runtime" yaml:"runtime"`
+	Root    string    `json:"root" yaml:"root"`
+	Address string    `json:"address" yaml:"address"` // host:port of the function
+	Route   string    `json:"route" yaml:"route"`     // URL of the function on the gateway
+	Started time.Time `json:"started" yaml:"started"`
+}
+
+// Registry of the functions running locally, shared by all `func run`
+// processes of the user.  Each instance is a file of the registry directory,
+// such that processes need not coordinate.
+type Registry struct {
+	dir string
+}
+
+// NewRegistry returns a registry persisted in the given directory.
+func NewRegistry(dir string) *Registry {
+	return &Registry{dir: dir}
+}
+
+// Register the instance, returning a function which unregisters it.
+func (r *Registry) Register(i Instance) (unregister func() error, err error) {
+	path, err := r.path(i)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(r.dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("cannot create instance registry: %w", err)
+	}
+	bb, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(path, bb, 0o644); err != nil {
+		return nil, fmt.Errorf("cannot register instance: %w", err)
+	}
+	return func() error { return r.Remove(i) }, nil
+}
+
+// Remove the registration of the instance.
+func (r *Registry) Remove(i Instance) error {
+	path, err := r.path(i)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path of the file registering the instance.
+func (r *Registry) path(i Instance) (string, error) {
+	_, port, err := net.SplitHostPort(i.Address)
+	if err != nil {
+		return "", fmt.Errorf("invalid instance address %q: %w", i.Address, err)
+	}
+	return filepath.Join(r.dir, i.Name+"-"+port+".json"), nil
+}
+
+// Instances returns the registered instances, ordered by name and address.
+// Instances are not checked to be running: registrations of processes which
+// were killed remain until removed, such as by Running.
+func (r *Registry) Instances() ([]Instance, error) {
+	entries, err := os.ReadDir(r.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Instance{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot read instance registry: %w", err)
+	}
+	instances := []Instance{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(r.dir, e.Name())
+		bb, err := os.ReadFile(path)
+		if err != nil {
+			continue // unregistered concurrently
+		}
+		var i Instance
+		if err = json.Unmarshal(bb, &i); err != nil {
+			_ = os.Remove(path)
+			continue
+		}
+		instances = append(instances, i)
+	}
+	sort.Slice(instances, func(a, b int) bool {
+		if instances[a].Name != instances[b].Name {
+			return instances[a].Name < instances[b].Name
+		}
+		return instances[a].Address < instances[b].Address
+	})
+	return instances, nil
+}
+
+// Running returns the registered instances which accept connections,
+// removing the registrations of the others.
+func (r *Registry) Running() ([]Instance, error) {
+	instances, err := r.Instances()
+	if err != nil {
+		return nil, err
+	}
+	running := []Instance{}
+	for _, i := range instances {
+		if !r.prune(i) {
+			running = append(running, i)
+		}
+	}
+	return running, nil
+}
+
+// prune the registration of the instance if it does not accept connections,
+// returning true if it was removed.
+func (r *Registry) prune(i Instance) bool {
+	if running(i.Address) {
+		return false
+	}
+	_ = r.Remove(i)
+	return true
+}
+
+// running returns true if the address accepts connections.
+func running(address string) bool {
+	conn, err := net.DialTimeout("tcp", address, DefaultDialTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,226 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	cloudAPIURL = "https://api.bitbucket.org/2.0"
+
+	webhookDescription = "Pipelines as Code"
+)
+
+// CloudClient manages webhooks of Bitbucket Cloud repositories.  The token
+// is a repository, project or workspace access token with the webhook scope.
+type CloudClient struct {
+	BaseURL             string // API URL, defaults to https://api.bitbucket.org/2.0
+	PersonalAccessToken string
+}
+
+type cloudHook struct {
+	UUID        string   `json:"uuid,omitempty"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Active      bool     `json:"active"`
+	Secret      string   `json:"secret,omitempty"`
+	Events      []string `json:"events"`
+}
+
+type cloudHookPage struct {
+	Values []cloudHook `json:"values"`
+	Next   string      `json:"next"`
+}
+
+func newCloudHook(payloadURL, webhookSecret string) cloudHook {
+	return cloudHook{
+		Description: webhookDescription,
+		URL:         payloadURL,
+		Active:      true,
+		Secret:      webhookSecret,
+		Events: []string{
+			"repo:push",
+			"pullrequest:created",
+			"pullrequest:updated",
+			"pullrequest:comment_created",
+		},
+	}
+}
+
+func (c CloudClient) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) error {
+	return do(ctx, c.PersonalAccessToken, http.MethodPost, c.hooksURL(repoOwner, repoName), newCloudHook(payloadURL, webhookSecret), nil)
+}
+
+func (c CloudClient) UpdateWebHookSecret(ctx context.Context, repoOwner, repoName, id, payloadURL, webhookSecret string) error {
+	return updateHook(ctx, c.PersonalAccessToken, c.hooksURL(repoOwner, repoName)+"/"+url.PathEscape(id), func(hook map[string]any) {
+		hook["secret"] = webhookSecret
+	})
+}
+
+func (c CloudClient) DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error {
+	return do(ctx, c.PersonalAccessToken, http.MethodDelete, c.hooksURL(repoOwner, repoName)+"/"+url.PathEscape(id), nil, nil)
+}
+
+func (c CloudClient) ListWebHooks(ctx context.Context, repoOwner, repoName string) (map[string]string, error) {
+	hooks := map[string]string{}
+	next := c.hooksURL(repoOwner, repoName) + "?pagelen=100"
+	for next != "" {
+		var page cloudHookPage
+		if err := do(ctx, c.PersonalAccessToken, http.MethodGet, next, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, hook := range page.Values {
+			hooks[hook.UUID] = hook.URL
+		}
+		next = page.Next
+	}
+	return hooks, nil
+}
+
+func (c CloudClient) hooksURL(workspace, repoSlug string) string {
+	base := c.BaseURL
+	if base == "" {
+		base = cloudAPIURL
+	}
+	return fmt.Sprintf("%s/repositories/%s/%s/hooks", base, url.PathEscape(workspace), url.PathEscape(repoSlug))
+}
+
+// ServerClient manages webhooks of Bitbucket Server and Data Center
+// repositories, identified by their project key and repository slug.  The
+// token is an HTTP access token with repository admin permission.
+type ServerClient struct {
+	BaseURL             string
+	PersonalAccessToken string
+}
+
+type serverHook struct {
+	ID            int               `json:"id,omitempty"`
+	Name          string            `json:"name"`
+	URL           string            `json:"url"`
+	Active        bool              `json:"active"`
+	Events        []string          `json:"events"`
+	Configuration map[string]string `json:"configuration,omitempty"`
+}
+
+type serverHookPage struct {
+	Values        []serverHook `json:"values"`
+	IsLastPage    bool         `json:"isLastPage"`
+	NextPageStart int          `json:"nextPageStart"`
+}
+
+func newServerHook(payloadURL, webhookSecret string) serverHook {
+	return serverHook{
+		Name:   webhookDescription,
+		URL:    payloadURL,
+		Active: true,
+		Events: []string{
+			"repo:refs_changed",
+			"pr:opened",
+			"pr:from_ref_updated",
+			"pr:comment:added",
+		},
+		Configuration: map[string]string{"secret": webhookSecret},
+	}
+}
+
+func (c ServerClient) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) error {
+	return do(ctx, c.PersonalAccessToken, http.MethodPost, c.hooksURL(repoOwner, repoName), newServerHook(payloadURL, webhookSecret), nil)
+}
+
+func (c ServerClient) UpdateWebHookSecret(ctx context.Context, repoOwner, repoName, id, payloadURL, webhookSecret string) error {
+	return updateHook(ctx, c.PersonalAccessToken, c.hooksURL(repoOwner, repoName)+"/"+url.PathEscape(id), func(hook map[string]any) {
+		cfg, _ := hook["configuration"].(map[string]any)
+		if cfg == nil {
+			cfg = map[string]any{}
+		}
+		cfg["secret"] = webhookSecret
+		hook["configuration"] = cfg
+	})
+}
+
+func (c ServerClient) DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error {
+	return do(ctx, c.PersonalAccessToken, http.MethodDelete, c.hooksURL(repoOwner, repoName)+"/"+url.PathEscape(id), nil, nil)
+}
+
+func (c ServerClient) ListWebHooks(ctx context.Context, repoOwner, repoName string) (map[string]string, error) {
+	hooks := map[string]string{}
+	start := 0
+	for {
+		var page serverHookPage
+		pageURL := c.hooksURL(repoOwner, repoName) + "?limit=100&start=" + strconv.Itoa(start)
+		if err := do(ctx, c.PersonalAccessToken, http.MethodGet, pageURL, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, hook := range page.Values {
+			hooks[strconv.Itoa(hook.ID)] = hook.URL
+		}
+		if page.IsLastPage || len(page.Values) == 0 {
+			return hooks, nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+func (c ServerClient) hooksURL(projectKey, repoSlug string) string {
+	return fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/webhooks", c.BaseURL, url.PathEscape(projectKey), url.PathEscape(repoSlug))
+}
+
+// updateHook replaces the webhook at hookURL with itself as changed by
+// update.  The webhook is read first as the Bitbucket APIs replace webhooks
+// whole, such that settings not managed here, like SSL verification, are
+// preserved.
+func updateHook(ctx context.Context, token, hookURL string, update func(hook map[string]any)) error {
+	var hook map[string]any
+	if err := do(ctx, token, http.MethodGet, hookURL, nil, &hook); err != nil {
+		return err
+	}
+	if hook == nil {
+		hook = map[string]any{}
+	}
+	update(hook)
+	return do(ctx, token, http.MethodPut, hookURL, hook, nil)
+}
+
+// do sends a request with an optional JSON body to the Bitbucket API,
+// decoding the JSON response into out if given.
+func do(ctx context.Context, token, method, url string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		raw, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		payload, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return fmt.Errorf("bitbucket request %s %s failed, status code: %v, error: %s", method, url, res.StatusCode, payload)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
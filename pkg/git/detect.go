@@ -0,0 +1,146 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	gitHubHost    = "github.com"
+	gitLabHost    = "gitlab.com"
+	bitBucketHost = "bitbucket.org"
+)
+
+// knownHosts are the public instances of the supported providers, which
+// are recognized without probing.
+var knownHosts = map[string]string{
+	gitHubHost:     GitHubProvider,
+	gitLabHost:     GitLabProvider,
+	bitBucketHost:  BitBucketProvider,
+	"codeberg.org": GiteaProvider,
+	"gitea.com":    GiteaProvider,
+}
+
+// probeTimeout limits the time spent probing a single provider's API.
+const probeTimeout = 5 * time.Second
+
+var defaultHTTPClient = &http.Client{Timeout: probeTimeout}
+
+// probe checks whether the instance at base is of a provider by requesting
+// an unauthenticated API endpoint and inspecting the response.
+type probe struct {
+	provider string
+	path     string
+	match    func(res *http.Response, body map[string]any) bool
+}
+
+// probes are tried in order.  Each requests an endpoint which is specific
+// to the provider's API, such that other servers answer with an error.
+var probes = []probe{
+	{
+		provider: GiteaProvider, // also Forgejo
+		path:     "/api/v1/version",
+		match: func(res *http.Response, body map[string]any) bool {
+			_, ok := body["version"]
+			return res.StatusCode == http.StatusOK && ok
+		},
+	},
+	{
+		provider: GitLabProvider,
+		path:     "/api/v4/version",
+		match: func(res *http.Response, body map[string]any) bool {
+			if res.StatusCode == http.StatusUnauthorized {
+				return body["message"] == "401 Unauthorized"
+			}
+			_, ok := body["version"]
+			return res.StatusCode == http.StatusOK && ok
+		},
+	},
+	{
+		provider: BitBucketServerProvider,
+		path:     "/rest/api/1.0/application-properties",
+		match: func(res *http.Response, body map[string]any) bool {
+			return res.StatusCode == http.StatusOK && body["displayName"] == "Bitbucket"
+		},
+	},
+	{
+		provider: GitHubProvider, // GitHub Enterprise Server
+		path:     "/api/v3/meta",
+		match: func(res *http.Response, body map[string]any) bool {
+			_, ok := body["installed_version"]
+			return res.StatusCode == http.StatusOK && (ok || res.Header.Get("X-GitHub-Enterprise-Version") != "")
+		},
+	},
+}
+
+var errUnknownProvider = errors.New("unknown git provider")
+
+// detectProvider returns the provider of the instance at base, which is
+// either a well-known host or detected by probing the instance's API.
+func detectProvider(ctx context.Context, client *http.Client, base string) (string, error) {
+	if provider, ok := knownHosts[hostOf(base)]; ok {
+		return provider, nil
+	}
+	for _, p := range probes {
+		if matchProbe(ctx, client, base, p) {
+			return p.provider, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+	}
+	return "", errUnknownProvider
+}
+
+func matchProbe(ctx context.Context, client *http.Client, base string, p probe) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+p.path, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	body := map[string]any{}
+	_ = json.NewDecoder(res.Body).Decode(&body) // non-JSON responses do not match
+	return p.match(res, body)
+}
+
+// baseURL returns the scheme and host of a repository URL, converting SSH
+// URLs (git@host:owner/repo.git) to HTTPS.
+func baseURL(repoURL string) (string, error) {
+	if strings.HasPrefix(repoURL, "git@") {
+		host, _, _ := strings.Cut(strings.TrimPrefix(repoURL, "git@"), ":")
+		return "https://" + host, nil
+	}
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse git repo url: %w", err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("git repo url %q has no host", repoURL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		// the SSH port of ssh:// and git:// URLs is not the API's port
+		return "https://" + u.Hostname(), nil
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+func hostOf(base string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
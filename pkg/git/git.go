@@ -2,26 +2,36 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/openshift-pipelines/pipelines-as-code/pkg/formatting"
 
+	"knative.dev/func/pkg/git/bitbucket"
+	"knative.dev/func/pkg/git/gitea"
 	"knative.dev/func/pkg/git/github"
 	"knative.dev/func/pkg/git/gitlab"
 )
 
 const (
-	GitHubProvider    = "github"
-	GitLabProvider    = "gitlab"
-	BitBucketProvider = "bitbucket-cloud"
+	GitHubProvider          = "github"
+	GitLabProvider          = "gitlab"
+	BitBucketProvider       = "bitbucket-cloud"
+	BitBucketServerProvider = "bitbucket-server"
+	GiteaProvider           = "gitea" // Gitea and Forgejo
 )
 
 type SupportedProviders []string
 
-var SupportedProvidersList = SupportedProviders{GitHubProvider, GitLabProvider}
+var SupportedProvidersList = SupportedProviders{GitHubProvider, GitLabProvider, BitBucketProvider, BitBucketServerProvider, GiteaProvider}
+
+// ErrWebHookExists is returned when creating a webhook for a payload URL
+// which already has a webhook on the repository.
+var ErrWebHookExists = errors.New("webhook already exists")
 
 func (sp SupportedProviders) PrettyString() string {
 	var b strings.Builder
@@ -37,16 +47,17 @@ func (sp SupportedProviders) PrettyString() string {
 	return b.String()
 }
 
-func GitProviderName(url string) (string, error) {
-	switch {
-	case strings.Contains(url, "github"):
-		return GitHubProvider, nil
-	case strings.Contains(url, "gitlab"):
-		return GitLabProvider, nil
-	case strings.Contains(url, "bitbucket-cloud"):
-		//return BitBucketProvider, nil
-	}
-	return "", fmt.Errorf("runtime for url %q is not supported, please use one of supported runtimes: %s", url, SupportedProvidersList.PrettyString())
+// GitProviderName returns the provider hosting the repository at the given
+// url.  Well-known hosts are recognized directly, self-hosted instances are
+// detected by probing the APIs of the supported providers.
+func GitProviderName(ctx context.Context, url string) (string, error) {
+	base, err := baseURL(url)
+	if err == nil {
+		if provider, err := detectProvider(ctx, defaultHTTPClient, base); err == nil {
+			return provider, nil
+		}
+	}
+	return "", fmt.Errorf("git provider for url %q is not supported, please use one of supported providers: %s", url, SupportedProvidersList.PrettyString())
 }
 
 // RepoOwnerAndNameFromUrl for input url returns repo owner and repo name
@@ -71,35 +82,83 @@ func RepoOwnerAndNameFromUrl(url string) (string, string, error) {
 	return repoOwner, repoName, nil
 }
 
-func CreateWebHook(ctx context.Context, gitRepoURL, webHookTarget, webHookSecret, personalAccessToken string) error {
-	providerName, err := GitProviderName(gitRepoURL)
+// repoOwnerAndName returns the owner and name of the repository as used by
+// the provider's API.  Bitbucket Server repositories are identified by their
+// project key and slug, as found in both clone (/scm/KEY/slug.git) and
+// browse (/projects/KEY/repos/slug) URLs.
+func repoOwnerAndName(provider, repoURL string) (string, string, error) {
+	if provider != BitBucketServerProvider {
+		return RepoOwnerAndNameFromUrl(repoURL)
+	}
+	u, err := url.Parse(repoURL)
 	if err != nil {
-		return err
+		return "", "", fmt.Errorf("cannot parse git repo url: %w", err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case len(parts) == 2: // ssh://git@host:7999/KEY/slug.git
+		return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+	case len(parts) == 3 && parts[0] == "scm":
+		return parts[1], strings.TrimSuffix(parts[2], ".git"), nil
+	case len(parts) >= 4 && parts[0] == "projects" && parts[2] == "repos":
+		return parts[1], parts[3], nil
 	}
+	return "", "", fmt.Errorf("invalid Bitbucket Server repository url %q, needs to be of format 'https://host/scm/project-key/repo-name.git'", repoURL)
+}
 
-	u, err := url.Parse(gitRepoURL)
+// ProviderURL returns the URL of a self-hosted provider's instance as
+// expected by the Pipelines as Code Repository, or an empty string for the
+// public instances of GitHub, GitLab and Bitbucket Cloud.
+func ProviderURL(provider, repoURL string) (string, error) {
+	base, err := baseURL(repoURL)
 	if err != nil {
-		return fmt.Errorf("cannot parse git repo url: %w", err)
+		return "", err
 	}
-
-	var cli providerClient
-	switch providerName {
+	switch provider {
 	case GitHubProvider:
-		cli = github.Client{
-			PersonalAccessToken: personalAccessToken,
+		if hostOf(base) == gitHubHost {
+			return "", nil
 		}
+		return base + "/api/v3", nil // GitHub Enterprise Server
 	case GitLabProvider:
-		cli = gitlab.Client{
-			BaseURL:             u.Scheme + "://" + u.Host,
-			PersonalAccessToken: personalAccessToken,
+		if hostOf(base) == gitLabHost {
+			return "", nil
 		}
+		return base, nil
+	case BitBucketProvider:
+		return "", nil
+	case BitBucketServerProvider:
+		return base + "/rest", nil
 	}
+	return base, nil
+}
+
+// WebHook is a repository webhook.
+type WebHook struct {
+	ID  string
+	URL string
+}
 
-	repoOwner, repoName, err := RepoOwnerAndNameFromUrl(gitRepoURL)
+// CreateWebHook creates a webhook calling webHookTarget on events of the
+// repository.  If gitProvider is empty it is detected from the repository
+// url.  ErrWebHookExists is returned if the repository already has a webhook
+// for webHookTarget.
+func CreateWebHook(ctx context.Context, gitProvider, gitRepoURL, webHookTarget, webHookSecret, personalAccessToken string) error {
+	cli, repoOwner, repoName, err := newProviderClient(ctx, gitProvider, gitRepoURL, personalAccessToken)
 	if err != nil {
 		return err
 	}
 
+	hooks, err := cli.ListWebHooks(ctx, repoOwner, repoName)
+	if err != nil {
+		return fmt.Errorf("cannot list existing web hooks: %w", err)
+	}
+	for _, hookURL := range hooks {
+		if hookURL == webHookTarget {
+			return ErrWebHookExists
+		}
+	}
+
 	err = cli.CreateWebHook(ctx, repoOwner, repoName, webHookTarget, webHookSecret)
 	if err != nil {
 		return fmt.Errorf("cannot create web hook: %w", err)
@@ -107,6 +166,130 @@ func CreateWebHook(ctx context.Context, gitRepoURL, webHookTarget, webHookSecret
 	return nil
 }
 
+// DeleteWebHook deletes the webhooks of the repository which call
+// webHookTarget, returning the number of webhooks deleted.
+func DeleteWebHook(ctx context.Context, gitProvider, gitRepoURL, webHookTarget, personalAccessToken string) (int, error) {
+	cli, repoOwner, repoName, err := newProviderClient(ctx, gitProvider, gitRepoURL, personalAccessToken)
+	if err != nil {
+		return 0, err
+	}
+
+	hooks, err := cli.ListWebHooks(ctx, repoOwner, repoName)
+	if err != nil {
+		return 0, fmt.Errorf("cannot list existing web hooks: %w", err)
+	}
+	deleted := 0
+	for id, hookURL := range hooks {
+		if hookURL != webHookTarget {
+			continue
+		}
+		if err = cli.DeleteWebHook(ctx, repoOwner, repoName, id); err != nil {
+			return deleted, fmt.Errorf("cannot delete web hook: %w", err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// UpdateWebHookSecret sets the payload validation secret of the webhooks of
+// the repository which call webHookTarget, returning the number of webhooks
+// updated.
+func UpdateWebHookSecret(ctx context.Context, gitProvider, gitRepoURL, webHookTarget, webHookSecret, personalAccessToken string) (int, error) {
+	cli, repoOwner, repoName, err := newProviderClient(ctx, gitProvider, gitRepoURL, personalAccessToken)
+	if err != nil {
+		return 0, err
+	}
+
+	hooks, err := cli.ListWebHooks(ctx, repoOwner, repoName)
+	if err != nil {
+		return 0, fmt.Errorf("cannot list existing web hooks: %w", err)
+	}
+	updated := 0
+	for id, hookURL := range hooks {
+		if hookURL != webHookTarget {
+			continue
+		}
+		if err = cli.UpdateWebHookSecret(ctx, repoOwner, repoName, id, webHookTarget, webHookSecret); err != nil {
+			return updated, fmt.Errorf("cannot update web hook: %w", err)
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// ListWebHooks returns the webhooks of the repository.
+func ListWebHooks(ctx context.Context, gitProvider, gitRepoURL, personalAccessToken string) ([]WebHook, error) {
+	cli, repoOwner, repoName, err := newProviderClient(ctx, gitProvider, gitRepoURL, personalAccessToken)
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := cli.ListWebHooks(ctx, repoOwner, repoName)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]WebHook, 0, len(hooks))
+	for id, hookURL := range hooks {
+		result = append(result, WebHook{ID: id, URL: hookURL})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func newProviderClient(ctx context.Context, providerName, gitRepoURL, personalAccessToken string) (cli providerClient, repoOwner, repoName string, err error) {
+	if providerName == "" {
+		if providerName, err = GitProviderName(ctx, gitRepoURL); err != nil {
+			return
+		}
+	}
+
+	base, err := baseURL(gitRepoURL)
+	if err != nil {
+		return
+	}
+	apiURL, err := ProviderURL(providerName, gitRepoURL)
+	if err != nil {
+		return
+	}
+
+	switch providerName {
+	case GitHubProvider:
+		cli = github.Client{
+			BaseURL:             apiURL,
+			PersonalAccessToken: personalAccessToken,
+		}
+	case GitLabProvider:
+		cli = gitlab.Client{
+			BaseURL:             base,
+			PersonalAccessToken: personalAccessToken,
+		}
+	case BitBucketProvider:
+		cli = bitbucket.CloudClient{
+			PersonalAccessToken: personalAccessToken,
+		}
+	case BitBucketServerProvider:
+		cli = bitbucket.ServerClient{
+			BaseURL:             base,
+			PersonalAccessToken: personalAccessToken,
+		}
+	case GiteaProvider:
+		cli = gitea.Client{
+			BaseURL:             base,
+			PersonalAccessToken: personalAccessToken,
+		}
+	default:
+		err = fmt.Errorf("git provider %q is not supported, please use one of supported providers: %s", providerName, SupportedProvidersList.PrettyString())
+		return
+	}
+
+	repoOwner, repoName, err = repoOwnerAndName(providerName, gitRepoURL)
+	return
+}
+
 type providerClient interface {
 	CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) error
+	DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error
+	UpdateWebHookSecret(ctx context.Context, repoOwner, repoName, id, payloadURL, webhookSecret string) error
+	// ListWebHooks returns the payload URLs of the repository's webhooks
+	// keyed by webhook ID.
+	ListWebHooks(ctx context.Context, repoOwner, repoName string) (map[string]string, error)
 }
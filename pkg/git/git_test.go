@@ -1,6 +1,18 @@
 package git
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"knative.dev/func/pkg/git/github"
+)
 
 func TestGetRepoOwnerFromGHURL(t *testing.T) {
 	tests := []struct {
@@ -55,6 +67,9 @@ func TestGetRepoOwnerFromGHURL(t *testing.T) {
 }
 
 func TestGitProviderName(t *testing.T) {
+	unknown := httptest.NewServer(http.NotFoundHandler())
+	defer unknown.Close()
+
 	tests := []struct {
 		name         string
 		url          string
@@ -74,14 +89,20 @@ func TestGitProviderName(t *testing.T) {
 			wantErr:      false,
 		},
 		{
-			name: "Bitbucket Cloud - not supported",
-			url:  "https://bitbucket.com/foo/bar",
-			//wantProvider: BitBucketProvider,
-			wantErr: true,
+			name:         "Bitbucket Cloud",
+			url:          "https://bitbucket.org/foo/bar",
+			wantProvider: BitBucketProvider,
+			wantErr:      false,
+		},
+		{
+			name:         "Codeberg",
+			url:          "git@codeberg.org:foo/bar.git",
+			wantProvider: GiteaProvider,
+			wantErr:      false,
 		},
 		{
 			name:         "Foo provider - not supported",
-			url:          "https://foo.com/foo/bar",
+			url:          unknown.URL + "/foo/bar",
 			wantProvider: "",
 			wantErr:      true,
 		},
@@ -94,7 +115,7 @@ func TestGitProviderName(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotProvider, err := GitProviderName(tt.url)
+			gotProvider, err := GitProviderName(context.Background(), tt.url)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ProviderGitProviderName() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -105,3 +126,234 @@ func TestGitProviderName(t *testing.T) {
 		})
 	}
 }
+
+// TestGitProviderName_Probing ensures self-hosted instances are detected by
+// their API rather than by their hostname.
+func TestGitProviderName_Probing(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		status       int
+		header       http.Header
+		body         string
+		wantProvider string
+	}{
+		{"Gitea", "/api/v1/version", http.StatusOK, nil, `{"version":"1.22.0"}`, GiteaProvider},
+		{"Forgejo", "/api/v1/version", http.StatusOK, nil, `{"version":"9.0.0+gitea-1.22.0"}`, GiteaProvider},
+		{"GitLab", "/api/v4/version", http.StatusUnauthorized, nil, `{"message":"401 Unauthorized"}`, GitLabProvider},
+		{"Bitbucket Server", "/rest/api/1.0/application-properties", http.StatusOK, nil, `{"version":"8.19.0","displayName":"Bitbucket"}`, BitBucketServerProvider},
+		{"GitHub Enterprise", "/api/v3/meta", http.StatusOK, http.Header{"X-Github-Enterprise-Version": {"3.14.0"}}, `{}`, GitHubProvider},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a hostname which names another provider must not matter
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"message":"401 Unauthorized"}`))
+					return
+				}
+				for k, v := range tt.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := GitProviderName(context.Background(), server.URL+"/github/repo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.wantProvider {
+				t.Errorf("expected provider %q, got %q", tt.wantProvider, got)
+			}
+		})
+	}
+}
+
+func TestProviderURL(t *testing.T) {
+	tests := []struct {
+		provider string
+		url      string
+		want     string
+	}{
+		{GitHubProvider, "https://github.com/foo/bar", ""},
+		{GitHubProvider, "https://ghe.example.com/foo/bar", "https://ghe.example.com/api/v3"},
+		{GitLabProvider, "https://gitlab.com/foo/bar", ""},
+		{GitLabProvider, "https://gitlab.example.com/foo/bar", "https://gitlab.example.com"},
+		{BitBucketProvider, "https://bitbucket.org/foo/bar", ""},
+		{BitBucketServerProvider, "https://bitbucket.example.com/scm/foo/bar.git", "https://bitbucket.example.com/rest"},
+		{GiteaProvider, "git@codeberg.org:foo/bar.git", "https://codeberg.org"},
+		{GiteaProvider, "http://gitea.local:3000/foo/bar", "http://gitea.local:3000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider+" "+tt.url, func(t *testing.T) {
+			got, err := ProviderURL(tt.provider, tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRepoOwnerAndName_BitbucketServer(t *testing.T) {
+	for _, url := range []string{
+		"https://bitbucket.example.com/scm/PROJ/repo.git",
+		"https://bitbucket.example.com/projects/PROJ/repos/repo/browse",
+		"ssh://git@bitbucket.example.com:7999/PROJ/repo.git",
+	} {
+		owner, name, err := repoOwnerAndName(BitBucketServerProvider, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if owner != "PROJ" || name != "repo" {
+			t.Errorf("%s: expected PROJ/repo, got %s/%s", url, owner, name)
+		}
+	}
+}
+
+// TestWebHooks ensures webhooks are created, listed, updated and deleted
+// using the APIs of the self-hosted providers, updates leaving their other
+// settings as they are.
+func TestWebHooks(t *testing.T) {
+	const target = "https://pac.example.com"
+
+	tests := []struct {
+		name     string
+		provider string
+		repo     string
+		hooks    string // path of the repository's hooks
+		auth     string
+		list     func(hooks map[string]string) string
+		hook     string // a webhook as returned by the provider, if read before updates
+	}{
+		{
+			name:     "Gitea",
+			provider: GiteaProvider,
+			repo:     "/foo/bar",
+			hooks:    "/api/v1/repos/foo/bar/hooks",
+			auth:     "token secret-token",
+			list: func(hooks map[string]string) string {
+				var items []string
+				for id, url := range hooks {
+					items = append(items, fmt.Sprintf(`{"id":%s,"config":{"url":%q}}`, id, url))
+				}
+				return "[" + strings.Join(items, ",") + "]"
+			},
+		},
+		{
+			name:     "Bitbucket Server",
+			provider: BitBucketServerProvider,
+			repo:     "/scm/PROJ/bar.git",
+			hooks:    "/rest/api/1.0/projects/PROJ/repos/bar/webhooks",
+			auth:     "Bearer secret-token",
+			list: func(hooks map[string]string) string {
+				var items []string
+				for id, url := range hooks {
+					items = append(items, fmt.Sprintf(`{"id":%s,"url":%q}`, id, url))
+				}
+				return `{"isLastPage":true,"values":[` + strings.Join(items, ",") + "]}"
+			},
+			hook: `{"id":2,"url":"https://pac.example.com","sslVerificationRequired":false,"configuration":{"secret":"webhook-secret"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hooks := map[string]string{"1": "https://other.example.com"}
+			secrets := map[string]string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != tt.auth {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == tt.hooks:
+					_, _ = w.Write([]byte(tt.list(hooks)))
+				case r.Method == http.MethodGet && tt.hook != "" && r.URL.Path == tt.hooks+"/2":
+					_, _ = w.Write([]byte(tt.hook))
+				case r.Method == http.MethodPost && r.URL.Path == tt.hooks:
+					raw, _ := io.ReadAll(r.Body)
+					if !strings.Contains(string(raw), target) {
+						t.Errorf("payload URL missing from request: %s", raw)
+					}
+					hooks["2"] = target
+					w.WriteHeader(http.StatusCreated)
+				case (r.Method == http.MethodPatch || r.Method == http.MethodPut) && strings.HasPrefix(r.URL.Path, tt.hooks+"/"):
+					raw, _ := io.ReadAll(r.Body)
+					if tt.hook != "" && !strings.Contains(string(raw), `"sslVerificationRequired":false`) {
+						t.Errorf("webhook settings not preserved: %s", raw)
+					}
+					if strings.Contains(string(raw), "rotated-secret") {
+						secrets[strings.TrimPrefix(r.URL.Path, tt.hooks+"/")] = "rotated-secret"
+					}
+				case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, tt.hooks+"/"):
+					delete(hooks, strings.TrimPrefix(r.URL.Path, tt.hooks+"/"))
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			ctx := context.Background()
+			repo := server.URL + tt.repo
+
+			if err := CreateWebHook(ctx, tt.provider, repo, target, "webhook-secret", "secret-token"); err != nil {
+				t.Fatal(err)
+			}
+			if err := CreateWebHook(ctx, tt.provider, repo, target, "webhook-secret", "secret-token"); !errors.Is(err, ErrWebHookExists) {
+				t.Fatalf("expected ErrWebHookExists, got %v", err)
+			}
+			listed, err := ListWebHooks(ctx, tt.provider, repo, "secret-token")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(listed) != 2 || listed[1] != (WebHook{ID: "2", URL: target}) {
+				t.Fatalf("unexpected webhooks %v", listed)
+			}
+			updated, err := UpdateWebHookSecret(ctx, tt.provider, repo, target, "rotated-secret", "secret-token")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if updated != 1 || len(secrets) != 1 || secrets["2"] == "" {
+				t.Fatalf("expected only the webhook for %s to be updated, updated: %v", target, secrets)
+			}
+			deleted, err := DeleteWebHook(ctx, tt.provider, repo, target, "secret-token")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != 1 || len(hooks) != 1 || hooks["1"] == "" {
+				t.Fatalf("expected only the webhook for %s to be deleted, remaining: %v", target, hooks)
+			}
+		})
+	}
+}
+
+// TestUpdateWebHookSecret_GitHub ensures that only the secret of a GitHub
+// webhook is updated, leaving its other settings, such as SSL verification,
+// as they are.
+func TestUpdateWebHookSecret_GitHub(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/api/v3/repos/foo/bar/hooks/2/config" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cli := github.Client{BaseURL: server.URL, PersonalAccessToken: "secret-token"}
+	if err := cli.UpdateWebHookSecret(context.Background(), "foo", "bar", "2", "https://pac.example.com", "rotated-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 1 || body["secret"] != "rotated-secret" {
+		t.Fatalf("expected only the secret to be sent, got %v", body)
+	}
+}
@@ -0,0 +1,116 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Client manages webhooks of Gitea and Forgejo repositories.  The token is
+// an access token with the write:repository scope.
+type Client struct {
+	BaseURL             string
+	PersonalAccessToken string
+}
+
+type hook struct {
+	ID     int64             `json:"id,omitempty"`
+	Type   string            `json:"type"`
+	Active bool              `json:"active"`
+	Events []string          `json:"events"`
+	Config map[string]string `json:"config"`
+}
+
+func newHook(payloadURL, webhookSecret string) hook {
+	return hook{
+		Type:   "gitea",
+		Active: true,
+		Events: []string{
+			"push",
+			"pull_request",
+			"issue_comment",
+		},
+		Config: map[string]string{
+			"url":          payloadURL,
+			"content_type": "json",
+			"secret":       webhookSecret,
+		},
+	}
+}
+
+func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) error {
+	return c.do(ctx, http.MethodPost, c.hooksURL(repoOwner, repoName), newHook(payloadURL, webhookSecret), nil)
+}
+
+func (c Client) UpdateWebHookSecret(ctx context.Context, repoOwner, repoName, id, payloadURL, webhookSecret string) error {
+	return c.do(ctx, http.MethodPatch, c.hooksURL(repoOwner, repoName)+"/"+url.PathEscape(id), newHook(payloadURL, webhookSecret), nil)
+}
+
+func (c Client) DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error {
+	return c.do(ctx, http.MethodDelete, c.hooksURL(repoOwner, repoName)+"/"+url.PathEscape(id), nil, nil)
+}
+
+func (c Client) ListWebHooks(ctx context.Context, repoOwner, repoName string) (map[string]string, error) {
+	const limit = 50
+	hooks := map[string]string{}
+	for p := 1; ; p++ {
+		var page []hook
+		pageURL := fmt.Sprintf("%s?limit=%d&page=%d", c.hooksURL(repoOwner, repoName), limit, p)
+		if err := c.do(ctx, http.MethodGet, pageURL, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, h := range page {
+			hooks[strconv.FormatInt(h.ID, 10)] = h.Config["url"]
+		}
+		if len(page) < limit {
+			return hooks, nil
+		}
+	}
+}
+
+func (c Client) hooksURL(repoOwner, repoName string) string {
+	return fmt.Sprintf("%s/api/v1/repos/%s/%s/hooks", c.BaseURL, url.PathEscape(repoOwner), url.PathEscape(repoName))
+}
+
+// do sends a request with an optional JSON body to the Gitea API, decoding
+// the JSON response into out if given.
+func (c Client) do(ctx context.Context, method, url string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		raw, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(raw)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "token "+c.PersonalAccessToken)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		payload, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return fmt.Errorf("gitea request %s %s failed, status code: %v, error: %s", method, url, res.StatusCode, payload)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/google/go-github/v68/github"
 	"golang.org/x/oauth2"
 )
 
 type Client struct {
+	BaseURL             string // API URL of GitHub Enterprise Server, empty for github.com
 	PersonalAccessToken string
 }
 
@@ -31,7 +33,7 @@ func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadU
 		},
 	}
 
-	ghClient, err := newGHClientByToken(ctx, c.PersonalAccessToken, "")
+	ghClient, err := newGHClientByToken(ctx, c.PersonalAccessToken, c.BaseURL)
 	if err != nil {
 		return err
 	}
@@ -54,6 +56,62 @@ func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadU
 	return nil
 }
 
+func (c Client) DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error {
+	hookID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid github webhook id %q: %w", id, err)
+	}
+
+	ghClient, err := newGHClientByToken(ctx, c.PersonalAccessToken, c.BaseURL)
+	if err != nil {
+		return err
+	}
+
+	_, err = ghClient.Repositories.DeleteHook(ctx, repoOwner, repoName, hookID)
+	return err
+}
+
+func (c Client) UpdateWebHookSecret(ctx context.Context, repoOwner, repoName, id, payloadURL, webhookSecret string) error {
+	hookID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid github webhook id %q: %w", id, err)
+	}
+
+	ghClient, err := newGHClientByToken(ctx, c.PersonalAccessToken, c.BaseURL)
+	if err != nil {
+		return err
+	}
+
+	// Only the secret is sent, leaving the rest of the configuration as is.
+	_, _, err = ghClient.Repositories.EditHookConfiguration(ctx, repoOwner, repoName, hookID, &github.HookConfig{
+		Secret: github.Ptr(webhookSecret),
+	})
+	return err
+}
+
+func (c Client) ListWebHooks(ctx context.Context, repoOwner, repoName string) (map[string]string, error) {
+	ghClient, err := newGHClientByToken(ctx, c.PersonalAccessToken, c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := map[string]string{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, res, err := ghClient.Repositories.ListHooks(ctx, repoOwner, repoName, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, hook := range page {
+			hooks[strconv.FormatInt(hook.GetID(), 10)] = hook.GetConfig().GetURL()
+		}
+		if res.NextPage == 0 {
+			return hooks, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
 func newGHClientByToken(ctx context.Context, personalAccessToken, ghApiURL string) (*github.Client, error) {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: personalAccessToken},
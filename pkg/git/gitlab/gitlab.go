@@ -3,6 +3,7 @@ package gitlab
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
@@ -15,26 +16,13 @@ type Client struct {
 func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadURL, webhookSecret string) error {
 	t := true
 	f := false
-	glabCli, err := gitlab.NewClient(c.PersonalAccessToken,
-		gitlab.WithBaseURL(c.BaseURL),
-		gitlab.WithRequestOptions(gitlab.WithContext(ctx)))
+	glabCli, err := c.newClient(ctx)
 	if err != nil {
-		return fmt.Errorf("cannot create GitLab client: %w", err)
+		return err
 	}
 
 	projectPath := repoOwner + "/" + repoName
 
-	existingHooks, _, err := glabCli.Projects.ListProjectHooks(projectPath, nil)
-	if err != nil {
-		return fmt.Errorf("cannot list existing hooks: %w", err)
-	}
-	for _, hook := range existingHooks {
-		if hook.URL == payloadURL {
-			fmt.Printf("GitLab webhook already exists for project %s at URL: %s\n", projectPath, payloadURL)
-			return nil
-		}
-	}
-
 	webhook := &gitlab.AddProjectHookOptions{
 		EnableSSLVerification: &f,
 		PushEvents:            &t,
@@ -48,3 +36,75 @@ func (c Client) CreateWebHook(ctx context.Context, repoOwner, repoName, payloadU
 	}
 	return nil
 }
+
+func (c Client) DeleteWebHook(ctx context.Context, repoOwner, repoName, id string) error {
+	glabCli, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	hookID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid gitlab webhook id %q: %w", id, err)
+	}
+
+	_, err = glabCli.Projects.DeleteProjectHook(repoOwner+"/"+repoName, hookID)
+	if err != nil {
+		return fmt.Errorf("cannot delete gitlab webhook: %w", err)
+	}
+	return nil
+}
+
+func (c Client) UpdateWebHookSecret(ctx context.Context, repoOwner, repoName, id, payloadURL, webhookSecret string) error {
+	glabCli, err := c.newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	hookID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid gitlab webhook id %q: %w", id, err)
+	}
+
+	_, _, err = glabCli.Projects.EditProjectHook(repoOwner+"/"+repoName, hookID, &gitlab.EditProjectHookOptions{
+		URL:   &payloadURL,
+		Token: &webhookSecret,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot update gitlab webhook: %w", err)
+	}
+	return nil
+}
+
+func (c Client) ListWebHooks(ctx context.Context, repoOwner, repoName string) (map[string]string, error) {
+	glabCli, err := c.newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := map[string]string{}
+	opts := &gitlab.ListProjectHooksOptions{PerPage: 100}
+	for {
+		page, res, err := glabCli.Projects.ListProjectHooks(repoOwner+"/"+repoName, opts)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list existing hooks: %w", err)
+		}
+		for _, hook := range page {
+			hooks[strconv.Itoa(hook.ID)] = hook.URL
+		}
+		if res.NextPage == 0 {
+			return hooks, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+func (c Client) newClient(ctx context.Context) (*gitlab.Client, error) {
+	glabCli, err := gitlab.NewClient(c.PersonalAccessToken,
+		gitlab.WithBaseURL(c.BaseURL),
+		gitlab.WithRequestOptions(gitlab.WithContext(ctx)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create GitLab client: %w", err)
+	}
+	return glabCli, nil
+}
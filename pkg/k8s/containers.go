@@ -0,0 +1,128 @@
+package k8s
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// SetAdditionalContainers sets the init containers and sidecars of the
+// function on a pod spec whose first container runs the function. Volumes
+// mounted by these containers are added to the pod's volumes.
+func SetAdditionalContainers(podSpec *corev1.PodSpec, f fn.Function, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string]) error {
+	initContainers, initVolumes, err := ProcessContainers(f.Deploy.InitContainers, referencedSecrets, referencedConfigMaps, referencedPVCs)
+	if err != nil {
+		return fmt.Errorf("failed to process init containers: %w", err)
+	}
+	sidecars, sidecarVolumes, err := ProcessContainers(f.Deploy.Sidecars, referencedSecrets, referencedConfigMaps, referencedPVCs)
+	if err != nil {
+		return fmt.Errorf("failed to process sidecars: %w", err)
+	}
+
+	podSpec.InitContainers = nil
+	if len(initContainers) > 0 {
+		podSpec.InitContainers = initContainers
+	}
+	podSpec.Containers = append(podSpec.Containers[:1], sidecars...)
+	podSpec.Volumes = MergeVolumes(MergeVolumes(podSpec.Volumes, initVolumes), sidecarVolumes)
+
+	// With several containers, the one serving requests must declare its
+	// port, which Knative requires explicitly.
+	if len(sidecars) > 0 && len(podSpec.Containers[0].Ports) == 0 {
+		podSpec.Containers[0].Ports = []corev1.ContainerPort{{ContainerPort: DefaultHTTPPort}}
+	}
+	return nil
+}
+
+// ProcessContainers generates the init containers or sidecars of a function
+// from its config, together with the volumes they mount. Secrets, ConfigMaps
+// and PVCs referenced by the containers are added to the referenced sets.
+func ProcessContainers(cc []fn.Container, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string]) ([]corev1.Container, []corev1.Volume, error) {
+	containers := []corev1.Container{}
+	volumes := []corev1.Volume{}
+
+	for _, c := range cc {
+		env, envFrom, err := processEnvs(c.Envs, referencedSecrets, referencedConfigMaps)
+		if err != nil {
+			return nil, nil, fmt.Errorf("container %q: %w", c.Name, err)
+		}
+		vv, mounts, err := ProcessVolumes(c.Volumes, referencedSecrets, referencedConfigMaps, referencedPVCs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("container %q: %w", c.Name, err)
+		}
+		resources, err := processContainerResources(c.Resources)
+		if err != nil {
+			return nil, nil, fmt.Errorf("container %q: %w", c.Name, err)
+		}
+
+		container := corev1.Container{
+			Name:         c.Name,
+			Image:        c.Image,
+			Command:      c.Command,
+			Args:         c.Args,
+			Env:          env,
+			EnvFrom:      envFrom,
+			VolumeMounts: mounts,
+			Resources:    resources,
+		}
+		SetSecurityContext(&container, c.SecurityContext)
+
+		containers = append(containers, container)
+		volumes = MergeVolumes(volumes, vv)
+	}
+	return containers, volumes, nil
+}
+
+// MergeVolumes appends the volumes which are not yet present, by name, to
+// the given volumes. Containers mounting the same Secret, ConfigMap or PVC
+// share a single volume of the pod.
+func MergeVolumes(volumes []corev1.Volume, more []corev1.Volume) []corev1.Volume {
+	names := sets.New[string]()
+	for _, v := range volumes {
+		names.Insert(v.Name)
+	}
+	for _, v := range more {
+		if !names.Has(v.Name) {
+			volumes = append(volumes, v)
+			names.Insert(v.Name)
+		}
+	}
+	return volumes
+}
+
+func processContainerResources(r *fn.ContainerResources) (resources corev1.ResourceRequirements, err error) {
+	if r == nil {
+		return
+	}
+	if resources.Requests, err = processResourceList(r.Requests); err != nil {
+		return
+	}
+	resources.Limits, err = processResourceList(r.Limits)
+	return
+}
+
+func processResourceList(r *fn.ResourcesRequestsOptions) (corev1.ResourceList, error) {
+	if r == nil {
+		return nil, nil
+	}
+	list := corev1.ResourceList{}
+	if r.CPU != nil {
+		value, err := resource.ParseQuantity(*r.CPU)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu quantity %q: %w", *r.CPU, err)
+		}
+		list[corev1.ResourceCPU] = value
+	}
+	if r.Memory != nil {
+		value, err := resource.ParseQuantity(*r.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory quantity %q: %w", *r.Memory, err)
+		}
+		list[corev1.ResourceMemory] = value
+	}
+	return list, nil
+}
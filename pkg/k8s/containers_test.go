@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/pkg/ptr"
+)
+
+func Test_SetAdditionalContainers(t *testing.T) {
+	f := fn.Function{
+		Deploy: fn.DeploySpec{
+			InitContainers: []fn.Container{{
+				Name:    "migrate",
+				Image:   "example.com/migrate",
+				Envs:    fn.Envs{{Name: ptr.String("DSN"), Value: ptr.String("{{ secret:db:dsn }}")}},
+				Volumes: []fn.Volume{{Secret: ptr.String("db"), Path: ptr.String("/etc/db")}},
+			}},
+			Sidecars: []fn.Container{{
+				Name:      "proxy",
+				Image:     "example.com/proxy",
+				Volumes:   []fn.Volume{{Secret: ptr.String("db"), Path: ptr.String("/etc/db")}},
+				Resources: &fn.ContainerResources{Limits: &fn.ResourcesRequestsOptions{Memory: ptr.String("64Mi")}},
+			}},
+		},
+	}
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "user-container"}, {Name: "stale-sidecar"}},
+		Volumes:    []corev1.Volume{{Name: "secret-db"}},
+	}
+
+	secrets, configMaps, pvcs := sets.New[string](), sets.New[string](), sets.New[string]()
+	if err := SetAdditionalContainers(&podSpec, f, &secrets, &configMaps, &pvcs); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Name != "migrate" {
+		t.Fatalf("unexpected init containers %v", podSpec.InitContainers)
+	}
+	if env := podSpec.InitContainers[0].Env; len(env) != 1 || env[0].ValueFrom.SecretKeyRef.Name != "db" {
+		t.Errorf("init container env not processed: %v", podSpec.InitContainers[0].Env)
+	}
+	if len(podSpec.Containers) != 2 || podSpec.Containers[1].Name != "proxy" {
+		t.Fatalf("expected the function and proxy containers, got %v", podSpec.Containers)
+	}
+	if podSpec.Containers[1].Resources.Limits.Memory().String() != "64Mi" {
+		t.Errorf("unexpected sidecar resources %v", podSpec.Containers[1].Resources)
+	}
+	if podSpec.Containers[1].SecurityContext == nil || !*podSpec.Containers[1].SecurityContext.RunAsNonRoot {
+		t.Errorf("expected the default security context on sidecars")
+	}
+	if len(podSpec.Containers[0].Ports) != 1 || podSpec.Containers[0].Ports[0].ContainerPort != DefaultHTTPPort {
+		t.Errorf("expected the function container to declare its port, got %v", podSpec.Containers[0].Ports)
+	}
+	if len(podSpec.Volumes) != 1 {
+		t.Errorf("expected the secret volume to be shared, got %v", podSpec.Volumes)
+	}
+	if !secrets.Has("db") {
+		t.Errorf("expected the secret to be referenced")
+	}
+
+	// removing them from func.yaml removes them from the pod
+	f.Deploy.InitContainers, f.Deploy.Sidecars = nil, nil
+	if err := SetAdditionalContainers(&podSpec, f, &secrets, &configMaps, &pvcs); err != nil {
+		t.Fatal(err)
+	}
+	if len(podSpec.InitContainers) != 0 || len(podSpec.Containers) != 1 {
+		t.Errorf("expected only the function container, got %v and %v", podSpec.InitContainers, podSpec.Containers)
+	}
+}
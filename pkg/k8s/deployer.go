@@ -21,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
 	clienteventingv1 "knative.dev/client/pkg/eventing/v1"
 	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
 	eventingv1client "knative.dev/eventing/pkg/client/clientset/versioned/typed/eventing/v1"
@@ -405,7 +406,7 @@ func (d *Deployer) generateDeployment(f fn.Function, namespace string, daprInsta
 	}
 
 	SetHealthEndpoints(f, &container)
-	SetSecurityContext(&container)
+	SetSecurityContext(&container, f.Deploy.SecurityContext)
 
 	replicas := int32(1)
 	if f.Deploy.Options.Scale != nil && f.Deploy.Options.Scale.Min != nil && *f.Deploy.Options.Scale.Min > 0 {
@@ -438,6 +439,11 @@ func (d *Deployer) generateDeployment(f fn.Function, namespace string, daprInsta
 		},
 	}
 
+	SetPodSecurityContext(&deployment.Spec.Template.Spec, f.Deploy.SecurityContext)
+	if err = SetAdditionalContainers(&deployment.Spec.Template.Spec, f, &referencedSecrets, &referencedConfigMaps, &referencedPVCs); err != nil {
+		return nil, err
+	}
+
 	return deployment, nil
 }
 
@@ -551,8 +557,9 @@ func SetHealthEndpoints(f fn.Function, container *corev1.Container) {
 	}
 }
 
-// SetSecurityContext configures security settings for a container
-func SetSecurityContext(container *corev1.Container) {
+// SetSecurityContext configures security settings for a container.
+// The function's security context, if any, is applied over the defaults.
+func SetSecurityContext(container *corev1.Container, sc *fn.SecurityContext) {
 	runAsNonRoot := true
 	allowPrivilegeEscalation := false
 	capabilities := corev1.Capabilities{
@@ -567,6 +574,64 @@ func SetSecurityContext(container *corev1.Container) {
 		Capabilities:             &capabilities,
 		SeccompProfile:           &seccompProfile,
 	}
+	if sc == nil {
+		return
+	}
+
+	csc := container.SecurityContext
+	if sc.RunAsUser != nil {
+		csc.RunAsUser = ptr.To(*sc.RunAsUser)
+	}
+	if sc.RunAsGroup != nil {
+		csc.RunAsGroup = ptr.To(*sc.RunAsGroup)
+	}
+	if sc.RunAsNonRoot != nil {
+		csc.RunAsNonRoot = ptr.To(*sc.RunAsNonRoot)
+	}
+	if sc.ReadOnlyRootFilesystem != nil {
+		csc.ReadOnlyRootFilesystem = ptr.To(*sc.ReadOnlyRootFilesystem)
+	}
+	if sc.AllowPrivilegeEscalation != nil {
+		csc.AllowPrivilegeEscalation = ptr.To(*sc.AllowPrivilegeEscalation)
+	}
+	if sc.SeccompProfile != nil {
+		csc.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileType(sc.SeccompProfile.Type)}
+		if sc.SeccompProfile.LocalhostProfile != "" {
+			csc.SeccompProfile.LocalhostProfile = ptr.To(sc.SeccompProfile.LocalhostProfile)
+		}
+	}
+	if sc.Capabilities != nil {
+		// Capabilities which are set replace the defaults, such that dropping
+		// only some capabilities is possible.
+		if sc.Capabilities.Add != nil {
+			csc.Capabilities.Add = toCapabilities(sc.Capabilities.Add)
+		}
+		if sc.Capabilities.Drop != nil {
+			csc.Capabilities.Drop = toCapabilities(sc.Capabilities.Drop)
+		}
+	}
+}
+
+// SetPodSecurityContext configures the pod level security settings of the
+// function. Only settings which have no container level counterpart, such as
+// the fsGroup, are set on the pod. The pod security context is left unset
+// when the function does not configure any of them.
+func SetPodSecurityContext(podSpec *corev1.PodSpec, sc *fn.SecurityContext) {
+	podSpec.SecurityContext = nil
+	if sc == nil || sc.FSGroup == nil {
+		return
+	}
+	podSpec.SecurityContext = &corev1.PodSecurityContext{
+		FSGroup: ptr.To(*sc.FSGroup),
+	}
+}
+
+func toCapabilities(cc []string) []corev1.Capability {
+	capabilities := make([]corev1.Capability, 0, len(cc))
+	for _, c := range cc {
+		capabilities = append(capabilities, corev1.Capability(c))
+	}
+	return capabilities
 }
 
 // ProcessEnvs generates array of EnvVars and EnvFromSources from a function config
@@ -585,7 +650,17 @@ func ProcessEnvs(envs []fn.Env, referencedSecrets, referencedConfigMaps *sets.Se
 
 	envs = withOpenAddress(envs) // prepends ADDRESS=0.0.0.0 if not extant
 
-	envVars := []corev1.EnvVar{{Name: "BUILT", Value: time.Now().Format("20060102T150405")}}
+	envVars, envFrom, err := processEnvs(envs, referencedSecrets, referencedConfigMaps)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append([]corev1.EnvVar{{Name: "BUILT", Value: time.Now().Format("20060102T150405")}}, envVars...), envFrom, nil
+}
+
+// processEnvs generates the EnvVars and EnvFromSources of the given envs,
+// without the variables only the function's container is given.
+func processEnvs(envs []fn.Env, referencedSecrets, referencedConfigMaps *sets.Set[string]) ([]corev1.EnvVar, []corev1.EnvFromSource, error) {
+	envVars := []corev1.EnvVar{}
 	envFrom := []corev1.EnvFromSource{}
 
 	for _, env := range envs {
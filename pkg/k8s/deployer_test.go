@@ -46,6 +46,63 @@ func Test_SetHealthEndpointDefaults(t *testing.T) {
 	}
 }
 
+func Test_SetSecurityContextDefaults(t *testing.T) {
+	c := corev1.Container{}
+	SetSecurityContext(&c, nil)
+	sc := c.SecurityContext
+	if sc == nil || !*sc.RunAsNonRoot || *sc.AllowPrivilegeEscalation {
+		t.Fatalf("unexpected default security context %+v", sc)
+	}
+	if len(sc.Capabilities.Drop) != 1 || sc.Capabilities.Drop[0] != "ALL" {
+		t.Errorf("expected all capabilities to be dropped, got %v", sc.Capabilities.Drop)
+	}
+	if sc.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Errorf("expected RuntimeDefault seccomp profile, got %v", sc.SeccompProfile.Type)
+	}
+
+	p := corev1.PodSpec{}
+	SetPodSecurityContext(&p, nil)
+	if p.SecurityContext != nil {
+		t.Errorf("expected no pod security context, got %+v", p.SecurityContext)
+	}
+}
+
+func Test_SetSecurityContext(t *testing.T) {
+	var (
+		uid, fsGroup = int64(2000), int64(3000)
+		readOnly     = true
+	)
+	fsc := &fn.SecurityContext{
+		RunAsUser:              &uid,
+		FSGroup:                &fsGroup,
+		ReadOnlyRootFilesystem: &readOnly,
+		SeccompProfile:         &fn.SeccompProfile{Type: "Localhost", LocalhostProfile: "profiles/func.json"},
+		Capabilities:           &fn.Capabilities{Add: []string{"NET_BIND_SERVICE"}},
+	}
+
+	c := corev1.Container{}
+	SetSecurityContext(&c, fsc)
+	sc := c.SecurityContext
+	if *sc.RunAsUser != uid || !*sc.ReadOnlyRootFilesystem {
+		t.Errorf("function settings not applied: %+v", sc)
+	}
+	if !*sc.RunAsNonRoot || *sc.AllowPrivilegeEscalation {
+		t.Errorf("defaults not kept: %+v", sc)
+	}
+	if sc.SeccompProfile.Type != corev1.SeccompProfileTypeLocalhost || *sc.SeccompProfile.LocalhostProfile != "profiles/func.json" {
+		t.Errorf("unexpected seccomp profile %+v", sc.SeccompProfile)
+	}
+	if len(sc.Capabilities.Add) != 1 || sc.Capabilities.Add[0] != "NET_BIND_SERVICE" || sc.Capabilities.Drop[0] != "ALL" {
+		t.Errorf("unexpected capabilities %+v", sc.Capabilities)
+	}
+
+	p := corev1.PodSpec{}
+	SetPodSecurityContext(&p, fsc)
+	if p.SecurityContext == nil || *p.SecurityContext.FSGroup != fsGroup {
+		t.Errorf("expected fsGroup %d, got %+v", fsGroup, p.SecurityContext)
+	}
+}
+
 func Test_processValue(t *testing.T) {
 	testEnvVarOld, testEnvVarOldExists := os.LookupEnv("TEST_KNATIVE_DEPLOYER")
 	os.Setenv("TEST_KNATIVE_DEPLOYER", "VALUE_FOR_TEST_KNATIVE_DEPLOYER")
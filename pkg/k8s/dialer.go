@@ -336,33 +336,8 @@ func (e emptyBlockingReader) Read(p []byte) (n int, err error) {
 }
 
 func (c *contextDialer) exec(ctx context.Context, hostPort string, in io.Reader, out, errOut io.Writer) error {
-
-	restClient := c.coreV1.RESTClient()
-	req := restClient.Post().
-		Resource("pods").
-		Name(c.podName).
-		Namespace(c.namespace).
-		SubResource("exec")
-	req.VersionedParams(&coreV1.PodExecOptions{
-		Command:   []string{"socat", "-dd", "-", fmt.Sprintf("TCP:%s", hostPort)},
-		Container: c.podName,
-		Stdin:     true,
-		Stdout:    true,
-		Stderr:    true,
-		TTY:       false,
-	}, scheme.ParameterCodec)
-
-	executor, err := remotecommand.NewSPDYExecutor(c.restConf, "POST", req.URL())
-	if err != nil {
-		return err
-	}
-
-	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
-		Stdin:  in,
-		Stdout: out,
-		Stderr: errOut,
-		Tty:    false,
-	})
+	command := []string{"socat", "-dd", "-", fmt.Sprintf("TCP:%s", hostPort)}
+	return execInPod(ctx, c.coreV1.RESTClient(), c.restConf, c.podName, c.namespace, c.podName, command, in, out, errOut)
 }
 
 func attach(ctx context.Context, restClient restclient.Interface, restConf *restclient.Config, podName, namespace string, in io.Reader, out, errOut io.Writer) error {
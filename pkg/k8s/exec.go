@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	coreV1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ExecInPod runs the command in a container of an existing pod, streaming
+// in to its stdin and its stdout and stderr to out and errOut.
+func ExecInPod(ctx context.Context, namespace, podName, containerName string, command []string, in io.Reader, out, errOut io.Writer) error {
+	restConf, err := GetClientConfig().ClientConfig()
+	if err != nil {
+		return fmt.Errorf("cannot get client config: %w", err)
+	}
+	restConf.WarningHandler = restclient.NoWarnings{}
+
+	err = setConfigDefaults(restConf)
+	if err != nil {
+		return fmt.Errorf("cannot set config defaults: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(restConf)
+	if err != nil {
+		return fmt.Errorf("cannot create k8s client: %w", err)
+	}
+
+	return execInPod(ctx, client.CoreV1().RESTClient(), restConf, podName, namespace, containerName, command, in, out, errOut)
+}
+
+func execInPod(ctx context.Context, restClient restclient.Interface, restConf *restclient.Config, podName, namespace, containerName string, command []string, in io.Reader, out, errOut io.Writer) error {
+	req := restClient.Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec")
+	req.VersionedParams(&coreV1.PodExecOptions{
+		Command:   command,
+		Container: containerName,
+		Stdin:     in != nil,
+		Stdout:    out != nil,
+		Stderr:    errOut != nil,
+		TTY:       false,
+	}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(restConf, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  in,
+		Stdout: out,
+		Stderr: errOut,
+		Tty:    false,
+	})
+}
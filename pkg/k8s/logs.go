@@ -144,6 +144,133 @@ func GetPodLogsBySelector(ctx context.Context, namespace, labelSelector, contain
 	return nil
 }
 
+// logsGracePeriod is the time given to log streams to be fully written once
+// following the logs of pods stops.
+const logsGracePeriod = 5 * time.Second
+
+// GetPodsLogsBySelector follows the logs of all containers of the pods
+// matching the selector as soon as they start, writing each line to out
+// prefixed with the result of prefix.
+//
+// This function runs as long as the passed context is active.  Once it is
+// cancelled, logs still being read are given a short grace period to
+// complete.
+func GetPodsLogsBySelector(ctx context.Context, namespace, labelSelector string, prefix func(pod corev1.Pod, container string) string, out io.Writer) error {
+	client, namespace, err := NewClientAndResolvedNamespace(namespace)
+	if err != nil {
+		return fmt.Errorf("cannot create k8s client: %w", err)
+	}
+
+	pods := client.CoreV1().Pods(namespace)
+
+	w, err := pods.Watch(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return fmt.Errorf("cannot create watch: %w", err)
+	}
+	defer w.Stop()
+
+	// streams outlive the watch for the grace period
+	streamCtx, cancelStreams := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelStreams()
+
+	var mu sync.Mutex // serializes lines written to out
+	copyLogs := func(pod corev1.Pod, container string) error {
+		req := pods.GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: container,
+			Follow:    true,
+		})
+		r, e := req.Stream(streamCtx)
+		if e != nil {
+			return fmt.Errorf("cannot get stream: %w", e)
+		}
+		defer r.Close()
+
+		pw := &prefixWriter{mu: &mu, out: out, prefix: prefix(pod, container)}
+		defer pw.Flush()
+		_, e = io.Copy(pw, r)
+		if e != nil && streamCtx.Err() == nil {
+			return fmt.Errorf("error copying logs: %w", e)
+		}
+		return nil
+	}
+
+	started := func(status corev1.ContainerStatus) bool {
+		return status.State.Running != nil || status.State.Terminated != nil
+	}
+
+	followed := make(map[string]bool)
+	var eg errgroup.Group
+
+	for event := range w.ResultChan() {
+		if event.Type != watch.Modified && event.Type != watch.Added {
+			continue
+		}
+		pod, ok := event.Object.(*corev1.Pod)
+		if !ok {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			key := pod.Name + "/" + status.Name
+			if followed[key] || !started(status) {
+				continue
+			}
+			followed[key] = true
+			pod, container := *pod, status.Name
+			eg.Go(func() error { return copyLogs(pod, container) })
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- eg.Wait() }()
+	select {
+	case err = <-done:
+	case <-time.After(logsGracePeriod):
+		cancelStreams()
+		err = <-done
+	}
+	if err != nil {
+		return fmt.Errorf("error while gathering logs: %w", err)
+	}
+	return nil
+}
+
+// prefixWriter writes complete lines to out, each prefixed with prefix.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := w.writeLine(w.buf[:i+1]); err != nil {
+			return len(p), err
+		}
+		w.buf = w.buf[i+1:]
+	}
+}
+
+// Flush writes the last line if it was not terminated.
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		_ = w.writeLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+	return err
+}
+
 type SynchronizedBuffer struct {
 	b  bytes.Buffer
 	mu sync.Mutex
@@ -0,0 +1,27 @@
+package k8s
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var (
+		out bytes.Buffer
+		mu  sync.Mutex
+	)
+	w := &prefixWriter{mu: &mu, out: &out, prefix: "[build] "}
+
+	for _, chunk := range []string{"first line\nsec", "ond line\n", "unterminated"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Flush()
+
+	expected := "[build] first line\n[build] second line\n[build] unterminated\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
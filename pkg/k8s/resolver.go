@@ -0,0 +1,36 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResourceResolver reads the Secrets and ConfigMaps referenced by a function
+// from the cluster, such that a function run locally sees the same values as
+// when deployed. An empty Namespace is the namespace of the active context.
+type ResourceResolver struct {
+	Namespace string
+}
+
+func (r ResourceResolver) Secret(ctx context.Context, name string) (map[string][]byte, error) {
+	s, err := GetSecret(ctx, name, r.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Secret %q: %w", name, err)
+	}
+	return s.Data, nil
+}
+
+func (r ResourceResolver) ConfigMap(ctx context.Context, name string) (map[string][]byte, error) {
+	cm, err := GetConfigMap(ctx, name, r.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ConfigMap %q: %w", name, err)
+	}
+	data := make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+	for k, v := range cm.Data {
+		data[k] = []byte(v)
+	}
+	for k, v := range cm.BinaryData {
+		data[k] = v
+	}
+	return data, nil
+}
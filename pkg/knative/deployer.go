@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -317,6 +318,12 @@ consider setting up the pull secrets and linking it to the default service accou
 			return fn.DeploymentResult{}, err
 		}
 
+		// resources referenced by init containers and sidecars
+		_, _, err = k8s.ProcessContainers(slices.Concat(f.Deploy.InitContainers, f.Deploy.Sidecars), &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
+		if err != nil {
+			return fn.DeploymentResult{}, err
+		}
+
 		err = k8s.CheckResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName)
 		if err != nil {
 			err = fmt.Errorf("knative deployer failed to update the Knative Service: %v", err)
@@ -416,7 +423,7 @@ func generateNewService(f fn.Function, decorator deployer.DeployDecorator, daprI
 		Image: f.Deploy.Image,
 	}
 
-	k8s.SetSecurityContext(&container)
+	k8s.SetSecurityContext(&container, f.Deploy.SecurityContext)
 	k8s.SetHealthEndpoints(f, &container)
 
 	referencedSecrets := sets.New[string]()
@@ -477,6 +484,17 @@ func generateNewService(f fn.Function, decorator deployer.DeployDecorator, daprI
 		},
 	}
 
+	// Pod level settings such as the fsGroup require the Knative Serving
+	// "kubernetes.podspec-securitycontext" feature to be enabled.
+	k8s.SetPodSecurityContext(&service.Spec.Template.Spec.PodSpec, f.Deploy.SecurityContext)
+
+	// Init containers require the Knative Serving
+	// "kubernetes.podspec-init-containers" feature to be enabled.
+	err = k8s.SetAdditionalContainers(&service.Spec.Template.Spec.PodSpec, f, &referencedSecrets, &referencedConfigMaps, &referencedPVC)
+	if err != nil {
+		return service, err
+	}
+
 	err = setServiceOptions(&service.Spec.Template, f.Deploy.Options)
 	if err != nil {
 		return service, err
@@ -535,6 +553,8 @@ func updateService(f fn.Function, previousService *servingv1.Service, newEnv []c
 		// know what this would mean for developers using the func library directly.
 		cp := &service.Spec.Template.Spec.Containers[0]
 		k8s.SetHealthEndpoints(f, cp)
+		k8s.SetSecurityContext(cp, f.Deploy.SecurityContext)
+		k8s.SetPodSecurityContext(&service.Spec.Template.Spec.PodSpec, f.Deploy.SecurityContext)
 
 		err := setServiceOptions(&service.Spec.Template, f.Deploy.Options)
 		if err != nil {
@@ -559,6 +579,14 @@ func updateService(f fn.Function, previousService *servingv1.Service, newEnv []c
 		cp.VolumeMounts = newVolumeMounts
 		service.Spec.Template.Spec.Volumes = newVolumes
 		service.Spec.Template.Spec.ServiceAccountName = f.Deploy.ServiceAccountName
+
+		referencedSecrets := sets.New[string]()
+		referencedConfigMaps := sets.New[string]()
+		referencedPVCs := sets.New[string]()
+		err = k8s.SetAdditionalContainers(&service.Spec.Template.Spec.PodSpec, f, &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
+		if err != nil {
+			return service, err
+		}
 		return service, nil
 	}
 }
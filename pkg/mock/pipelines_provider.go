@@ -15,6 +15,7 @@ type PipelinesProvider struct {
 	ConfigurePACInvoked bool
 	ConfigurePACFn      func(fn.Function) error
 	RemovePACInvoked    bool
+	RemovePACMetadata   any
 	RemovePACFn         func(fn.Function) error
 }
 
@@ -71,5 +72,6 @@ func (p *PipelinesProvider) ConfigurePAC(ctx context.Context, f fn.Function, met
 
 func (p *PipelinesProvider) RemovePAC(ctx context.Context, f fn.Function, metadata any) error {
 	p.RemovePACInvoked = true
+	p.RemovePACMetadata = metadata
 	return p.RemovePACFn(f)
 }
@@ -0,0 +1,37 @@
+package mock
+
+import (
+	"context"
+	"sync"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Tester runs a function's test suite.  By default the suite passes.
+type Tester struct {
+	TestInvoked   bool
+	RootRequested string
+	ArgsRequested []string
+	EnvRequested  []string
+	TestFn        func(context.Context, fn.Function, []string, []string) (fn.TestResult, error)
+	sync.Mutex
+}
+
+func NewTester() *Tester {
+	return &Tester{
+		TestFn: func(_ context.Context, f fn.Function, _, _ []string) (fn.TestResult, error) {
+			return fn.TestResult{Runtime: f.Runtime, Passed: true}, nil
+		},
+	}
+}
+
+func (t *Tester) Test(ctx context.Context, f fn.Function, args, env []string) (fn.TestResult, error) {
+	t.Lock()
+	defer t.Unlock()
+	t.TestInvoked = true
+	t.RootRequested = f.Root
+	t.ArgsRequested = args
+	t.EnvRequested = env
+
+	return t.TestFn(ctx, f, args, env)
+}
@@ -177,6 +177,7 @@ func TestBuilder_Files(t *testing.T) {
 		{Path: "/func/a.lnk", Linkname: link.Target, Type: link.Mode, Executable: link.Executable},
 		{Path: "/func/a.txt"},
 		{Path: "/func/f", Executable: true},
+		{Path: "/func/func.lock"},
 		{Path: "/func/func.yaml"},
 		{Path: "/func/function.go"},
 		{Path: "/func/function_test.go"},
@@ -0,0 +1,116 @@
+package oci
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NerdctlRuntime runs containers on containerd using the nerdctl CLI.
+type NerdctlRuntime struct {
+	bin     string // nerdctl binary
+	verbose bool
+}
+
+// NewNerdctl returns a runtime using the nerdctl binary of the FUNC_NERDCTL
+// environment variable, defaulting to the one on PATH.
+func NewNerdctl(verbose bool) *NerdctlRuntime {
+	// TODO: long-term, the binary should be a setting exposed to main rather
+	// than read from the environment here, as is done for FUNC_GIT.
+	bin := os.Getenv("FUNC_NERDCTL")
+	if bin == "" {
+		bin = "nerdctl"
+	}
+	return &NerdctlRuntime{bin: bin, verbose: verbose}
+}
+
+func (n *NerdctlRuntime) Load(ctx context.Context, r io.Reader) (string, error) {
+	cmd := n.command(ctx, "load")
+	cmd.Stdin = r
+	out, err := n.output(cmd)
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if image, ok := strings.CutPrefix(scanner.Text(), "Loaded image: "); ok {
+			return strings.TrimSpace(image), nil
+		}
+	}
+	return "", fmt.Errorf("nerdctl did not report the loaded image: %s", out)
+}
+
+func (n *NerdctlRuntime) Create(ctx context.Context, cfg ContainerConfig) (string, error) {
+	args := []string{"create"}
+	for _, e := range cfg.Env {
+		args = append(args, "--env", e)
+	}
+	for _, b := range cfg.Ports {
+		args = append(args, "--publish", net.JoinHostPort(b.HostIP, b.HostPort)+":"+b.ContainerPort)
+	}
+	args = append(args, cfg.Image)
+
+	out, err := n.output(n.command(ctx, args...))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (n *NerdctlRuntime) Start(_ context.Context, id string, out, errOut io.Writer) (<-chan error, error) {
+	// Attached to the container until it exits, independent of the context
+	// of the start request.
+	cmd := n.command(context.Background(), "start", "--attach", id)
+	cmd.Stdout = out
+	cmd.Stderr = errOut
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("cannot start nerdctl container: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			err = fmt.Errorf("exited code %v", exitErr.ExitCode())
+		} else if err == nil {
+			err = fmt.Errorf("exited code 0")
+		}
+		exited <- err
+	}()
+	return exited, nil
+}
+
+func (n *NerdctlRuntime) Stop(ctx context.Context, id string, timeout time.Duration) error {
+	_, err := n.output(n.command(ctx, "stop", "--time", strconv.Itoa(int(timeout.Seconds())), id))
+	return err
+}
+
+func (n *NerdctlRuntime) Remove(ctx context.Context, id string) error {
+	_, err := n.output(n.command(ctx, "rm", "--force", id))
+	return err
+}
+
+func (n *NerdctlRuntime) command(ctx context.Context, args ...string) *exec.Cmd {
+	if n.verbose {
+		fmt.Fprintf(os.Stderr, "%v %v\n", n.bin, strings.Join(args, " "))
+	}
+	return exec.CommandContext(ctx, n.bin, args...)
+}
+
+// output of the command, with its stderr included in the returned error.
+func (n *NerdctlRuntime) output(cmd *exec.Cmd) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, fmt.Errorf("%v %v failed: %w: %s", n.bin, cmd.Args[1], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
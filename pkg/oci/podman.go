@@ -0,0 +1,249 @@
+package oci
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// podmanAPI is the version of the libpod API used.
+const podmanAPI = "/v4.0.0/libpod"
+
+// PodmanRuntime runs containers using Podman's libpod REST API, which is
+// served by `podman system service` without any Docker compatibility layer.
+type PodmanRuntime struct {
+	client  *http.Client
+	base    string // URL of the API
+	verbose bool
+}
+
+// NewPodman returns a runtime using the Podman API socket of the
+// CONTAINER_HOST environment variable, as used by the podman remote client,
+// defaulting to the rootless socket of the user and then the rootful one.
+func NewPodman(verbose bool) (*PodmanRuntime, error) {
+	socket, err := podmanSocket()
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	return newPodman(client, "http://podman", verbose), nil
+}
+
+func newPodman(client *http.Client, base string, verbose bool) *PodmanRuntime {
+	return &PodmanRuntime{client: client, base: base + podmanAPI, verbose: verbose}
+}
+
+func podmanSocket() (string, error) {
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		u, err := url.Parse(host)
+		if err != nil {
+			return "", fmt.Errorf("invalid CONTAINER_HOST %q: %w", host, err)
+		}
+		if u.Scheme != "unix" {
+			return "", fmt.Errorf("unsupported CONTAINER_HOST %q, only unix sockets are supported", host)
+		}
+		return u.Path, nil
+	}
+	candidates := []string{"/run/podman/podman.sock"}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		candidates = append([]string{filepath.Join(dir, "podman", "podman.sock")}, candidates...)
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("no Podman API socket found at %v. Start it with 'podman system service' or set CONTAINER_HOST", strings.Join(candidates, " or "))
+}
+
+func (p *PodmanRuntime) Load(ctx context.Context, r io.Reader) (string, error) {
+	res, err := p.do(ctx, http.MethodPost, "/images/load", "application/x-tar", r)
+	if err != nil {
+		return "", fmt.Errorf("cannot load image into podman: %w", err)
+	}
+	defer res.Body.Close()
+	var report struct {
+		Names []string `json:"Names"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&report); err != nil {
+		return "", fmt.Errorf("cannot decode podman load report: %w", err)
+	}
+	if len(report.Names) == 0 {
+		return "", fmt.Errorf("podman did not report the loaded image")
+	}
+	return report.Names[0], nil
+}
+
+func (p *PodmanRuntime) Create(ctx context.Context, cfg ContainerConfig) (string, error) {
+	type portMapping struct {
+		HostIP        string `json:"host_ip,omitempty"`
+		HostPort      uint16 `json:"host_port"`
+		ContainerPort uint16 `json:"container_port"`
+		Protocol      string `json:"protocol"`
+	}
+	spec := struct {
+		Image        string            `json:"image"`
+		Env          map[string]string `json:"env,omitempty"`
+		PortMappings []portMapping     `json:"portmappings,omitempty"`
+	}{
+		Image: cfg.Image,
+		Env:   map[string]string{},
+	}
+	for _, e := range cfg.Env {
+		k, v, _ := strings.Cut(e, "=")
+		spec.Env[k] = v
+	}
+	for _, b := range cfg.Ports {
+		hostPort, err := strconv.ParseUint(b.HostPort, 10, 16)
+		if err != nil {
+			return "", fmt.Errorf("invalid host port %q: %w", b.HostPort, err)
+		}
+		containerPort, err := strconv.ParseUint(b.ContainerPort, 10, 16)
+		if err != nil {
+			return "", fmt.Errorf("invalid container port %q: %w", b.ContainerPort, err)
+		}
+		spec.PortMappings = append(spec.PortMappings, portMapping{
+			HostIP:        b.HostIP,
+			HostPort:      uint16(hostPort),
+			ContainerPort: uint16(containerPort),
+			Protocol:      "tcp",
+		})
+	}
+	bb, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := p.do(ctx, http.MethodPost, "/containers/create", "application/json", strings.NewReader(string(bb)))
+	if err != nil {
+		return "", fmt.Errorf("cannot create podman container: %w", err)
+	}
+	defer res.Body.Close()
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("cannot decode podman container: %w", err)
+	}
+	return created.ID, nil
+}
+
+func (p *PodmanRuntime) Start(ctx context.Context, id string, out, errOut io.Writer) (<-chan error, error) {
+	res, err := p.do(ctx, http.MethodPost, "/containers/"+id+"/start", "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot start podman container: %w", err)
+	}
+	res.Body.Close()
+
+	// Output is streamed until the container exits, independent of the
+	// context of the start request.
+	logs, err := p.do(context.Background(), http.MethodGet, "/containers/"+id+"/logs?follow=true&stdout=true&stderr=true", "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot follow podman container output: %w", err)
+	}
+	go func() {
+		defer logs.Body.Close()
+		_ = demuxStream(logs.Body, out, errOut)
+	}()
+
+	exited := make(chan error, 1)
+	go func() {
+		res, err := p.do(context.Background(), http.MethodPost, "/containers/"+id+"/wait?condition=exited", "", nil)
+		if err != nil {
+			exited <- err
+			return
+		}
+		defer res.Body.Close()
+		var code int
+		if err = json.NewDecoder(res.Body).Decode(&code); err != nil {
+			exited <- fmt.Errorf("cannot decode podman exit code: %w", err)
+			return
+		}
+		exited <- fmt.Errorf("exited code %v", code)
+	}()
+	return exited, nil
+}
+
+func (p *PodmanRuntime) Stop(ctx context.Context, id string, timeout time.Duration) error {
+	res, err := p.do(ctx, http.MethodPost, fmt.Sprintf("/containers/%v/stop?timeout=%d", id, int(timeout.Seconds())), "", nil)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (p *PodmanRuntime) Remove(ctx context.Context, id string) error {
+	res, err := p.do(ctx, http.MethodDelete, "/containers/"+id+"?force=true", "", nil)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// do an API request, returning an error for unsuccessful responses.
+func (p *PodmanRuntime) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.base+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if p.verbose {
+		fmt.Fprintf(os.Stderr, "podman API: %v %v\n", method, path)
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 300 && res.StatusCode != http.StatusNotModified {
+		defer res.Body.Close()
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = res.Status
+		}
+		return nil, fmt.Errorf("%v %v: %v", method, path, apiErr.Message)
+	}
+	return res, nil
+}
+
+// demuxStream copies a multiplexed container output stream, in which each
+// frame has a header of the stream (1 stdout, 2 stderr) and the frame size.
+func demuxStream(r io.Reader, out, errOut io.Writer) error {
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		w := out
+		if header[0] == 2 {
+			w = errOut
+		}
+		if _, err := io.CopyN(w, r, int64(binary.BigEndian.Uint32(header[4:]))); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPodman ensures containers are managed through the libpod API.
+func TestPodman(t *testing.T) {
+	var created map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v4.0.0/libpod/images/load", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"Names":["example.com/alice/f:latest"]}`))
+	})
+	mux.HandleFunc("POST /v4.0.0/libpod/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"Id":"abc"}`))
+	})
+	mux.HandleFunc("POST /v4.0.0/libpod/containers/abc/start", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /v4.0.0/libpod/containers/abc/logs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(append([]byte{1, 0, 0, 0, 0, 0, 0, 3}, "out"...))
+		_, _ = w.Write(append([]byte{2, 0, 0, 0, 0, 0, 0, 3}, "err"...))
+	})
+	mux.HandleFunc("POST /v4.0.0/libpod/containers/abc/wait", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`137`))
+	})
+	mux.HandleFunc("POST /v4.0.0/libpod/containers/missing/stop", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"no such container"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	p := newPodman(server.Client(), server.URL, false)
+
+	image, err := p.Load(ctx, strings.NewReader("archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image != "example.com/alice/f:latest" {
+		t.Fatalf("unexpected image %q", image)
+	}
+
+	id, err := p.Create(ctx, ContainerConfig{
+		Image: image,
+		Env:   []string{"A=B=C"},
+		Ports: []PortBinding{{HostIP: "127.0.0.1", HostPort: "8081", ContainerPort: "8080"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "abc" {
+		t.Fatalf("unexpected container id %q", id)
+	}
+	if env := created["env"].(map[string]any); env["A"] != "B=C" {
+		t.Fatalf("unexpected env %v", env)
+	}
+	if ports := created["portmappings"].([]any); ports[0].(map[string]any)["host_port"] != float64(8081) {
+		t.Fatalf("unexpected port mappings %v", ports)
+	}
+
+	var out, errOut syncBuffer
+	exited, err := p.Start(ctx, id, &out, &errOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = <-exited; err == nil || err.Error() != "exited code 137" {
+		t.Fatalf("unexpected exit %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for (out.String() != "out" || errOut.String() != "err") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if out.String() != "out" || errOut.String() != "err" {
+		t.Fatalf("unexpected output %q and %q", out.String(), errOut.String())
+	}
+
+	if err = p.Stop(ctx, "missing", time.Second); err == nil || !strings.Contains(err.Error(), "no such container") {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+}
+
+// syncBuffer is a buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,180 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+const (
+	// DefaultRunHost on which functions are published.
+	DefaultRunHost = "127.0.0.1"
+
+	// DefaultRunPort is the preferred host port of functions, and the port
+	// on which they listen within their container.
+	DefaultRunPort = "8080"
+
+	// DefaultStopTimeout when stopping containers.
+	DefaultStopTimeout = 10 * time.Second
+)
+
+// Runner starts and stops functions as containers of a ContainerRuntime.
+// Functions built by the host builder are loaded into the runtime from their
+// OCI layout, others are expected to be available to the runtime by image.
+type Runner struct {
+	runtime ContainerRuntime
+	verbose bool
+	out     io.Writer
+	errOut  io.Writer
+}
+
+// NewRunner creates a runner using the given container runtime.
+func NewRunner(runtime ContainerRuntime, verbose bool, out, errOut io.Writer) *Runner {
+	return &Runner{runtime: runtime, verbose: verbose, out: out, errOut: errOut}
+}
+
+// Run the function.
+func (n *Runner) Run(ctx context.Context, f fn.Function, address string, startTimeout time.Duration) (job *fn.Job, err error) {
+	host, port := DefaultRunHost, DefaultRunPort
+	if address != "" {
+		if host, port, err = net.SplitHostPort(address); err != nil {
+			return nil, fmt.Errorf("invalid address format '%s': %w", address, err)
+		}
+	}
+	if port, err = chooseRunPort(host, port, address != ""); err != nil {
+		return
+	}
+
+	image, err := n.load(ctx, f)
+	if err != nil {
+		return
+	}
+
+	envs, err := fn.Interpolate(f.Run.Envs)
+	if err != nil {
+		return
+	}
+	cfg := ContainerConfig{
+		Image: image,
+		Ports: []PortBinding{{HostIP: host, HostPort: port, ContainerPort: DefaultRunPort}},
+	}
+	for k, v := range envs {
+		cfg.Env = append(cfg.Env, k+"="+v)
+	}
+	if n.verbose {
+		cfg.Env = append(cfg.Env, "VERBOSE=true")
+	}
+
+	id, err := n.runtime.Create(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("runner unable to create container: %w", err)
+	}
+	stop := func() error {
+		ctx := context.Background()
+		if err := n.runtime.Stop(ctx, id, DefaultStopTimeout); err != nil {
+			return fmt.Errorf("error stopping container %v: %v", id, err)
+		}
+		if err := n.runtime.Remove(ctx, id); err != nil {
+			return fmt.Errorf("error removing container %v: %v", id, err)
+		}
+		return nil
+	}
+
+	exited, err := n.runtime.Start(ctx, id, n.out, n.errOut)
+	if err != nil {
+		_ = n.runtime.Remove(context.Background(), id)
+		return nil, fmt.Errorf("runner unable to start container: %w", err)
+	}
+	runtimeErrCh := make(chan error, 10)
+	go func() {
+		// An exit, for any reason, is considered an error, as functions are
+		// expected to be long-running processes.
+		runtimeErrCh <- <-exited
+	}()
+
+	if err = waitReady(ctx, host, port, startTimeout, runtimeErrCh); err != nil {
+		_ = stop()
+		return nil, err
+	}
+
+	return fn.NewJob(f, host, port, runtimeErrCh, stop, n.verbose)
+}
+
+// load the function's image into the runtime, returning its reference.  The
+// OCI layout of host builds is loaded, other images are used as is.
+func (n *Runner) load(ctx context.Context, f fn.Function) (string, error) {
+	if f.Build.Image == "" {
+		return "", errors.New("function has no associated image. Has it been built?")
+	}
+	dir := filepath.Join(f.Root, fn.RunDataDir, fn.BuildDir, "oci")
+	if f.Build.Builder != "host" {
+		return f.Build.Image, nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, "index.json")); err != nil {
+		return "", fmt.Errorf("no OCI layout found in %v. Has the function been built? %w", dir, err)
+	}
+	if n.verbose {
+		fmt.Fprintf(n.errOut, "Loading %v into the container runtime as %v\n", dir, f.Build.Image)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(WriteArchive(dir, HostPlatform(), f.Build.Image, pw))
+	}()
+	image, err := n.runtime.Load(ctx, pr)
+	pr.Close()
+	if err != nil {
+		return "", fmt.Errorf("runner unable to load image: %w", err)
+	}
+	return image, nil
+}
+
+// waitReady waits for the function to accept connections on the given
+// address, returning early if it exits.
+func waitReady(ctx context.Context, host, port string, timeout time.Duration, errs <-chan error) error {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, port), 500*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		select {
+		case err := <-errs:
+			return fmt.Errorf("container error before readiness: %w", err)
+		case <-deadline:
+			return fmt.Errorf("container did not become ready in %v", timeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// chooseRunPort returns the preferred port if it is free, or else an
+// OS-chosen one unless the port was requested explicitly.
+func chooseRunPort(host, port string, explicit bool) (string, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err == nil {
+		l.Close()
+		return port, nil
+	}
+	if explicit {
+		return "", &fn.ErrPortUnavailableError{Port: port, Err: err}
+	}
+	if l, err = net.Listen("tcp", net.JoinHostPort(host, "")); err != nil {
+		return "", fmt.Errorf("cannot bind tcp: %w", err)
+	}
+	defer l.Close()
+	_, port, err = net.SplitHostPort(l.Addr().String())
+	return port, err
+}
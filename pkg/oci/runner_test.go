@@ -0,0 +1,101 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestRunner ensures functions are run as containers of the runtime,
+// published on the requested address.
+func TestRunner(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Build.Builder = "pack"
+	f.Build.Image = "example.com/alice/f:latest"
+
+	address := net.JoinHostPort("127.0.0.1", freePort(t))
+	rt := &fakeRuntime{}
+	job, err := NewRunner(rt, false, io.Discard, io.Discard).Run(context.Background(), f, address, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rt.cfg.Image != f.Build.Image {
+		t.Fatalf("expected the built image to be run, got %q", rt.cfg.Image)
+	}
+	if p := rt.cfg.Ports[0]; p.HostPort != job.Port || p.ContainerPort != DefaultRunPort {
+		t.Fatalf("unexpected port binding %+v for job port %v", p, job.Port)
+	}
+	if err = job.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if !rt.stopped || !rt.removed {
+		t.Fatal("expected the container to be stopped and removed")
+	}
+
+	// Functions built by the host builder are loaded from their OCI layout,
+	// which must exist.
+	f.Build.Builder = "host"
+	if _, err = NewRunner(rt, false, io.Discard, io.Discard).Run(context.Background(), f, address, time.Second); err == nil {
+		t.Fatal("expected an error running a host build without an OCI layout")
+	}
+}
+
+func freePort(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+// fakeRuntime serves the function on its published port once started.
+type fakeRuntime struct {
+	cfg              ContainerConfig
+	listener         net.Listener
+	stopped, removed bool
+}
+
+func (r *fakeRuntime) Load(context.Context, io.Reader) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (r *fakeRuntime) Create(_ context.Context, cfg ContainerConfig) (string, error) {
+	r.cfg = cfg
+	return "abc", nil
+}
+
+func (r *fakeRuntime) Start(_ context.Context, _ string, _, _ io.Writer) (<-chan error, error) {
+	p := r.cfg.Ports[0]
+	l, err := net.Listen("tcp", net.JoinHostPort(p.HostIP, p.HostPort))
+	if err != nil {
+		return nil, err
+	}
+	r.listener = l
+	go func() { _ = http.Serve(l, http.NotFoundHandler()) }()
+	return make(chan error), nil
+}
+
+func (r *fakeRuntime) Stop(context.Context, string, time.Duration) error {
+	r.stopped = true
+	return r.listener.Close()
+}
+
+func (r *fakeRuntime) Remove(context.Context, string) error {
+	r.removed = true
+	return nil
+}
@@ -0,0 +1,183 @@
+package oci
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Container runtimes which can be selected in lieu of the Docker daemon.
+const (
+	Podman  = "podman"
+	Nerdctl = "nerdctl"
+)
+
+// ContainerRuntimes which are supported by NewContainerRuntime.
+var ContainerRuntimes = []string{Podman, Nerdctl}
+
+// ContainerRuntime is a container engine able to load the OCI images written
+// by the host builder and to run them as containers, such as Podman or
+// containerd, without requiring a Docker daemon.
+type ContainerRuntime interface {
+	// Load the OCI archive read from r into the runtime's image store,
+	// returning the reference of the loaded image.
+	Load(ctx context.Context, r io.Reader) (image string, err error)
+
+	// Create a container, returning its ID.
+	Create(ctx context.Context, cfg ContainerConfig) (id string, err error)
+
+	// Start the container, copying its output to out and errOut.  The
+	// returned channel receives an error once the container has exited.
+	Start(ctx context.Context, id string, out, errOut io.Writer) (<-chan error, error)
+
+	// Stop the container, killing it after the given timeout.
+	Stop(ctx context.Context, id string, timeout time.Duration) error
+
+	// Remove the container.
+	Remove(ctx context.Context, id string) error
+}
+
+// ContainerConfig of a container to create.
+type ContainerConfig struct {
+	Image string
+	Env   []string // In the form NAME=VALUE
+	Ports []PortBinding
+}
+
+// PortBinding publishes a container port on a host interface.
+type PortBinding struct {
+	HostIP        string
+	HostPort      string
+	ContainerPort string
+}
+
+// NewContainerRuntime returns the container runtime of the given name.
+func NewContainerRuntime(name string, verbose bool) (ContainerRuntime, error) {
+	switch name {
+	case Podman:
+		return NewPodman(verbose)
+	case Nerdctl:
+		return NewNerdctl(verbose), nil
+	default:
+		return nil, fmt.Errorf("unsupported container runtime %q. Supported runtimes are %v", name, ContainerRuntimes)
+	}
+}
+
+// HostPlatform is the platform of the images run by the local container
+// runtime.
+func HostPlatform() v1.Platform {
+	return v1.Platform{OS: "linux", Architecture: runtime.GOARCH}
+}
+
+// WriteArchive writes the image of the given platform from the OCI layout
+// directory to w as an OCI archive.  The image is annotated with the given
+// reference, which both Podman and containerd use to name the image when
+// loading the archive.
+func WriteArchive(dir string, p v1.Platform, ref string, w io.Writer) error {
+	path, err := layout.FromPath(dir)
+	if err != nil {
+		return fmt.Errorf("cannot read OCI layout %v: %w", dir, err)
+	}
+	index, err := path.ImageIndex()
+	if err != nil {
+		return err
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return err
+	}
+
+	var desc *v1.Descriptor
+	for i, m := range manifest.Manifests {
+		if m.Platform != nil && m.Platform.Satisfies(p) {
+			desc = &manifest.Manifests[i]
+			break
+		}
+	}
+	if desc == nil {
+		return fmt.Errorf("the OCI layout %v has no image for platform %v", dir, p)
+	}
+	img, err := index.Image(desc.Digest)
+	if err != nil {
+		return err
+	}
+	imgManifest, err := img.Manifest()
+	if err != nil {
+		return err
+	}
+
+	// The archive holds a single image, whose blobs are its manifest, config
+	// and layers.
+	blobs := []v1.Hash{desc.Digest, imgManifest.Config.Digest}
+	for _, l := range imgManifest.Layers {
+		blobs = append(blobs, l.Digest)
+	}
+
+	named := *desc
+	named.Annotations = map[string]string{
+		"org.opencontainers.image.ref.name": ref,
+		"io.containerd.image.name":          ref,
+	}
+	indexJSON, err := json.Marshal(v1.IndexManifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIImageIndex,
+		Manifests:     []v1.Descriptor{named},
+	})
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	if err = writeTarFile(tw, "oci-layout", []byte(`{ "imageLayoutVersion": "1.0.0" }`)); err != nil {
+		return err
+	}
+	if err = writeTarFile(tw, "index.json", indexJSON); err != nil {
+		return err
+	}
+	written := map[v1.Hash]bool{}
+	for _, h := range blobs {
+		if written[h] {
+			continue
+		}
+		written[h] = true
+		if err = writeTarBlob(tw, filepath.Join(dir, "blobs", h.Algorithm, h.Hex), "blobs/"+h.Algorithm+"/"+h.Hex); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+func writeTarBlob(tw *tar.Writer, source, name string) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	fi, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: fi.Size(), Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, file)
+	return err
+}
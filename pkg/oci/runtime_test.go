@@ -0,0 +1,88 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+// TestWriteArchive ensures the image of the requested platform is written as
+// a single, named image of an OCI archive including all of its blobs.
+func TestWriteArchive(t *testing.T) {
+	dir := t.TempDir()
+	path, err := layout.Write(dir, empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amd64, arm64 := v1.Platform{OS: "linux", Architecture: "amd64"}, v1.Platform{OS: "linux", Architecture: "arm64"}
+	images := map[string]v1.Image{}
+	for _, p := range []v1.Platform{amd64, arm64} {
+		img, err := random.Image(64, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = path.AppendImage(img, layout.WithPlatform(p)); err != nil {
+			t.Fatal(err)
+		}
+		images[p.Architecture] = img
+	}
+
+	var buf bytes.Buffer
+	if err = WriteArchive(dir, arm64, "example.com/alice/f:latest", &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{}
+	tr := tar.NewReader(&buf)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		bb, _ := io.ReadAll(tr)
+		files[h.Name] = bb
+	}
+
+	var index v1.IndexManifest
+	if err = json.Unmarshal(files["index.json"], &index); err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Manifests) != 1 {
+		t.Fatalf("expected a single image, got %d", len(index.Manifests))
+	}
+	want, _ := images["arm64"].Digest()
+	if index.Manifests[0].Digest != want {
+		t.Fatalf("expected the arm64 image %v, got %v", want, index.Manifests[0].Digest)
+	}
+	if name := index.Manifests[0].Annotations["org.opencontainers.image.ref.name"]; name != "example.com/alice/f:latest" {
+		t.Fatalf("unexpected image name annotation %q", name)
+	}
+
+	manifest, _ := images["arm64"].Manifest()
+	blobs := []v1.Hash{want, manifest.Config.Digest}
+	for _, l := range manifest.Layers {
+		blobs = append(blobs, l.Digest)
+	}
+	for _, h := range blobs {
+		if _, ok := files["blobs/sha256/"+h.Hex]; !ok {
+			t.Errorf("blob %v missing from archive", h)
+		}
+	}
+	if len(files) != len(blobs)+2 {
+		t.Errorf("expected %d files in archive, got %d", len(blobs)+2, len(files))
+	}
+
+	if err = WriteArchive(dir, v1.Platform{OS: "linux", Architecture: "s390x"}, "example.com/alice/f:latest", io.Discard); err == nil {
+		t.Fatal("expected an error for a platform missing from the layout")
+	}
+}
@@ -16,4 +16,8 @@ type PacMetadata struct {
 	ConfigureLocalResources   bool
 	ConfigureClusterResources bool
 	ConfigureRemoteResources  bool
+
+	// RotateWebhookSecret replaces the webhook secret on the git repository
+	// and on the cluster instead of removing the webhook.
+	RotateWebhookSecret bool
 }
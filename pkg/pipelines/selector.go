@@ -0,0 +1,58 @@
+package pipelines
+
+import (
+	"context"
+	"fmt"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Selector is a pipelines provider which delegates to the provider of the
+// remote builder selected by the function (build.remoteBuilder), keyed by
+// the remote builder's name.  Functions which do not select a remote
+// builder use Tekton.
+type Selector map[string]fn.PipelinesProvider
+
+func (s Selector) provider(f fn.Function) (fn.PipelinesProvider, error) {
+	name := f.Build.RemoteBuilder
+	if name == "" {
+		name = fn.RemoteBuilderTekton
+	}
+	p, ok := s[name]
+	if !ok {
+		return nil, fmt.Errorf("remote builder %q is not supported", name)
+	}
+	return p, nil
+}
+
+func (s Selector) Run(ctx context.Context, f fn.Function) (string, fn.Function, error) {
+	p, err := s.provider(f)
+	if err != nil {
+		return "", f, err
+	}
+	return p.Run(ctx, f)
+}
+
+func (s Selector) Remove(ctx context.Context, f fn.Function) error {
+	p, err := s.provider(f)
+	if err != nil {
+		return err
+	}
+	return p.Remove(ctx, f)
+}
+
+func (s Selector) ConfigurePAC(ctx context.Context, f fn.Function, metadata any) error {
+	p, err := s.provider(f)
+	if err != nil {
+		return err
+	}
+	return p.ConfigurePAC(ctx, f, metadata)
+}
+
+func (s Selector) RemovePAC(ctx context.Context, f fn.Function, metadata any) error {
+	p, err := s.provider(f)
+	if err != nil {
+		return err
+	}
+	return p.RemovePAC(ctx, f, metadata)
+}
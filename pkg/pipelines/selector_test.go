@@ -0,0 +1,39 @@
+package pipelines_test
+
+import (
+	"context"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	"knative.dev/func/pkg/pipelines"
+)
+
+func TestSelector(t *testing.T) {
+	tekton, shipwright := mock.NewPipelinesProvider(), mock.NewPipelinesProvider()
+	s := pipelines.Selector{
+		fn.RemoteBuilderTekton:     tekton,
+		fn.RemoteBuilderShipwright: shipwright,
+	}
+
+	f := fn.Function{Name: "myfunc", Namespace: "ns", Registry: "example.com/alice"}
+	if _, _, err := s.Run(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if !tekton.RunInvoked || shipwright.RunInvoked {
+		t.Error("expected tekton to be used by default")
+	}
+
+	f.Build.RemoteBuilder = fn.RemoteBuilderShipwright
+	if err := s.Remove(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if !shipwright.RemoveInvoked || tekton.RemoveInvoked {
+		t.Error("expected shipwright to be used when selected")
+	}
+
+	f.Build.RemoteBuilder = "kaniko"
+	if _, _, err := s.Run(context.Background(), f); err == nil {
+		t.Error("expected an error for an unknown remote builder")
+	}
+}
@@ -0,0 +1,397 @@
+package shipwright
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+	"knative.dev/func/pkg/oci"
+)
+
+// ErrPACNotSupported is returned when Pipelines as Code is configured for a
+// function built by Shipwright.
+var ErrPACNotSupported = errors.New("pipelines as code is not supported by the shipwright remote builder")
+
+type PipelineDecorator interface {
+	UpdateLabels(fn.Function, map[string]string) map[string]string
+}
+
+type Opt func(*PipelinesProvider)
+
+// PipelinesProvider builds functions on the cluster with Shipwright Build
+// and deploys the resulting image with a Deployer.
+type PipelinesProvider struct {
+	verbose             bool
+	quiet               bool
+	credentialsProvider oci.CredentialsProvider
+	decorator           PipelineDecorator
+	deployer            fn.Deployer
+
+	// allow replacing cluster access in unit tests
+	newDynamicClient func() (dynamic.Interface, error)
+	ensurePushSecret func(ctx context.Context, name, namespace string, labels, annotations map[string]string, username, password, server string) error
+	uploadSources    func(ctx context.Context, f fn.Function, namespace, buildRun string) error
+	streamLogs       func(ctx context.Context, namespace, labelSelector string, prefix func(pod corev1.Pod, container string) string, out io.Writer) error
+}
+
+func WithCredentialsProvider(credentialsProvider oci.CredentialsProvider) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.credentialsProvider = credentialsProvider
+	}
+}
+
+func WithVerbose(verbose bool) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.verbose = verbose
+	}
+}
+
+// WithQuiet prints only the progress of the BuildRun instead of streaming
+// the logs of its steps.
+func WithQuiet(quiet bool) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.quiet = quiet
+	}
+}
+
+func WithPipelineDecorator(decorator PipelineDecorator) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.decorator = decorator
+	}
+}
+
+// WithDeployer sets the deployer of the built image.  Shipwright only
+// builds, the function is deployed from the client.
+func WithDeployer(deployer fn.Deployer) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.deployer = deployer
+	}
+}
+
+func NewPipelinesProvider(opts ...Opt) *PipelinesProvider {
+	pp := &PipelinesProvider{
+		newDynamicClient: k8s.NewDynamicClient,
+		ensurePushSecret: k8s.EnsureDockerRegistrySecretExist,
+		uploadSources:    uploadSources,
+		streamLogs:       k8s.GetPodsLogsBySelector,
+	}
+
+	for _, opt := range opts {
+		opt(pp)
+	}
+
+	return pp
+}
+
+// Run a remote build by creating a Shipwright Build of the function and
+// a BuildRun of it, uploading the function's sources unless it is built from
+// a git repository.  Once the image is built it is deployed.
+// Progress is by default piped to stderr.
+// Returned is the final url, and the input Function with the final results of the run populated
+// (f.Deploy.Image and f.Deploy.Namespace) or an error.
+func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn.Function, error) {
+	var err error
+
+	if pp.deployer == nil {
+		return "", f, errors.New("shipwright remote builder requires a deployer")
+	}
+
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	if namespace == "" {
+		return "", f, fn.ErrNamespaceRequired
+	}
+	f.Deploy.Namespace = namespace
+
+	image := f.Image
+	if image == "" {
+		image, err = f.ImageName()
+		if err != nil {
+			return "", f, err
+		}
+	}
+	f.Deploy.Image = image
+
+	labels, err := f.LabelsMap()
+	if err != nil {
+		return "", f, err
+	}
+	if pp.decorator != nil {
+		labels = pp.decorator.UpdateLabels(f, labels)
+	}
+
+	build, err := generateBuild(f, namespace, image, labels)
+	if err != nil {
+		return "", f, err
+	}
+
+	client, err := pp.newDynamicClient()
+	if err != nil {
+		return "", f, err
+	}
+
+	registry, err := docker.GetRegistry(image)
+	if err != nil {
+		return "", f, fmt.Errorf("problem in resolving image registry name: %v", err)
+	}
+
+	creds, err := pp.credentialsProvider(ctx, image)
+	if err != nil {
+		return "", f, err
+	}
+
+	if registry == name.DefaultRegistry {
+		registry = authn.DefaultAuthKey
+	}
+	if f.Registry == "" {
+		f.Registry = registry
+	}
+
+	err = pp.ensurePushSecret(ctx, getPushSecretName(f), namespace, labels, f.Deploy.Annotations, creds.Username, creds.Password, registry)
+	if err != nil {
+		return "", f, fmt.Errorf("problem in creating secret: %v", err)
+	}
+
+	if err = applyBuild(ctx, client, build); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", f, fmt.Errorf("problem creating build, missing shipwright?: %v", err)
+		}
+		return "", f, fmt.Errorf("problem creating build: %v", err)
+	}
+
+	run, err := client.Resource(buildRunGVR).Namespace(namespace).Create(ctx, generateBuildRun(f, namespace, labels), metav1.CreateOptions{})
+	if err != nil {
+		return "", f, fmt.Errorf("problem in creating build run: %v", err)
+	}
+
+	status, err := pp.watchBuildRunProgress(ctx, client, f, run)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			return "", f, fmt.Errorf("problem in watching started build run: %v", err)
+		}
+		cancelBuildRun(client, namespace, run.GetName())
+		return "", f, fmt.Errorf("build run cancelled: %w", context.Canceled)
+	}
+
+	if !status.succeeded {
+		// unless quiet, the logs of the failed step were already streamed
+		message := status.message
+		if pp.quiet {
+			message = getFailedBuildRunLog(ctx, namespace, status)
+		}
+		return "", f, fmt.Errorf("function build run has failed with message: \n\n%s", message)
+	}
+
+	if status.digest != "" {
+		if f.Deploy.Image, err = imageWithDigest(image, status.digest); err != nil {
+			return "", f, err
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Deploying function to the cluster")
+	result, err := pp.deployer.Deploy(ctx, f)
+	if err != nil {
+		return "", f, err
+	}
+
+	if result.Status == fn.Deployed {
+		fmt.Fprintf(os.Stderr, "✅ Function deployed in namespace %q and exposed at URL: \n   %s\n", result.Namespace, result.URL)
+	} else {
+		fmt.Fprintf(os.Stderr, "✅ Function updated in namespace %q and exposed at URL: \n   %s\n", result.Namespace, result.URL)
+	}
+
+	return result.URL, f, nil
+}
+
+// Remove the Build, BuildRuns and the push secret of the function.
+func (pp *PipelinesProvider) Remove(ctx context.Context, f fn.Function) error {
+	if f.Deploy.Namespace == "" {
+		return fn.ErrNamespaceRequired
+	}
+	namespace := f.Deploy.Namespace
+
+	client, err := pp.newDynamicClient()
+	if err != nil {
+		return err
+	}
+
+	listOptions := metav1.ListOptions{
+		LabelSelector: k8slabels.SelectorFromSet(k8slabels.Set{fnlabels.FunctionNameKey: f.Name}).String(),
+	}
+
+	errMsg := ""
+	for _, gvr := range []schema.GroupVersionResource{buildRunGVR, buildGVR} {
+		err = client.Resource(gvr).Namespace(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, listOptions)
+		if err != nil && !k8serrors.IsNotFound(err) && !k8serrors.IsForbidden(err) {
+			errMsg += fmt.Sprintf("\n %v", err)
+		}
+	}
+	err = k8s.DeleteSecrets(ctx, namespace, listOptions)
+	if err != nil && !k8serrors.IsNotFound(err) && !k8serrors.IsForbidden(err) {
+		errMsg += fmt.Sprintf("\n %v", err)
+	}
+
+	if errMsg != "" {
+		return errors.New("error deleting resources:" + errMsg)
+	}
+	return nil
+}
+
+func (pp *PipelinesProvider) ConfigurePAC(ctx context.Context, f fn.Function, metadata any) error {
+	return ErrPACNotSupported
+}
+
+func (pp *PipelinesProvider) RemovePAC(ctx context.Context, f fn.Function, metadata any) error {
+	return ErrPACNotSupported
+}
+
+// applyBuild creates the Build, or updates the spec of an existing one.
+func applyBuild(ctx context.Context, client dynamic.Interface, build *unstructured.Unstructured) error {
+	builds := client.Resource(buildGVR).Namespace(build.GetNamespace())
+	_, err := builds.Create(ctx, build, metav1.CreateOptions{})
+	if !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	current, err := builds.Get(ctx, build.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	current.Object["spec"] = build.Object["spec"]
+	current.SetLabels(build.GetLabels())
+	current.SetAnnotations(build.GetAnnotations())
+	_, err = builds.Update(ctx, current, metav1.UpdateOptions{})
+	return err
+}
+
+// buildRunProgressMsg describes the BuildRun's reasons of its Succeeded
+// condition while running.
+var buildRunProgressMsg = map[string]string{
+	"Pending": "Waiting for the build to start",
+	"Running": "Building function image on the cluster",
+}
+
+// watchBuildRunProgress watches the progress of the BuildRun, printing a
+// description of its state whenever it changes, and uploads the function's
+// sources if the Build expects them.  The final status of the BuildRun is
+// returned.
+func (pp *PipelinesProvider) watchBuildRunProgress(ctx context.Context, client dynamic.Interface, f fn.Function, run *unstructured.Unstructured) (buildRunStatus, error) {
+	namespace := run.GetNamespace()
+	watcher, err := client.Resource(buildRunGVR).Namespace(namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", run.GetName()).String(),
+	})
+	if err != nil {
+		return buildRunStatus{}, err
+	}
+	defer watcher.Stop()
+
+	if !pp.quiet {
+		logsCtx, stopLogs := context.WithCancel(ctx)
+		logsDone := make(chan struct{})
+		go func() {
+			defer close(logsDone)
+			selector := k8slabels.SelectorFromSet(k8slabels.Set{buildRunNameLabel: run.GetName()}).String()
+			err := pp.streamLogs(logsCtx, namespace, selector, stepLogPrefix, os.Stderr)
+			if err != nil && pp.verbose {
+				fmt.Fprintf(os.Stderr, "cannot stream the build run logs: %v\n", err)
+			}
+		}()
+		defer func() {
+			stopLogs()
+			<-logsDone
+		}()
+	}
+
+	uploadErr := make(chan error, 1)
+	if f.Build.Git.URL == "" {
+		go func() {
+			fmt.Fprintf(os.Stderr, "Running Shipwright BuildRun: %s\n", "Uploading function sources")
+			uploadErr <- pp.uploadSources(ctx, f, namespace, run.GetName())
+		}()
+	}
+
+	var reason string
+	for {
+		select {
+		case <-ctx.Done():
+			return buildRunStatus{}, ctx.Err()
+		case err := <-uploadErr:
+			if err != nil {
+				return buildRunStatus{}, fmt.Errorf("cannot upload sources: %w", err)
+			}
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return buildRunStatus{}, errors.New("build run watch closed unexpectedly")
+			}
+			u, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			status := getBuildRunStatus(u)
+			if status.done {
+				return status, nil
+			}
+			if status.reason != "" && status.reason != reason {
+				reason = status.reason
+				description := reason
+				if val, ok := buildRunProgressMsg[reason]; ok {
+					description = val
+				}
+				fmt.Fprintf(os.Stderr, "Running Shipwright BuildRun: %s\n", description)
+			}
+		}
+	}
+}
+
+// stepLogPrefix prefixes the log lines of a BuildRun's step with its name.
+func stepLogPrefix(_ corev1.Pod, container string) string {
+	return fmt.Sprintf("[%s] ", strings.TrimPrefix(container, "step-"))
+}
+
+// cancelBuildRun requests the cancellation of the BuildRun.  It uses its own
+// context as the one of the run is already done.
+func cancelBuildRun(client dynamic.Interface, namespace, name string) {
+	patch := []byte(`{"spec":{"state":"BuildRunCanceled"}}`)
+	_, _ = client.Resource(buildRunGVR).Namespace(namespace).Patch(context.Background(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// getFailedBuildRunLog returns the log of the failed step's container if
+// the BuildRun reports it, falling back to the BuildRun's failure message.
+func getFailedBuildRunLog(ctx context.Context, namespace string, status buildRunStatus) string {
+	if status.pod != "" && status.container != "" {
+		podLogs, err := k8s.GetPodLogs(ctx, namespace, status.pod, status.container)
+		if err == nil {
+			return podLogs
+		}
+	}
+	return status.message
+}
+
+func imageWithDigest(image, digest string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse image %q: %w", image, err)
+	}
+	return ref.Context().Name() + "@" + digest, nil
+}
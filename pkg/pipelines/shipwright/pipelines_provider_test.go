@@ -0,0 +1,167 @@
+package shipwright
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	"knative.dev/func/pkg/oci"
+)
+
+const testDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+// newTestProvider returns a provider using a fake cluster, on which the
+// BuildRun finishes with the given condition once the sources are uploaded.
+func newTestProvider(t *testing.T, deployer fn.Deployer, condition map[string]any) (*PipelinesProvider, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		buildGVR:    "BuildList",
+		buildRunGVR: "BuildRunList",
+	})
+	// the fake client does not generate names
+	var runs int
+	client.PrependReactor("create", "buildruns", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		if obj.GetName() == "" {
+			runs++
+			obj.SetName(fmt.Sprintf("%s%d", obj.GetGenerateName(), runs))
+		}
+		return false, nil, nil
+	})
+
+	pp := NewPipelinesProvider(
+		WithCredentialsProvider(func(context.Context, string) (oci.Credentials, error) {
+			return oci.Credentials{Username: "alice", Password: "secret"}, nil
+		}),
+		WithDeployer(deployer))
+	pp.newDynamicClient = func() (dynamic.Interface, error) { return client, nil }
+	pp.streamLogs = func(ctx context.Context, _, _ string, _ func(corev1.Pod, string) string, _ io.Writer) error {
+		<-ctx.Done()
+		return nil
+	}
+	pp.ensurePushSecret = func(context.Context, string, string, map[string]string, map[string]string, string, string, string) error {
+		return nil
+	}
+	pp.uploadSources = func(ctx context.Context, f fn.Function, namespace, buildRun string) error {
+		buildRuns := client.Resource(buildRunGVR).Namespace(namespace)
+		run, err := buildRuns.Get(ctx, buildRun, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		run.Object["status"] = map[string]any{
+			"conditions": []any{condition},
+			"output":     map[string]any{"digest": testDigest},
+		}
+		_, err = buildRuns.Update(ctx, run, metav1.UpdateOptions{})
+		return err
+	}
+	return pp, client
+}
+
+func TestRun(t *testing.T) {
+	deployer := mock.NewDeployerWithResult(fn.DeploymentResult{Status: fn.Deployed, URL: "http://myfunc.ns", Namespace: "ns"})
+	pp, client := newTestProvider(t, deployer, map[string]any{"type": "Succeeded", "status": "True", "reason": "Succeeded"})
+
+	f := fn.Function{
+		Name:      "myfunc",
+		Runtime:   "go",
+		Namespace: "ns",
+		Registry:  "example.com/alice",
+		Build:     fn.BuildSpec{Builder: builders.Pack, RemoteBuilder: fn.RemoteBuilderShipwright},
+	}
+	url, f, err := pp.Run(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "http://myfunc.ns" {
+		t.Errorf("unexpected url %q", url)
+	}
+	if !deployer.DeployInvoked {
+		t.Error("function was not deployed")
+	}
+	if want := "example.com/alice/myfunc@" + testDigest; f.Deploy.Image != want {
+		t.Errorf("expected image %q, got %q", want, f.Deploy.Image)
+	}
+
+	build, err := client.Resource(buildGVR).Namespace("ns").Get(context.Background(), "func-myfunc-build", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source, _, _ := unstructured.NestedString(build.Object, "spec", "source", "type"); source != "Local" {
+		t.Errorf("expected local source, got %q", source)
+	}
+
+	// running again updates the existing Build
+	if _, _, err = pp.Run(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_Failed(t *testing.T) {
+	deployer := mock.NewDeployer()
+	pp, _ := newTestProvider(t, deployer, map[string]any{"type": "Succeeded", "status": "False", "reason": "Failed", "message": "no space left"})
+
+	f := fn.Function{
+		Name:      "myfunc",
+		Runtime:   "go",
+		Namespace: "ns",
+		Registry:  "example.com/alice",
+		Build:     fn.BuildSpec{Builder: builders.Pack},
+	}
+	_, _, err := pp.Run(context.Background(), f)
+	if err == nil || !strings.Contains(err.Error(), "no space left") {
+		t.Fatalf("expected the build run failure, got %v", err)
+	}
+	if deployer.DeployInvoked {
+		t.Error("function deployed despite the failed build")
+	}
+}
+
+func TestConfigurePAC_NotSupported(t *testing.T) {
+	pp := NewPipelinesProvider()
+	if err := pp.ConfigurePAC(context.Background(), fn.Function{}, nil); err != ErrPACNotSupported {
+		t.Errorf("expected ErrPACNotSupported, got %v", err)
+	}
+}
+
+func TestRun_StreamsLogs(t *testing.T) {
+	for _, quiet := range []bool{false, true} {
+		t.Run(fmt.Sprintf("quiet=%v", quiet), func(t *testing.T) {
+			deployer := mock.NewDeployer()
+			pp, _ := newTestProvider(t, deployer, map[string]any{"type": "Succeeded", "status": "True", "reason": "Succeeded"})
+			pp.quiet = quiet
+
+			var selector string
+			pp.streamLogs = func(ctx context.Context, _, labelSelector string, _ func(corev1.Pod, string) string, _ io.Writer) error {
+				selector = labelSelector
+				<-ctx.Done()
+				return nil
+			}
+
+			f := fn.Function{Name: "myfunc", Runtime: "go", Namespace: "ns", Registry: "example.com/alice", Build: fn.BuildSpec{Builder: builders.Pack}}
+			if _, _, err := pp.Run(context.Background(), f); err != nil {
+				t.Fatal(err)
+			}
+			if quiet && selector != "" {
+				t.Errorf("expected no logs to be streamed when quiet")
+			}
+			if !quiet && selector != buildRunNameLabel+"=func-myfunc-buildrun-1" {
+				t.Errorf("unexpected log selector %q", selector)
+			}
+		})
+	}
+}
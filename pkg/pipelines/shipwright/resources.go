@@ -0,0 +1,184 @@
+package shipwright
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/s2i"
+)
+
+const (
+	apiVersion = "shipwright.io/v1beta1"
+
+	// Cluster build strategies installed with Shipwright's samples, used for
+	// the function's builder.
+	packStrategy = "buildpacks-v3"
+	s2iStrategy  = "source-to-image"
+
+	// localSourceName names the uploaded source of a Build without a git
+	// repository.
+	localSourceName    = "func-upload"
+	localSourceTimeout = "10m"
+)
+
+var (
+	buildGVR    = schema.GroupVersionResource{Group: "shipwright.io", Version: "v1beta1", Resource: "builds"}
+	buildRunGVR = schema.GroupVersionResource{Group: "shipwright.io", Version: "v1beta1", Resource: "buildruns"}
+)
+
+func getBuildName(f fn.Function) string {
+	return fmt.Sprintf("func-%s-build", f.Name)
+}
+
+func getBuildRunGenerateName(f fn.Function) string {
+	return fmt.Sprintf("func-%s-buildrun-", f.Name)
+}
+
+func getPushSecretName(f fn.Function) string {
+	return fmt.Sprintf("func-%s-push-secret", f.Name)
+}
+
+// getStrategy returns the cluster build strategy for the function's builder
+// and the parameters it expects.
+func getStrategy(f fn.Function) (string, []any, error) {
+	switch f.Build.Builder {
+	case builders.Pack:
+		return packStrategy, nil, nil
+	case builders.S2I:
+		image, err := s2i.BuilderImage(f, builders.S2I)
+		if err != nil {
+			return "", nil, err
+		}
+		return s2iStrategy, []any{
+			map[string]any{"name": "builder-image", "value": image},
+		}, nil
+	case builders.Host:
+		return "", nil, fmt.Errorf("the %q builder is not supported for remote deployments. Use %q or %q instead", builders.Host, builders.Pack, builders.S2I)
+	default:
+		return "", nil, builders.ErrUnknownBuilder{Name: f.Build.Builder, Known: builders.Known{builders.Pack, builders.S2I}}
+	}
+}
+
+// generateBuild returns the Shipwright Build which builds the function's
+// image from its git repository, or from uploaded sources if the function
+// has no git repository.
+func generateBuild(f fn.Function, namespace, image string, labels map[string]string) (*unstructured.Unstructured, error) {
+	strategy, params, err := getStrategy(f)
+	if err != nil {
+		return nil, err
+	}
+
+	source := map[string]any{}
+	if f.Build.Git.URL == "" {
+		source["type"] = "Local"
+		source["local"] = map[string]any{
+			"name":    localSourceName,
+			"timeout": localSourceTimeout,
+		}
+		// uploaded sources are extracted to ./source
+	} else {
+		git := map[string]any{"url": f.Build.Git.URL}
+		if f.Build.Git.Revision != "" {
+			git["revision"] = f.Build.Git.Revision
+		}
+		source["type"] = "Git"
+		source["git"] = git
+		if f.Build.Git.ContextDir != "" {
+			source["contextDir"] = f.Build.Git.ContextDir
+		}
+	}
+
+	env := []any{}
+	for _, e := range f.Build.BuildEnvs {
+		if e.Name == nil || e.Value == nil {
+			continue
+		}
+		env = append(env, map[string]any{"name": *e.Name, "value": *e.Value})
+	}
+
+	spec := map[string]any{
+		"source": source,
+		"strategy": map[string]any{
+			"name": strategy,
+			"kind": "ClusterBuildStrategy",
+		},
+		"output": map[string]any{
+			"image":      image,
+			"pushSecret": getPushSecretName(f),
+		},
+	}
+	if len(params) > 0 {
+		spec["paramValues"] = params
+	}
+	if len(env) > 0 {
+		spec["env"] = env
+	}
+
+	build := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       "Build",
+		"spec":       spec,
+	}}
+	build.SetName(getBuildName(f))
+	build.SetNamespace(namespace)
+	build.SetLabels(labels)
+	build.SetAnnotations(f.Deploy.Annotations)
+	return build, nil
+}
+
+// generateBuildRun returns a BuildRun of the function's Build.
+func generateBuildRun(f fn.Function, namespace string, labels map[string]string) *unstructured.Unstructured {
+	run := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       "BuildRun",
+		"spec": map[string]any{
+			"build": map[string]any{"name": getBuildName(f)},
+		},
+	}}
+	run.SetGenerateName(getBuildRunGenerateName(f))
+	run.SetNamespace(namespace)
+	run.SetLabels(labels)
+	return run
+}
+
+// buildRunStatus is the state of a BuildRun, read from its Succeeded
+// condition.
+type buildRunStatus struct {
+	done      bool
+	succeeded bool
+	reason    string
+	message   string
+	digest    string
+	pod       string // pod of the failed step, if known
+	container string
+}
+
+func getBuildRunStatus(run *unstructured.Unstructured) buildRunStatus {
+	var s buildRunStatus
+	conditions, _, _ := unstructured.NestedSlice(run.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok || condition["type"] != "Succeeded" {
+			continue
+		}
+		s.reason, _ = condition["reason"].(string)
+		s.message, _ = condition["message"].(string)
+		switch condition["status"] {
+		case "True":
+			s.done, s.succeeded = true, true
+		case "False":
+			s.done = true
+		}
+	}
+	s.digest, _, _ = unstructured.NestedString(run.Object, "status", "output", "digest")
+	if message, ok, _ := unstructured.NestedString(run.Object, "status", "failureDetails", "message"); ok && message != "" {
+		s.message = message
+	}
+	s.pod, _, _ = unstructured.NestedString(run.Object, "status", "failureDetails", "location", "pod")
+	s.container, _, _ = unstructured.NestedString(run.Object, "status", "failureDetails", "location", "container")
+	return s
+}
@@ -0,0 +1,123 @@
+package shipwright
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func TestGenerateBuild_Source(t *testing.T) {
+	tests := []struct {
+		name string
+		git  fn.Git
+		want map[string]any
+	}{
+		{
+			name: "local",
+			want: map[string]any{
+				"type": "Local",
+				"local": map[string]any{
+					"name":    localSourceName,
+					"timeout": localSourceTimeout,
+				},
+			},
+		},
+		{
+			name: "git",
+			git:  fn.Git{URL: "https://example.com/user/repo.git", Revision: "main", ContextDir: "fn"},
+			want: map[string]any{
+				"type": "Git",
+				"git": map[string]any{
+					"url":      "https://example.com/user/repo.git",
+					"revision": "main",
+				},
+				"contextDir": "fn",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fn.Function{Name: "myfunc", Runtime: "go", Build: fn.BuildSpec{Builder: builders.Pack, Git: tt.git}}
+			build, err := generateBuild(f, "ns", "example.com/alice/myfunc:latest", map[string]string{"a": "b"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			source, _, _ := unstructured.NestedMap(build.Object, "spec", "source")
+			if diff := cmp.Diff(tt.want, source); diff != "" {
+				t.Errorf("unexpected source (-want, +got): %s", diff)
+			}
+			if build.GetName() != "func-myfunc-build" || build.GetNamespace() != "ns" {
+				t.Errorf("unexpected build %s/%s", build.GetNamespace(), build.GetName())
+			}
+			output, _, _ := unstructured.NestedStringMap(build.Object, "spec", "output")
+			if output["image"] != "example.com/alice/myfunc:latest" || output["pushSecret"] != "func-myfunc-push-secret" {
+				t.Errorf("unexpected output %v", output)
+			}
+		})
+	}
+}
+
+func TestGenerateBuild_Strategy(t *testing.T) {
+	name, value := "FOO", "bar"
+	f := fn.Function{
+		Name:    "myfunc",
+		Runtime: "node",
+		Build: fn.BuildSpec{
+			Builder:   builders.S2I,
+			BuildEnvs: fn.Envs{{Name: &name, Value: &value}},
+		},
+	}
+	build, err := generateBuild(f, "ns", "example.com/alice/myfunc:latest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strategy, _, _ := unstructured.NestedString(build.Object, "spec", "strategy", "name")
+	if strategy != s2iStrategy {
+		t.Errorf("expected strategy %q, got %q", s2iStrategy, strategy)
+	}
+	params, _, _ := unstructured.NestedSlice(build.Object, "spec", "paramValues")
+	if len(params) != 1 || params[0].(map[string]any)["name"] != "builder-image" {
+		t.Errorf("expected the builder-image param, got %v", params)
+	}
+	env, _, _ := unstructured.NestedSlice(build.Object, "spec", "env")
+	if diff := cmp.Diff([]any{map[string]any{"name": "FOO", "value": "bar"}}, env); diff != "" {
+		t.Errorf("unexpected env (-want, +got): %s", diff)
+	}
+
+	f.Build.Builder = builders.Host
+	if _, err = generateBuild(f, "ns", "example.com/alice/myfunc:latest", nil); err == nil {
+		t.Error("expected the host builder to be rejected")
+	}
+}
+
+func TestGetBuildRunStatus(t *testing.T) {
+	run := &unstructured.Unstructured{Object: map[string]any{
+		"status": map[string]any{
+			"conditions": []any{
+				map[string]any{"type": "Succeeded", "status": "False", "reason": "Failed", "message": "generic"},
+			},
+			"failureDetails": map[string]any{
+				"message":  "step failed",
+				"location": map[string]any{"pod": "p", "container": "step-build"},
+			},
+		},
+	}}
+	got := getBuildRunStatus(run)
+	want := buildRunStatus{done: true, reason: "Failed", message: "step failed", pod: "p", container: "step-build"}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	run.Object["status"] = map[string]any{
+		"conditions": []any{
+			map[string]any{"type": "Succeeded", "status": "Unknown", "reason": "Running"},
+		},
+	}
+	if got = getBuildRunStatus(run); got.done || got.reason != "Running" {
+		t.Errorf("expected a running build run, got %+v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package shipwright
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/pipelines"
+)
+
+const (
+	// buildRunNameLabel is set by Shipwright on the pod of a BuildRun.
+	buildRunNameLabel = "buildrun.shipwright.io/name"
+
+	// sourceLocalContainer waits for the local source upload, and is done
+	// once `waiter done` is run in it.
+	sourceLocalContainer = "step-source-local"
+
+	// workspaceDir is where the sources are expected; the tar stream puts
+	// them into its ./source subdirectory.
+	workspaceDir = "/workspace"
+
+	podPollInterval = 2 * time.Second
+)
+
+// uploadSources streams the function's sources into the BuildRun's pod once
+// its source step waits for them, then signals the step the upload is done.
+func uploadSources(ctx context.Context, f fn.Function, namespace, buildRun string) error {
+	podName, err := waitForSourceStep(ctx, namespace, buildRun)
+	if err != nil {
+		return err
+	}
+
+	content := pipelines.SourcesAsTarStream(f)
+	defer content.Close()
+
+	var out bytes.Buffer
+	err = k8s.ExecInPod(ctx, namespace, podName, sourceLocalContainer, []string{"tar", "-xmof", "-", "-C", workspaceDir}, content, &out, &out)
+	if err != nil {
+		return fmt.Errorf("cannot extract sources: %w, out=%q", err, strings.TrimSpace(out.String()))
+	}
+
+	out.Reset()
+	err = k8s.ExecInPod(ctx, namespace, podName, sourceLocalContainer, []string{"waiter", "done"}, nil, &out, &out)
+	if err != nil {
+		return fmt.Errorf("cannot complete the upload: %w, out=%q", err, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
+
+// waitForSourceStep returns the name of the BuildRun's pod once its source
+// step is running.
+func waitForSourceStep(ctx context.Context, namespace, buildRun string) (string, error) {
+	client, err := k8s.NewKubernetesClientset()
+	if err != nil {
+		return "", err
+	}
+
+	listOptions := metav1.ListOptions{
+		LabelSelector: k8slabels.SelectorFromSet(k8slabels.Set{buildRunNameLabel: buildRun}).String(),
+	}
+
+	ticker := time.NewTicker(podPollInterval)
+	defer ticker.Stop()
+	for {
+		pods, err := client.CoreV1().Pods(namespace).List(ctx, listOptions)
+		if err != nil {
+			return "", err
+		}
+		for _, pod := range pods.Items {
+			if sourceStepRunning(pod) {
+				return pod.Name, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func sourceStepRunning(pod corev1.Pod) bool {
+	for _, s := range pod.Status.ContainerStatuses {
+		if s.Name == sourceLocalContainer && s.State.Running != nil {
+			return true
+		}
+	}
+	return false
+}
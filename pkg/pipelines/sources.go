@@ -0,0 +1,129 @@
+package pipelines
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// SourcesAsTarStream creates a tar stream with the function sources as they
+// were in "./source" directory, omitting .git and files ignored by .gitignore.
+func SourcesAsTarStream(f fn.Function) *io.PipeReader {
+	ignored := func(p string) bool { return strings.HasPrefix(p, ".git") }
+	if gi, err := gitignore.CompileIgnoreFile(filepath.Join(f.Root, ".gitignore")); err == nil {
+		ignored = func(p string) bool {
+			if strings.HasPrefix(p, ".git") {
+				return true
+			}
+			return gi.MatchesPath(p)
+		}
+	}
+
+	pr, pw := io.Pipe()
+
+	const nobodyID = 65534
+
+	const up = ".." + string(os.PathSeparator)
+	go func() {
+		tw := tar.NewWriter(pw)
+
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     "source/",
+			Mode:     0777,
+			Uid:      nobodyID,
+			Gid:      nobodyID,
+			Uname:    "nobody",
+			Gname:    "nobody",
+		})
+		if err != nil {
+			_ = pw.CloseWithError(fmt.Errorf("error while creating tar stream from sources: %w", err))
+		}
+
+		err = filepath.Walk(f.Root, func(p string, fi fs.FileInfo, err error) error {
+			if err != nil {
+				return fmt.Errorf("error traversing function directory: %w", err)
+			}
+
+			relp, err := filepath.Rel(f.Root, p)
+			if err != nil {
+				return fmt.Errorf("cannot get relative path: %w", err)
+			}
+
+			if relp == "." {
+				return nil
+			}
+
+			if ignored(relp) {
+				return nil
+			}
+
+			lnk := ""
+			if fi.Mode()&fs.ModeSymlink != 0 {
+				lnk, err = os.Readlink(p)
+				if err != nil {
+					return fmt.Errorf("cannot read link: %w", err)
+				}
+				if filepath.IsAbs(lnk) {
+					lnk, err = filepath.Rel(f.Root, lnk)
+					if err != nil {
+						return fmt.Errorf("cannot get relative path for symlink: %w", err)
+					}
+					if strings.HasPrefix(lnk, up) || lnk == ".." {
+						return fmt.Errorf("link %q points outside source root", p)
+					}
+				} else {
+					t, err := filepath.Rel(f.Root, filepath.Join(filepath.Dir(p), lnk))
+					if err != nil {
+						return fmt.Errorf("cannot get relative path for symlink: %w", err)
+					}
+					if strings.HasPrefix(t, up) || t == ".." {
+						return fmt.Errorf("link %q points outside source root", p)
+					}
+				}
+			}
+
+			hdr, err := tar.FileInfoHeader(fi, filepath.ToSlash(lnk))
+			if err != nil {
+				return fmt.Errorf("cannot create a tar header: %w", err)
+			}
+			// "source" is expected path in workspace pvc
+			hdr.Name = path.Join("source", filepath.ToSlash(relp))
+
+			err = tw.WriteHeader(hdr)
+			if err != nil {
+				return fmt.Errorf("cannot write header to tar stream: %w", err)
+			}
+
+			if fi.Mode().IsRegular() {
+				var file io.ReadCloser
+				file, err = os.Open(p)
+				if err != nil {
+					return fmt.Errorf("cannot open source file: %w", err)
+				}
+				defer file.Close()
+				_, err = io.Copy(tw, file)
+				if err != nil {
+					return fmt.Errorf("cannot copy source file content: %w", err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			_ = pw.CloseWithError(fmt.Errorf("error while creating tar stream from sources: %w", err))
+		} else {
+			_ = tw.Close()
+			_ = pw.Close()
+		}
+	}()
+	return pr
+}
@@ -2,6 +2,7 @@ package tekton
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -83,7 +84,7 @@ func (pp *PipelinesProvider) ConfigurePAC(ctx context.Context, f fn.Function, me
 }
 
 // RemovePAC tries to remove all local and remote resources that were created for PAC.
-// Resources on the remote GitHub repo are not removed, we would need to store webhook id somewhere locally.
+// The webhook on the remote git repository is either deleted, or its secret is rotated.
 func (pp *PipelinesProvider) RemovePAC(ctx context.Context, f fn.Function, metadata any) error {
 	data, ok := metadata.(pipelines.PacMetadata)
 	if !ok {
@@ -92,6 +93,14 @@ func (pp *PipelinesProvider) RemovePAC(ctx context.Context, f fn.Function, metad
 
 	compoundErrMsg := ""
 
+	// the webhook is handled first, as the access token and git provider are
+	// read from the cluster resources
+	if data.ConfigureRemoteResources || data.RotateWebhookSecret {
+		if err := pp.removeRemotePACResources(ctx, f, data); err != nil {
+			compoundErrMsg += err.Error()
+		}
+	}
+
 	if data.ConfigureLocalResources {
 		errMsg := deleteAllPipelineTemplates(f)
 		compoundErrMsg += errMsg
@@ -112,6 +121,79 @@ func (pp *PipelinesProvider) RemovePAC(ctx context.Context, f fn.Function, metad
 	return nil
 }
 
+// removeRemotePACResources deletes the webhooks calling the PAC controller
+// from the git repository, or rotates their payload validation secret if
+// requested, updating the secret on the cluster accordingly.
+func (pp *PipelinesProvider) removeRemotePACResources(ctx context.Context, f fn.Function, data pipelines.PacMetadata) error {
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	if f.Build.Git.URL == "" {
+		return fmt.Errorf("cannot update webhook, the function has no git repository url")
+	}
+
+	// read the access token and webhook secret stored by 'func config git set'
+	secret, err := k8s.GetSecret(ctx, getPipelineSecretName(f), namespace)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		secret = nil
+	}
+	if data.PersonalAccessToken == "" && secret != nil {
+		data.PersonalAccessToken = string(secret.Data["provider.token"])
+	}
+	if data.PersonalAccessToken == "" {
+		return fmt.Errorf("cannot update webhook on repository %v, no git provider access token found in secret %q", f.Build.Git.URL, getPipelineSecretName(f))
+	}
+	if data.GitProvider == "" {
+		data.GitProvider = pacRepositoryGitProvider(ctx, f, namespace)
+	}
+
+	controllerURL, err := pp.pacControllerURL(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !data.RotateWebhookSecret {
+		deleted, err := git.DeleteWebHook(ctx, data.GitProvider, f.Build.Git.URL, controllerURL, data.PersonalAccessToken)
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			fmt.Printf(" ✅ No webhook for %v present on repository %v\n", controllerURL, f.Build.Git.URL)
+			return nil
+		}
+		fmt.Printf(" ✅ Webhook for %v deleted from repository %v\n", controllerURL, f.Build.Git.URL)
+		return nil
+	}
+
+	webhookSecret := data.WebhookSecret
+	if webhookSecret == "" {
+		webhookSecret = random.AlphaString(10)
+	}
+	updated, err := git.UpdateWebHookSecret(ctx, data.GitProvider, f.Build.Git.URL, controllerURL, webhookSecret, data.PersonalAccessToken)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("cannot rotate webhook secret, no webhook for %v present on repository %v", controllerURL, f.Build.Git.URL)
+	}
+	fmt.Printf(" ✅ Webhook secret rotated on repository %v\n", f.Build.Git.URL)
+
+	if secret == nil {
+		fmt.Printf(" ⚠️ Secret %q is not present on the cluster, webhook payloads can't be validated\n", getPipelineSecretName(f))
+		return nil
+	}
+	secret.Data["webhook.secret"] = []byte(webhookSecret)
+	if err = k8s.EnsureSecretExist(ctx, *secret, namespace); err != nil {
+		return err
+	}
+	fmt.Printf(" ✅ Webhook secret updated on the cluster in secret %q\n", getPipelineSecretName(f))
+	return nil
+}
+
 // createLocalPACResources creates necessary local resources in .tekton directory:
 // Pipeline and PipelineRun templates
 func (pp *PipelinesProvider) createLocalPACResources(ctx context.Context, f fn.Function) error {
@@ -212,11 +294,10 @@ func (pp *PipelinesProvider) createClusterPACResources(ctx context.Context, f fn
 	return nil
 }
 
-// createRemotePACResources creates resources on the remote git repository
-// set up a webhook with secrets, access tokens and it tries to detec PAC installation
-// together with PAC controller route url - needed for webhook payload trigger
-func (pp *PipelinesProvider) createRemotePACResources(ctx context.Context, f fn.Function, metadata pipelines.PacMetadata) error {
-
+// pacControllerURL returns the public URL of the PAC controller, which is the
+// payload URL of the webhooks.  It tries to detect PAC installation and its
+// route, prompting for the URL if it can't be detected.
+func (pp *PipelinesProvider) pacControllerURL(ctx context.Context) (string, error) {
 	// figure out pac installation namespace
 	installed, installationNS, err := pac.DetectPACInstallation(ctx)
 	if !installed {
@@ -224,16 +305,16 @@ func (pp *PipelinesProvider) createRemotePACResources(ctx context.Context, f fn.
 		if err != nil {
 			errMsg = fmt.Sprintf(", %v", err)
 		}
-		return fmt.Errorf("pipelines as code not installed%s", errMsg)
+		return "", fmt.Errorf("pipelines as code not installed%s", errMsg)
 	}
 	if installed && err != nil {
-		return err
+		return "", err
 	}
 
 	// fetch configmap to get controller url
 	controllerURL, err := pac.GetPACInfo(ctx, installationNS)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// check if info configmap has url then use that otherwise try to detect
@@ -244,13 +325,25 @@ func (pp *PipelinesProvider) createRemotePACResources(ctx context.Context, f fn.
 	// we haven't been able to detect PAC controller public route, let's prompt:
 	if controllerURL == "" {
 		if controllerURL, err = pp.getPacURL(); err != nil {
-			return err
+			return "", err
 		}
 	}
+	return controllerURL, nil
+}
+
+// createRemotePACResources creates resources on the remote git repository
+// set up a webhook with secrets, access tokens and it tries to detec PAC installation
+// together with PAC controller route url - needed for webhook payload trigger
+func (pp *PipelinesProvider) createRemotePACResources(ctx context.Context, f fn.Function, metadata pipelines.PacMetadata) error {
+
+	controllerURL, err := pp.pacControllerURL(ctx)
+	if err != nil {
+		return err
+	}
 
-	if err := git.CreateWebHook(ctx, f.Build.Git.URL, controllerURL, metadata.WebhookSecret, metadata.PersonalAccessToken); err != nil {
+	if err := git.CreateWebHook(ctx, metadata.GitProvider, f.Build.Git.URL, controllerURL, metadata.WebhookSecret, metadata.PersonalAccessToken); err != nil {
 		// Error: POST https://api.github.com/repos/foobar/test-function/hooks: 422 Validation Failed [{Resource:Hook Field: Code:custom Message:Hook already exists on this repository}]
-		if !strings.Contains(err.Error(), "Hook already exists") {
+		if !errors.Is(err, git.ErrWebHookExists) && !strings.Contains(err.Error(), "Hook already exists") {
 			return err
 		}
 		fmt.Printf(" ✅ Webhook already exists on repository %v\n", f.Build.Git.URL)
@@ -1,23 +1,16 @@
 package tekton
 
 import (
-	"archive/tar"
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"io/fs"
 	"os"
-	"path"
-	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
-	gitignore "github.com/sabhiram/go-gitignore"
 	"github.com/tektoncd/cli/pkg/pipelinerun"
 	"github.com/tektoncd/cli/pkg/taskrun"
 	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
@@ -33,6 +26,7 @@ import (
 	fnlabels "knative.dev/func/pkg/k8s/labels"
 	"knative.dev/func/pkg/knative"
 	"knative.dev/func/pkg/oci"
+	"knative.dev/func/pkg/pipelines"
 	"knative.dev/pkg/apis"
 )
 
@@ -52,6 +46,8 @@ type pacURLCallback = func() (string, error)
 
 type PipelinesProvider struct {
 	verbose             bool
+	quiet               bool
+	noCache             bool
 	getPacURL           pacURLCallback
 	credentialsProvider oci.CredentialsProvider
 	decorator           PipelineDecorator
@@ -69,6 +65,21 @@ func WithVerbose(verbose bool) Opt {
 	}
 }
 
+// WithQuiet prints only the progress of the pipeline's tasks instead of
+// streaming the logs of their steps.
+func WithQuiet(quiet bool) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.quiet = quiet
+	}
+}
+
+// WithNoCache resets the function's build cache before running the pipeline.
+func WithNoCache(noCache bool) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.noCache = noCache
+	}
+}
+
 func WithPipelineDecorator(decorator PipelineDecorator) Opt {
 	return func(pp *PipelinesProvider) {
 		pp.decorator = decorator
@@ -151,6 +162,12 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 		labels = pp.decorator.UpdateLabels(f, labels)
 	}
 
+	if pp.noCache {
+		if err = deletePersistentVolumeClaim(ctx, getPipelineCachePvcName(f), namespace); err != nil {
+			return "", f, fmt.Errorf("problem resetting the build cache: %w", err)
+		}
+	}
+
 	err = createPipelinePersistentVolumeClaim(ctx, f, namespace, labels)
 	if err != nil {
 		return "", f, err
@@ -158,7 +175,7 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 
 	if f.Build.Git.URL == "" {
 		// Use direct upload to PVC if Git is not set up.
-		content := sourcesAsTarStream(f)
+		content := pipelines.SourcesAsTarStream(f)
 		defer content.Close()
 		err = k8s.UploadToVolume(ctx, content, getPipelinePvcName(f), namespace)
 		if err != nil {
@@ -203,7 +220,7 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 		return "", f, fmt.Errorf("problem in creating secret: %v", err)
 	}
 
-	err = createAndApplyPipelineRunTemplate(f, namespace, labels)
+	err = createAndApplyPipelineRunTemplate(f, namespace, labels, pp.noCache)
 	if err != nil {
 		return "", f, fmt.Errorf("problem in creating pipeline run: %v", err)
 	}
@@ -232,7 +249,11 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 	}
 
 	if newestPipelineRun.Status.GetCondition(apis.ConditionSucceeded).Status == corev1.ConditionFalse {
-		message := getFailedPipelineRunLog(ctx, client, newestPipelineRun, namespace)
+		// unless quiet, the logs of the failed step were already streamed
+		message := newestPipelineRun.Status.GetCondition(apis.ConditionSucceeded).Message
+		if pp.quiet {
+			message = getFailedPipelineRunLog(ctx, client, newestPipelineRun, namespace)
+		}
 		return "", f, fmt.Errorf("function pipeline run has failed with message: \n\n%s", message)
 	}
 
@@ -259,118 +280,6 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 	return ksvc.Status.URL.String(), f, nil
 }
 
-// Creates tar stream with the function sources as they were in "./source" directory.
-func sourcesAsTarStream(f fn.Function) *io.PipeReader {
-	ignored := func(p string) bool { return strings.HasPrefix(p, ".git") }
-	if gi, err := gitignore.CompileIgnoreFile(filepath.Join(f.Root, ".gitignore")); err == nil {
-		ignored = func(p string) bool {
-			if strings.HasPrefix(p, ".git") {
-				return true
-			}
-			return gi.MatchesPath(p)
-		}
-	}
-
-	pr, pw := io.Pipe()
-
-	const nobodyID = 65534
-
-	const up = ".." + string(os.PathSeparator)
-	go func() {
-		tw := tar.NewWriter(pw)
-
-		err := tw.WriteHeader(&tar.Header{
-			Typeflag: tar.TypeDir,
-			Name:     "source/",
-			Mode:     0777,
-			Uid:      nobodyID,
-			Gid:      nobodyID,
-			Uname:    "nobody",
-			Gname:    "nobody",
-		})
-		if err != nil {
-			_ = pw.CloseWithError(fmt.Errorf("error while creating tar stream from sources: %w", err))
-		}
-
-		err = filepath.Walk(f.Root, func(p string, fi fs.FileInfo, err error) error {
-			if err != nil {
-				return fmt.Errorf("error traversing function directory: %w", err)
-			}
-
-			relp, err := filepath.Rel(f.Root, p)
-			if err != nil {
-				return fmt.Errorf("cannot get relative path: %w", err)
-			}
-
-			if relp == "." {
-				return nil
-			}
-
-			if ignored(relp) {
-				return nil
-			}
-
-			lnk := ""
-			if fi.Mode()&fs.ModeSymlink != 0 {
-				lnk, err = os.Readlink(p)
-				if err != nil {
-					return fmt.Errorf("cannot read link: %w", err)
-				}
-				if filepath.IsAbs(lnk) {
-					lnk, err = filepath.Rel(f.Root, lnk)
-					if err != nil {
-						return fmt.Errorf("cannot get relative path for symlink: %w", err)
-					}
-					if strings.HasPrefix(lnk, up) || lnk == ".." {
-						return fmt.Errorf("link %q points outside source root", p)
-					}
-				} else {
-					t, err := filepath.Rel(f.Root, filepath.Join(filepath.Dir(p), lnk))
-					if err != nil {
-						return fmt.Errorf("cannot get relative path for symlink: %w", err)
-					}
-					if strings.HasPrefix(t, up) || t == ".." {
-						return fmt.Errorf("link %q points outside source root", p)
-					}
-				}
-			}
-
-			hdr, err := tar.FileInfoHeader(fi, filepath.ToSlash(lnk))
-			if err != nil {
-				return fmt.Errorf("cannot create a tar header: %w", err)
-			}
-			// "source" is expected path in workspace pvc
-			hdr.Name = path.Join("source", filepath.ToSlash(relp))
-
-			err = tw.WriteHeader(hdr)
-			if err != nil {
-				return fmt.Errorf("cannot write header to tar stream: %w", err)
-			}
-
-			if fi.Mode().IsRegular() {
-				var file io.ReadCloser
-				file, err = os.Open(p)
-				if err != nil {
-					return fmt.Errorf("cannot open source file: %w", err)
-				}
-				defer file.Close()
-				_, err = io.Copy(tw, file)
-				if err != nil {
-					return fmt.Errorf("cannot copy source file content: %w", err)
-				}
-			}
-			return nil
-		})
-		if err != nil {
-			_ = pw.CloseWithError(fmt.Errorf("error while creating tar stream from sources: %w", err))
-		} else {
-			_ = tw.Close()
-			_ = pw.Close()
-		}
-	}()
-	return pr
-}
-
 // Remove tries to remove all resources that are present on the cluster and belongs to the input function and it's pipelines
 func (pp *PipelinesProvider) Remove(ctx context.Context, f fn.Function) error {
 	return pp.removeClusterResources(ctx, f)
@@ -449,6 +358,23 @@ func (pp *PipelinesProvider) watchPipelineRunProgress(ctx context.Context, pr *v
 		return err
 	}
 
+	if !pp.quiet {
+		logsCtx, stopLogs := context.WithCancel(ctx)
+		logsDone := make(chan struct{})
+		go func() {
+			defer close(logsDone)
+			selector := k8slabels.SelectorFromSet(k8slabels.Set{pipelineRunLabel: pr.Name}).String()
+			err := getPodsLogsBySelector(logsCtx, namespace, selector, taskLogPrefix, os.Stderr)
+			if err != nil && pp.verbose {
+				fmt.Fprintf(os.Stderr, "cannot stream the pipeline run logs: %v\n", err)
+			}
+		}()
+		defer func() {
+			stopLogs()
+			<-logsDone
+		}()
+	}
+
 	prTracker := pipelinerun.NewTracker(pr.Name, namespace, clients)
 	trChannel := prTracker.Monitor([]string{})
 	ctxDone := ctx.Done()
@@ -489,6 +415,24 @@ out:
 	return err
 }
 
+// Labels set by Tekton on the pods of a PipelineRun's tasks.
+const (
+	pipelineRunLabel  = "tekton.dev/pipelineRun"
+	pipelineTaskLabel = "tekton.dev/pipelineTask"
+)
+
+// allows simple mocking in unit tests
+var getPodsLogsBySelector = k8s.GetPodsLogsBySelector
+
+// taskLogPrefix prefixes the log lines of a step with the name of its task.
+func taskLogPrefix(pod corev1.Pod, _ string) string {
+	task := pod.Labels[pipelineTaskLabel]
+	if task == "" {
+		task = pod.Name
+	}
+	return fmt.Sprintf("[%s] ", task)
+}
+
 // getFailedPipelineRunLog returns log message for a failed PipelineRun,
 // returns log from a container where the failing TaskRun is running, if available.
 func getFailedPipelineRunLog(ctx context.Context, client *pipelineClient.TektonV1Client, pr *v1.PipelineRun, namespace string) string {
@@ -557,8 +501,13 @@ func findNewestPipelineRunWithRetry(ctx context.Context, f fn.Function, namespac
 }
 
 // allows simple mocking in unit tests, use with caution regarding concurrency
-var createPersistentVolumeClaim = k8s.CreatePersistentVolumeClaim
+var (
+	createPersistentVolumeClaim = k8s.CreatePersistentVolumeClaim
+	deletePersistentVolumeClaim = k8s.DeletePersistentVolumeClaim
+)
 
+// createPipelinePersistentVolumeClaim creates the volumes of the function's
+// sources and of its build cache, both of the size requested by the function.
 func createPipelinePersistentVolumeClaim(ctx context.Context, f fn.Function, namespace string, labels map[string]string) error {
 	var err error
 	pvcs := DefaultPersistentVolumeClaimSize
@@ -567,9 +516,11 @@ func createPipelinePersistentVolumeClaim(ctx context.Context, f fn.Function, nam
 			return fmt.Errorf("PVC size value could not be parsed. %w", err)
 		}
 	}
-	err = createPersistentVolumeClaim(ctx, getPipelinePvcName(f), namespace, labels, f.Deploy.Annotations, corev1.ReadWriteOnce, pvcs, f.Build.RemoteStorageClass)
-	if err != nil && !k8serrors.IsAlreadyExists(err) {
-		return fmt.Errorf("problem creating persistent volume claim: %v", err)
+	for _, name := range []string{getPipelinePvcName(f), getPipelineCachePvcName(f)} {
+		err = createPersistentVolumeClaim(ctx, name, namespace, labels, f.Deploy.Annotations, corev1.ReadWriteOnce, pvcs, f.Build.RemoteStorageClass)
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return fmt.Errorf("problem creating persistent volume claim: %v", err)
+		}
 	}
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -16,6 +17,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/pipelines"
 )
 
 func TestSourcesAsTarStream(t *testing.T) {
@@ -28,7 +30,7 @@ func TestSourcesAsTarStream(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	rc := sourcesAsTarStream(fn.Function{Root: root})
+	rc := pipelines.SourcesAsTarStream(fn.Function{Root: root})
 	t.Cleanup(func() { _ = rc.Close() })
 
 	var helloTxtContent []byte
@@ -73,6 +75,26 @@ func TestSourcesAsTarStream(t *testing.T) {
 	}
 }
 
+func Test_createPipelinePersistentVolumeClaim_Cache(t *testing.T) {
+	old := createPersistentVolumeClaim
+	defer func() { createPersistentVolumeClaim = old }()
+
+	var created []string
+	createPersistentVolumeClaim = func(ctx context.Context, name, namespaceOverride string, labels map[string]string, annotations map[string]string, accessMode corev1.PersistentVolumeAccessMode, resourceRequest resource.Quantity, storageClass string) (err error) {
+		created = append(created, name)
+		return nil
+	}
+
+	f := fn.Function{Name: "myfunc", Build: fn.BuildSpec{Builder: "pack"}}
+	if err := createPipelinePersistentVolumeClaim(t.Context(), f, "test-ns", nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{getPipelinePvcName(f), getPipelineCachePvcName(f)}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("expected volumes %v, got %v", want, created)
+	}
+}
+
 func Test_createPipelinePersistentVolumeClaim(t *testing.T) {
 	type mockType func(ctx context.Context, name, namespaceOverride string, labels map[string]string, annotations map[string]string, accessMode corev1.PersistentVolumeAccessMode, resourceRequest resource.Quantity, storageClass string) (err error)
 
@@ -145,3 +167,14 @@ func Test_createPipelinePersistentVolumeClaim(t *testing.T) {
 		})
 	}
 }
+
+func Test_taskLogPrefix(t *testing.T) {
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "func-abc-run-xyz-build-pod", Labels: map[string]string{pipelineTaskLabel: "build"}}}
+	if got := taskLogPrefix(pod, "step-create"); got != "[build] " {
+		t.Errorf("expected the task name prefix, got %q", got)
+	}
+	pod.Labels = nil
+	if got := taskLogPrefix(pod, "step-create"); got != "[func-abc-run-xyz-build-pod] " {
+		t.Errorf("expected the pod name prefix, got %q", got)
+	}
+}
@@ -78,3 +78,9 @@ func getPipelineSecretName(f fn.Function) string {
 func getPipelinePvcName(f fn.Function) string {
 	return fmt.Sprintf("%s-pvc", getPipelineName(f))
 }
+
+// getPipelineCachePvcName returns the name of the volume holding the build
+// cache, which outlives the runs of the function's pipeline.
+func getPipelineCachePvcName(f fn.Function) string {
+	return fmt.Sprintf("%s-cache-pvc", getPipelineName(f))
+}
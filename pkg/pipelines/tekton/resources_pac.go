@@ -11,6 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/git"
 	"knative.dev/func/pkg/k8s"
 	"knative.dev/func/pkg/pipelines"
 	"knative.dev/func/pkg/pipelines/tekton/pac"
@@ -47,6 +48,14 @@ func ensurePACRepositoryExists(ctx context.Context, f fn.Function, namespace str
 		return err
 	}
 
+	// self-hosted providers are reached by the URL of their instance
+	var providerURL string
+	if metadata.GitProvider != "" {
+		if providerURL, err = git.ProviderURL(metadata.GitProvider, f.Build.Git.URL); err != nil {
+			return err
+		}
+	}
+
 	repoName := getPipelineRepositoryName(f)
 	repo := v1alpha1.Repository{
 		ObjectMeta: metav1.ObjectMeta{
@@ -58,6 +67,7 @@ func ensurePACRepositoryExists(ctx context.Context, f fn.Function, namespace str
 			URL: f.Build.Git.URL,
 			GitProvider: &v1alpha1.GitProvider{
 				Type: metadata.GitProvider,
+				URL:  providerURL,
 				Secret: &v1alpha1.Secret{
 					Name: getPipelineSecretName(f),
 				},
@@ -109,6 +119,20 @@ func deletePACRepositories(ctx context.Context, namespaceOverride string, listOp
 	return client.Repositories(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, listOptions)
 }
 
+// pacRepositoryGitProvider returns the git provider of the function's PAC
+// Repository on the cluster, or an empty string if it can't be determined.
+func pacRepositoryGitProvider(ctx context.Context, f fn.Function, namespace string) string {
+	client, namespace, err := pac.NewTektonPacClientAndResolvedNamespace(namespace)
+	if err != nil {
+		return ""
+	}
+	repo, err := client.Repositories(namespace).Get(ctx, getPipelineRepositoryName(f), metav1.GetOptions{})
+	if err != nil || repo.Spec.GitProvider == nil {
+		return ""
+	}
+	return repo.Spec.GitProvider.Type
+}
+
 // getPipelineRepositoryName generates name for Repository CR
 func getPipelineRepositoryName(f fn.Function) string {
 	return fmt.Sprintf("%s-repo", getPipelineName(f))
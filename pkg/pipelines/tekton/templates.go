@@ -7,6 +7,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -70,8 +71,13 @@ type templateData struct {
 	PipelineName    string
 	PipelineRunName string
 	PvcName         string
+	CachePvcName    string
 	SecretName      string
 
+	// Build cache of the pack builder, stored in the image if set
+	CacheImage  string
+	SkipRestore string
+
 	// The branch or tag we are targeting with Pipelines (ie: main, refs/tags/*)
 	PipelinesTargetBranch string
 
@@ -184,8 +190,12 @@ func createPipelineRunTemplatePAC(f fn.Function, labels map[string]string) error
 		PipelineName:    getPipelineName(f),
 		PipelineRunName: fmt.Sprintf("%s-run", getPipelineName(f)),
 		PvcName:         getPipelinePvcName(f),
+		CachePvcName:    getPipelineCachePvcName(f),
 		SecretName:      getPipelineSecretName(f),
 
+		CacheImage:  f.Build.CacheImage,
+		SkipRestore: "false",
+
 		PipelinesTargetBranch: pipelinesTargetBranch,
 
 		PipelineYamlURL: fmt.Sprintf("%s/%s", resourcesDirectory, pipelineFileNamePAC),
@@ -329,8 +339,9 @@ func createAndApplyPipelineTemplate(f fn.Function, namespace string, labels map[
 }
 
 // createAndApplyPipelineRunTemplate creates and applies PipelineRun template for a standard on-cluster build
-// all resources are created on the fly, if there's a PipelineRun defined in the project directory, it is used instead
-func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels map[string]string) error {
+// all resources are created on the fly, if there's a PipelineRun defined in the project directory, it is used instead.
+// With skipCache the pack builder does not restore its build cache.
+func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels map[string]string, skipCache bool) error {
 	contextDir := f.Build.Git.ContextDir
 	if contextDir == "" && f.Build.Builder == builders.S2I {
 		// TODO(lkingland): could instead update S2I to interpret empty string
@@ -383,8 +394,12 @@ func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels m
 		PipelineName:    getPipelineName(f),
 		PipelineRunName: getPipelineRunGenerateName(f),
 		PvcName:         getPipelinePvcName(f),
+		CachePvcName:    getPipelineCachePvcName(f),
 		SecretName:      getPipelineSecretName(f),
 
+		CacheImage:  f.Build.CacheImage,
+		SkipRestore: strconv.FormatBool(skipCache),
+
 		S2iImageScriptsUrl: s2iImageScriptsUrl,
 		TlsVerify:          tlsVerify,
 
@@ -40,6 +40,14 @@ spec:
     - description: Environment variables to set during build time
       name: buildEnvs
       type: array
+    - default: ''
+      description: Image where the build cache is stored instead of the cache workspace
+      name: cacheImage
+      type: string
+    - default: 'false'
+      description: Do not restore the build cache
+      name: skipRestore
+      type: string
   tasks:
     - name: build
       params:
@@ -58,6 +66,10 @@ spec:
         - name: ENV_VARS
           value:
             - '$(params.buildEnvs[*])'
+        - name: CACHE_IMAGE
+          value: $(params.cacheImage)
+        - name: SKIP_RESTORE
+          value: $(params.skipRestore)
       {{.FuncBuildpacksTaskRef}}
       workspaces:
         - name: source
@@ -69,7 +81,7 @@ spec:
   workspaces:
     - description: Directory where function source is located.
       name: source-workspace
-    - description: Directory where build cache is stored.
+    - description: Directory where build cache is stored, persisted across runs.
       name: cache-workspace
     - description: Directory containing image registry credentials stored in config.json file.
       name: dockerconfig-workspace
@@ -111,6 +123,10 @@ spec:
         {{range .BuildEnvs -}}
            - {{.}}
         {{end}}
+    - name: cacheImage
+      value: "{{.CacheImage}}"
+    - name: skipRestore
+      value: "{{.SkipRestore}}"
   pipelineRef:
    name: {{.PipelineName}}
   workspaces:
@@ -120,8 +136,7 @@ spec:
       subPath: source
     - name: cache-workspace
       persistentVolumeClaim:
-        claimName: {{.PvcName}}
-      subPath: cache
+        claimName: {{.CachePvcName}}
     - name: dockerconfig-workspace
       secret:
         secretName: {{.SecretName}}
@@ -171,6 +186,10 @@ spec:
         {{range .BuildEnvs -}}
            - {{.}}
         {{end}}
+    - name: cacheImage
+      value: "{{.CacheImage}}"
+    - name: skipRestore
+      value: "{{.SkipRestore}}"
   pipelineRef:
    name: {{.PipelineName}}
   workspaces:
@@ -180,8 +199,7 @@ spec:
       subPath: source
     - name: cache-workspace
       persistentVolumeClaim:
-        claimName: {{.PvcName}}
-      subPath: cache
+        claimName: {{.CachePvcName}}
     - name: dockerconfig-workspace
       secret:
         secretName: {{.SecretName}}
@@ -81,7 +81,7 @@ spec:
   workspaces:
     - description: Directory where function source is located.
       name: source-workspace
-    - description: Directory where build cache is stored.
+    - description: Directory where build cache is stored, persisted across runs.
       name: cache-workspace
     - description: Directory containing image registry credentials stored in config.json file.
       name: dockerconfig-workspace
@@ -135,8 +135,7 @@ spec:
       subPath: source
     - name: cache-workspace
       persistentVolumeClaim:
-        claimName: {{.PvcName}}
-      subPath: cache
+        claimName: {{.CachePvcName}}
     - name: dockerconfig-workspace
       secret:
         secretName: {{.SecretName}}
@@ -202,8 +201,7 @@ spec:
       subPath: source
     - name: cache-workspace
       persistentVolumeClaim:
-        claimName: {{.PvcName}}
-      subPath: cache
+        claimName: {{.CachePvcName}}
     - name: dockerconfig-workspace
       secret:
         secretName: {{.SecretName}}
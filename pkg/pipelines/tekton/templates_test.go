@@ -1,11 +1,13 @@
 package tekton
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
 
 	"github.com/manifestival/manifestival"
 	"github.com/manifestival/manifestival/fake"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"knative.dev/func/pkg/builders"
 	fn "knative.dev/func/pkg/functions"
@@ -316,9 +318,74 @@ func Test_createAndApplyPipelineRunTemplate(t *testing.T) {
 			f.Image = "docker.io/alice/" + f.Name
 			f.Registry = TestRegistry
 
-			if err := createAndApplyPipelineRunTemplate(f, tt.namespace, tt.labels); (err != nil) != tt.wantErr {
+			if err := createAndApplyPipelineRunTemplate(f, tt.namespace, tt.labels, false); (err != nil) != tt.wantErr {
 				t.Errorf("createAndApplyPipelineRunTemplate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func Test_createAndApplyPipelineRunTemplate_Cache(t *testing.T) {
+	for _, skipCache := range []bool{false, true} {
+		t.Run(fmt.Sprintf("skipCache=%v", skipCache), func(t *testing.T) {
+			var created *unstructured.Unstructured
+			old := manifestivalClient
+			defer func() { manifestivalClient = old }()
+			manifestivalClient = func() (manifestival.Client, error) {
+				c := fake.New()
+				c.Stubs.Create = func(u *unstructured.Unstructured) error {
+					created = u
+					return nil
+				}
+				return c, nil
+			}
+
+			root := "testdata/testCreatePipelinePackNodeRun"
+			defer Using(t, root)()
+
+			f, err := fn.NewFunction(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Build.Builder = builders.Pack
+			f.Runtime = "node"
+			f.Image = "docker.io/alice/" + f.Name
+			f.Registry = TestRegistry
+			f.Build.CacheImage = "docker.io/alice/cache"
+
+			if err = createAndApplyPipelineRunTemplate(f, "test-ns", nil, skipCache); err != nil {
+				t.Fatal(err)
+			}
+			if created == nil {
+				t.Fatal("pipeline run was not applied")
+			}
+
+			params, _, _ := unstructured.NestedSlice(created.Object, "spec", "params")
+			values := map[string]any{}
+			for _, p := range params {
+				values[p.(map[string]any)["name"].(string)] = p.(map[string]any)["value"]
+			}
+			if values["cacheImage"] != "docker.io/alice/cache" {
+				t.Errorf("unexpected cacheImage %v", values["cacheImage"])
+			}
+			if values["skipRestore"] != fmt.Sprint(skipCache) {
+				t.Errorf("unexpected skipRestore %v", values["skipRestore"])
+			}
+
+			workspaces, _, _ := unstructured.NestedSlice(created.Object, "spec", "workspaces")
+			for _, w := range workspaces {
+				w := w.(map[string]any)
+				if w["name"] != "cache-workspace" {
+					continue
+				}
+				claim, _, _ := unstructured.NestedString(w, "persistentVolumeClaim", "claimName")
+				if claim != getPipelineCachePvcName(f) {
+					t.Errorf("expected cache workspace on %q, got %q", getPipelineCachePvcName(f), claim)
+				}
+				if _, ok := w["subPath"]; ok {
+					t.Error("cache workspace should not share the sources volume")
+				}
+			}
+		})
+	}
+}
@@ -193,6 +193,46 @@ func ServeRepo(name string, t *testing.T) string {
 	return fmt.Sprintf("%v/%v", url, name)
 }
 
+// ServeVersionedRepo serves a git repository with the template go/versioned,
+// returning its URL and its three commits.  The first two are tagged v1.0.0
+// and v1.1.0, the third is the head of the branch main.  The first is also
+// tagged c0ffee1, a name which may be mistaken for an abbreviated commit.
+func ServeVersionedRepo(t *testing.T) (uri string, commits []string) {
+	t.Helper()
+	src, gitRoot := t.TempDir(), t.TempDir()
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	git(src, "init", "--initial-branch=main")
+	if err := os.MkdirAll(filepath.Join(src, "go", "versioned"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i, tag := range []string{"v1.0.0", "v1.1.0", ""} {
+		content := fmt.Sprintf("package function\n\n// Version %d\n", i+1)
+		if err := os.WriteFile(filepath.Join(src, "go", "versioned", "handle.go"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git(src, "add", "-A")
+		git(src, "commit", "-m", fmt.Sprintf("version %d", i+1))
+		if tag != "" {
+			git(src, "tag", tag)
+		}
+		commits = append(commits, git(src, "rev-parse", "HEAD"))
+	}
+	git(src, "tag", "c0ffee1", commits[0])
+	git(gitRoot, "clone", "--bare", src, "versioned.git")
+
+	return RunGitServer(gitRoot, t) + "/versioned.git", commits
+}
+
 // WithExecutable creates an executable of the given name and source in a temp
 // directory which is then added to PATH.  Returned is a deferrable which will
 // clean up both the script and PATH.